@@ -1,17 +1,22 @@
 package main
 
 import (
+	"context"
+	"expvar"
 	"fmt"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/thiagoluis88git/tech1-orders/internal/core/data/remote"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/data/repositories"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/usecases"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
 	"github.com/thiagoluis88git/tech1-orders/pkg/database"
 	"github.com/thiagoluis88git/tech1-orders/pkg/environment"
 	"github.com/thiagoluis88git/tech1-orders/pkg/httpserver"
-	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
 	"gorm.io/driver/postgres"
 
 	"github.com/mvrilo/go-redoc"
@@ -24,6 +29,22 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
 
+// orderEventsHistorySize is how many order events are kept for clients resuming the stream
+const orderEventsHistorySize = 1024
+
+// adminAddress only listens on the loopback interface, so the runtime and cache
+// metrics are not served to the public
+const adminAddress = "127.0.0.1:3212"
+
+// outboxRelaySettings controls how the order events are delivered to the downstream systems
+var outboxRelaySettings = usecases.OutboxRelaySettings{
+	BatchSize:  100,
+	Lease:      time.Minute,
+	MinBackoff: 5 * time.Second,
+	MaxBackoff: 10 * time.Minute,
+	Retention:  7 * 24 * time.Hour,
+}
+
 // @title Tech1 API Docs
 // @version 1.0
 // @description This is the API for the Tech1 Fiap Project.
@@ -63,98 +84,300 @@ func main() {
 		panic(fmt.Sprintf("could not open database: %v", err.Error()))
 	}
 
+	staffRoleTokens := environment.GetStaffRoleTokens()
+
+	for _, role := range staffRoleTokens {
+		if !statemachine.IsStaffRole(role) {
+			panic(fmt.Sprintf("%v is not a staff role, the staff tokens can only be kitchen, waiter or manager", role))
+		}
+	}
+
+	if len(staffRoleTokens) == 0 {
+		log.Print("no staff tokens, the orders can not be moved until STAFF_ROLE_TOKENS is set")
+	}
+
+	if environment.GetWebhookSecret() == "" {
+		log.Print("no payment webhook secret, every payment notification is rejected until PAYMENT_WEBHOOK_SECRET is set")
+	}
+
+	if environment.GetPixKey() == "" {
+		log.Print("no PIX key, the QR Code orders can not be created until PIX_KEY is set")
+	}
+
 	router := chi.NewRouter()
 	router.Use(chiMiddleware.RequestID)
 	router.Use(chiMiddleware.RealIP)
 	router.Use(chiMiddleware.Recoverer)
+	router.Use(handler.OrderRoleMiddleware(staffRoleTokens))
+	router.Use(handler.OrderIfMatchMiddleware)
 
-	httpClient := httpserver.NewHTTPClient()
+	tlsConfig, err := httpserver.NewTLSConfig(httpserver.TLSSettings{
+		CAFile:   environment.GetHTTPClientCAFile(),
+		CertFile: environment.GetHTTPClientCertFile(),
+		KeyFile:  environment.GetHTTPClientKeyFile(),
+		Insecure: environment.IsHTTPClientInsecure(),
+	})
+
+	if err != nil {
+		panic(fmt.Sprintf("could not load tls config: %v", err.Error()))
+	}
+
+	outboundTransport := httpserver.NewResilientTransport(httpserver.NewHTTPTransport(tlsConfig), httpserver.DefaultResilienceSettings)
+	httpClient := httpserver.NewHTTPClient(outboundTransport)
 
 	customerRemote := remote.NewCustomerRemoteDataSource(httpClient, environment.GetCustomerRootAPI())
+
+	if environment.IsCustomerCacheEnabled() {
+		customerCache := remote.NewCachedCustomerRemoteDataSource(customerRemote, remote.CustomerCacheSettings{
+			Size:        environment.GetCustomerCacheSize(),
+			TTL:         environment.GetCustomerCacheTTL(),
+			NegativeTTL: environment.GetCustomerCacheNegativeTTL(),
+		})
+
+		expvar.Publish("customerCache", expvar.Func(func() any {
+			return customerCache.Stats()
+		}))
+
+		customerRemote = customerCache
+	}
+
 	customerRepo := repositories.NewCustomerRepository(customerRemote)
 
+	categoryRepo := repositories.NewCategoryRepository(db)
+	getCategoriesUseCase := usecases.NewGetCategoriesUseCase(categoryRepo)
+	getCategoryByIdUseCase := usecases.NewGetCategoryByIdUseCase(categoryRepo)
+	createCategoryUseCase := usecases.NewCreateCategoryUseCase(categoryRepo)
+	updateCategoryUseCase := usecases.NewUpdateCategoryUseCase(categoryRepo)
+	deleteCategoryUseCase := usecases.NewDeleteCategoryUseCase(categoryRepo)
+
 	productRepo := repositories.NewProductRepository(db)
 	validateProductCategoryUseCase := usecases.NewValidateProductCategoryUseCase()
-	getCategoriesUseCase := usecases.NewGetCategoriesUseCase(productRepo)
 	getProductsUseCase := usecases.NewGetProductsByCategoryUseCase(productRepo)
 	getProductByIdUseCase := usecases.NewGetProductByIdUseCase(productRepo)
 	deleteProductUseCase := usecases.NewDeleteProductUseCase(productRepo)
-	updateProductUseCase := usecases.NewUpdateProductUseCase(productRepo)
-	createProductUseCase := usecases.NewCreateProductUseCase(validateProductCategoryUseCase, productRepo)
+	restoreProductUseCase := usecases.NewRestoreProductUseCase(productRepo)
+	updateProductUseCase := usecases.NewUpdateProductUseCase(validateProductCategoryUseCase, productRepo, categoryRepo)
+	patchProductUseCase := usecases.NewPatchProductUseCase(productRepo, updateProductUseCase)
+	createProductUseCase := usecases.NewCreateProductUseCase(validateProductCategoryUseCase, productRepo, categoryRepo)
+
+	comboRepo := repositories.NewComboRepository(db)
+	createComboUseCase := usecases.NewCreateComboUseCase(comboRepo, productRepo, categoryRepo)
+	updateComboUseCase := usecases.NewUpdateComboUseCase(comboRepo, productRepo, categoryRepo)
+	getCombosUseCase := usecases.NewGetCombosUseCase(comboRepo)
+	getComboByIdUseCase := usecases.NewGetComboByIdUseCase(comboRepo)
 
 	orderRepo := repositories.NewOrderRespository(db, customerRemote)
-	validateToPreare := usecases.NewValidateOrderToPrepareUseCase(orderRepo)
-	validateToDone := usecases.NewValidateOrderToDoneUseCase(orderRepo)
-	validateToDeliveredOrNot := usecases.NewValidateOrderToDeliveredOrNotUseCase(orderRepo)
-	sortOrders := usecases.NewSortOrdersUseCase()
+	orderStateMachine := statemachine.NewOrderStateMachine()
+	orderEvents := events.NewOrderEventBus(orderEventsHistorySize)
+	orderEventNotifier := repositories.NewOrderEventNotifier(db, dsn, orderEvents)
+
+	err = orderEventNotifier.Start(context.Background())
+
+	if err != nil {
+		panic(fmt.Sprintf("could not listen to order events: %v", err.Error()))
+	}
+
+	kitchenSortOrders := usecases.NewSortOrdersUseCase(usecases.KitchenOrderSortView)
+	pickupSortOrders := usecases.NewSortOrdersUseCase(usecases.PickupOrderSortView)
+	priceOrder := usecases.NewPriceOrderUseCase(productRepo, comboRepo)
 	createOrderUseCase := usecases.NewCreateOrderUseCase(
 		orderRepo,
 		customerRepo,
-		validateToPreare,
-		validateToDone,
-		validateToDeliveredOrNot,
-		sortOrders,
+		priceOrder,
+		pickupSortOrders,
+		orderEventNotifier,
 	)
-	getOrderByIdUseCase := usecases.NewGetOrderByIdUseCase(orderRepo)
-	getOrdersToPrepareUseCase := usecases.NewGetOrdersToPrepareUseCase(
+	createPayingOrderUseCase := usecases.NewCreatePayingOrderUseCase(
 		orderRepo,
-		sortOrders,
+		customerRepo,
+		priceOrder,
+		orderEventNotifier,
 	)
-	getOrdersToFollowUseCase := usecases.NewGetOrdersToFollowUseCase(
+	finishOrderWithPaymentUseCase := usecases.NewFinishOrderWithPaymentUseCase(
 		orderRepo,
-		sortOrders,
+		orderStateMachine,
+		orderEventNotifier,
 	)
-	getOrdersWaitingPaymentUseCase := usecases.NewGetOrdersWaitingPaymentUseCase(
+	deletePayingOrderUseCase := usecases.NewDeletePayingOrderUseCase(orderRepo)
+	createQRCodeOrderUseCase := usecases.NewCreateQRCodeOrderUseCase(
+		createPayingOrderUseCase,
+		usecases.QRCodeSettings{
+			PixKey:       environment.GetPixKey(),
+			MerchantName: environment.GetPixMerchantName(),
+			MerchantCity: environment.GetPixMerchantCity(),
+			Expiration:   environment.GetQRCodeExpiration(),
+		},
+	)
+	expirePayingOrdersUseCase := usecases.NewExpirePayingOrdersUseCase(
 		orderRepo,
-		sortOrders,
+		environment.GetQRCodeExpiration(),
 	)
-	updateToPreparingUseCase := usecases.NewUpdateToPreparingUseCase(
+	processPaymentNotificationUseCase := usecases.NewProcessPaymentNotificationUseCase(
 		orderRepo,
-		validateToPreare,
+		finishOrderWithPaymentUseCase,
 	)
-	updateToDoneUseCase := usecases.NewUpdateToDoneUseCase(
+	getOrderByIdUseCase := usecases.NewGetOrderByIdUseCase(orderRepo)
+	searchOrdersUseCase := usecases.NewSearchOrdersUseCase(orderRepo)
+	getOrderHistoryUseCase := usecases.NewGetOrderHistoryUseCase(orderRepo)
+	getOrdersToPrepareUseCase := usecases.NewGetOrdersToPrepareUseCase(
 		orderRepo,
-		validateToDone,
+		kitchenSortOrders,
 	)
-	updateToDeliveredUseCase := usecases.NewUpdateToDeliveredUseCase(
+	getOrdersToFollowUseCase := usecases.NewGetOrdersToFollowUseCase(
 		orderRepo,
-		validateToDeliveredOrNot,
+		pickupSortOrders,
 	)
-	updateToNotDeliveredUseCase := usecases.NewUpdateToNotDeliveredUseCase(
+	getOrdersWaitingPaymentUseCase := usecases.NewGetOrdersWaitingPaymentUseCase(
 		orderRepo,
-		validateToDeliveredOrNot,
+		pickupSortOrders,
 	)
+	updateOrderStatusUseCase := usecases.NewUpdateOrderStatusUseCase(
+		orderRepo,
+		orderStateMachine,
+		orderEventNotifier,
+	)
+	cancelOrderUseCase := usecases.NewCancelOrderUseCase(
+		orderRepo,
+		orderStateMachine,
+		orderEventNotifier,
+	)
+	updateOrderPriorityUseCase := usecases.NewUpdateOrderPriorityUseCase(orderRepo)
+	updateToPreparingUseCase := usecases.NewUpdateToPreparingUseCase(updateOrderStatusUseCase)
+	updateToDoneUseCase := usecases.NewUpdateToDoneUseCase(updateOrderStatusUseCase)
+	updateToDeliveredUseCase := usecases.NewUpdateToDeliveredUseCase(updateOrderStatusUseCase)
+	updateToNotDeliveredUseCase := usecases.NewUpdateToNotDeliveredUseCase(updateOrderStatusUseCase)
 
-	router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		httpserver.SendResponseSuccess(w, &responses.BusinessResponse{
-			StatusCode: 200,
-			Message:    "ok",
-		})
-	})
+	router.Get("/health", handler.HealthHandler(outboundTransport))
+
+	router.Post("/api/admin/categories", handler.CreateCategoryHandler(createCategoryUseCase))
+	router.Get("/api/admin/categories", handler.GetAdminCategoriesHandler(getCategoriesUseCase))
+	router.Get("/api/admin/categories/{id}", handler.GetCategoryByIdHandler(getCategoryByIdUseCase))
+	router.Put("/api/admin/categories/{id}", handler.UpdateCategoryHandler(updateCategoryUseCase))
+	router.Delete("/api/admin/categories/{id}", handler.DeleteCategoryHandler(deleteCategoryUseCase))
+	router.Get("/api/categories", handler.GetMenuCategoriesHandler(getCategoriesUseCase))
 
 	router.Post("/api/admin/products", handler.CreateProductHandler(createProductUseCase))
 	router.Delete("/api/admin/products/{id}", handler.DeleteProductHandler(deleteProductUseCase))
+	router.Post("/api/admin/products/{id}/restore", handler.RestoreProductHandler(restoreProductUseCase))
 	router.Put("/api/admin/products/{id}", handler.UpdateProductHandler(updateProductUseCase))
+	router.Patch("/api/admin/products/{id}", handler.PatchProductHandler(patchProductUseCase))
 	router.Get("/api/products/{id}", handler.GetProductsByIdHandler(getProductByIdUseCase))
 	router.Get("/api/products/categories", handler.GetCategoriesHandler(getCategoriesUseCase))
 	router.Get("/api/products/categories/{category}", handler.GetProductsByCategoryHandler(getProductsUseCase))
 
+	router.Post("/api/admin/combos", handler.CreateComboHandler(createComboUseCase))
+	router.Put("/api/admin/combos/{id}", handler.UpdateComboHandler(updateComboUseCase))
+	router.Get("/api/combos", handler.GetCombosHandler(getCombosUseCase))
+	router.Get("/api/combos/{id}", handler.GetComboByIdHandler(getComboByIdUseCase))
+
+	router.Get("/api/orders", handler.SearchOrdersHandler(searchOrdersUseCase))
 	router.Post("/api/orders", handler.CreateOrderHandler(createOrderUseCase))
+	router.Post("/api/orders/paying", handler.CreatePayingOrderHandler(createPayingOrderUseCase))
+	router.Post("/api/orders/qrcode", handler.CreateQRCodeOrderHandler(createQRCodeOrderUseCase))
 	router.Get("/api/orders/{id}", handler.GetOrderByIdHandler(getOrderByIdUseCase))
+	router.Delete("/api/orders/{id}", handler.DeletePayingOrderHandler(deletePayingOrderUseCase))
+	router.Get("/api/orders/{id}/history", handler.GetOrderHistoryHandler(getOrderHistoryUseCase))
+	router.Put("/api/orders/{id}/payment", handler.FinishOrderWithPaymentHandler(finishOrderWithPaymentUseCase))
+	router.Get("/api/orders/events", handler.OrderEventsHandler(orderEvents))
+	router.Get("/api/orders/events/ws", handler.OrderEventsWebSocketHandler(orderEvents))
 	router.Get("/api/orders/to-prepare", handler.GetOrdersToPrepareHandler(getOrdersToPrepareUseCase))
 	router.Get("/api/orders/follow", handler.GetOrdersToFollowHandler(getOrdersToFollowUseCase))
 	router.Get("/api/orders/waiting-payment", handler.GetOrdersWaitingPaymentHandler(getOrdersWaitingPaymentUseCase))
+	router.Put("/api/orders/{id}/status", handler.UpdateOrderStatusHandler(updateOrderStatusUseCase))
+	router.Put("/api/orders/{id}/cancel", handler.CancelOrderHandler(cancelOrderUseCase))
+	router.Put("/api/orders/{id}/priority", handler.UpdateOrderPriorityHandler(updateOrderPriorityUseCase))
 	router.Put("/api/orders/{id}/preparing", handler.UpdateOrderPreparingHandler(updateToPreparingUseCase))
 	router.Put("/api/orders/{id}/done", handler.UpdateOrderDoneHandler(updateToDoneUseCase))
 	router.Put("/api/orders/{id}/delivered", handler.UpdateOrderDeliveredHandler(updateToDeliveredUseCase))
 	router.Put("/api/orders/{id}/not-delivered", handler.UpdateOrderNotDeliveredandler(updateToNotDeliveredUseCase))
 
+	router.Post("/api/webhooks/payments", handler.PaymentWebhookHandler(
+		environment.GetWebhookSecret(),
+		processPaymentNotificationUseCase,
+	))
+
 	router.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("http://localhost:3210/swagger/doc.json"),
 	))
 
 	go http.ListenAndServe(":3211", doc.Handler())
 
+	adminRouter := chi.NewRouter()
+	adminRouter.Handle("/debug/vars", expvar.Handler())
+
+	go http.ListenAndServe(adminAddress, adminRouter)
+
+	go expirePayingOrders(expirePayingOrdersUseCase, time.Minute)
+
+	if webhookURL := environment.GetOutboxWebhookURL(); webhookURL != "" {
+		outboxRepo := repositories.NewOutboxRepository(db)
+		webhookPublisher := remote.NewWebhookEventPublisher(
+			httpClient,
+			webhookURL,
+			environment.GetOutboxWebhookSecret(),
+		)
+		relayOutboxEventsUseCase := usecases.NewRelayOutboxEventsUseCase(
+			outboxRepo,
+			webhookPublisher,
+			outboxRelaySettings,
+		)
+
+		purgeOutboxEventsUseCase := usecases.NewPurgeOutboxEventsUseCase(outboxRepo, outboxRelaySettings)
+
+		go relayOutboxEvents(relayOutboxEventsUseCase, time.Second)
+		go purgeOutboxEvents(purgeOutboxEventsUseCase, time.Hour)
+	} else {
+		log.Print("outbox relay not started, the order events stay pending until OUTBOX_WEBHOOK_URL is set")
+	}
+
 	server := httpserver.New(router)
 	server.Start()
 }
+
+// expirePayingOrders deletes the orders not paid in the QR Code expiration window
+func expirePayingOrders(expirePayingOrdersUseCase usecases.ExpirePayingOrdersUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		err := expirePayingOrdersUseCase.Execute(context.Background(), now)
+
+		if err != nil {
+			log.Print("expire paying orders", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// relayOutboxEvents delivers the order events written in the outbox to the downstream systems
+func relayOutboxEvents(relayOutboxEventsUseCase usecases.RelayOutboxEventsUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		err := relayOutboxEventsUseCase.Execute(context.Background(), now)
+
+		if err != nil {
+			log.Print("relay outbox events", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// purgeOutboxEvents deletes the order events delivered longer ago than the outbox retention
+func purgeOutboxEvents(purgeOutboxEventsUseCase usecases.PurgeOutboxEventsUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		err := purgeOutboxEventsUseCase.Execute(context.Background(), now)
+
+		if err != nil {
+			log.Print("purge outbox events", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
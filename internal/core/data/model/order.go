@@ -13,30 +13,97 @@ const (
 	OrderStatusDone         = "Finalizado"
 	OrderStatusDelivered    = "Entregue"
 	OrderStatusNotDelivered = "Não entregue"
+	OrderStatusCanceled     = "Cancelado"
+)
+
+const (
+	CancelReasonCustomerRequest = "CUSTOMER_REQUEST"
+	CancelReasonOutOfStock      = "OUT_OF_STOCK"
+	CancelReasonPaymentIssue    = "PAYMENT_ISSUE"
+	CancelReasonDuplicated      = "DUPLICATED"
+	CancelReasonOther           = "OTHER"
+)
+
+const (
+	RefundStatusPending = "Pendente"
+)
+
+const (
+	PaymentStatusPending  = "Pendente"
+	PaymentStatusApproved = "Aprovado"
+	PaymentStatusFailed   = "Recusado"
 )
 
 type Order struct {
 	gorm.Model
 	OrderStatus    string
 	TotalPrice     float64
-	PaymentID      string
-	CPF            *string
-	TicketNumber   int
+	PaymentID      string `gorm:"uniqueIndex:idx_orders_unique_payment_id,where:payment_id <> ''"`
+	PaymentStatus  string
+	CPF            *string `gorm:"index"`
+	TicketNumber   int     `gorm:"index"`
+	Priority       int
 	PreparingAt    *time.Time
 	DoneAt         *time.Time
 	DeliveredAt    *time.Time
 	NotDeliveredAt *time.Time
+	CanceledAt     *time.Time
+	CancelReason   string
+	CancelNote     string
 	OrderProduct   []OrderProduct
 }
 
 type OrderProduct struct {
 	gorm.Model
-	OrderID   uint
-	ProductID uint
-	Product   Product
+	OrderID      uint `gorm:"index"`
+	ProductID    uint
+	ProductPrice float64
+	Quantity     int `gorm:"default:1"`
+	Observations string
+	Product      Product
+	AddOns       []OrderProductAddOn
+	ComboItems   []OrderProductComboItem
+}
+
+type OrderProductAddOn struct {
+	gorm.Model
+	OrderProductID uint
+	ProductID      uint
+	ProductPrice   float64
+	Product        Product
+}
+
+// OrderProductComboItem is the product chosen for a combo slot, so the kitchen
+// knows what to prepare for the combo
+type OrderProductComboItem struct {
+	gorm.Model
+	OrderProductID uint `gorm:"index"`
+	ComboSlotID    uint
+	ProductID      uint
+	Product        Product
 }
 
 type OrderTicketNumber struct {
 	Date         int64 `gorm:"index;unique"`
 	TicketNumber int
 }
+
+// Refund is created when a paid order is cancelled, so the money can be returned to the customer
+type Refund struct {
+	gorm.Model
+	OrderID   uint `gorm:"unique"`
+	PaymentID string
+	Amount    float64
+	Status    string
+}
+
+// OrderStatusEvent is an append-only record of every order status change
+type OrderStatusEvent struct {
+	ID             uint `gorm:"primarykey"`
+	OrderID        uint `gorm:"index"`
+	PreviousStatus string
+	NewStatus      string
+	Actor          string
+	RequestID      string
+	CreatedAt      time.Time
+}
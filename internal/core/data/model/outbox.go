@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// OutboxEvent is written in the same transaction as the order change and delivered
+// later to the downstream systems by the outbox relay. Rows of the same order are
+// delivered in ID order. DeadAt is set on a row whose payload can not be read,
+// it is never delivered and is kept for inspection
+type OutboxEvent struct {
+	ID            uint `gorm:"primarykey"`
+	OrderID       uint `gorm:"index"`
+	Type          string
+	Payload       string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time  `gorm:"index"`
+	DeliveredAt   *time.Time `gorm:"index"`
+	DeadAt        *time.Time `gorm:"index"`
+	CreatedAt     time.Time
+}
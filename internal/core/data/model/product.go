@@ -1,6 +1,10 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 const (
 	CategorySnack    = "Lanche"
@@ -10,14 +14,42 @@ const (
 	CategoryCombo    = "Combo"
 )
 
+const (
+	ComboPricingFixed    = "fixed"
+	ComboPricingDiscount = "discount"
+)
+
+// Category groups the products in the menu. Inactive categories and their products
+// are hidden from the menu but kept for the admin. Only the products of a category
+// allowed in combo can be chosen in a combo slot. System categories are looked up
+// by name by the combos and add-ons, so they can not be renamed or deleted
+type Category struct {
+	gorm.Model
+	Name           string `gorm:"unique"`
+	DisplayOrder   int
+	Active         bool
+	ImageUrl       string
+	AllowedInCombo bool
+	System         bool
+}
+
+// Product is never deleted, so the orders can always show it. An archived product
+// is hidden from the menu and can not be ordered until it is restored
 type Product struct {
 	gorm.Model
 	Name         string `gorm:"unique"`
 	Description  string
-	Category     string
+	CategoryID   uint `gorm:"index"`
+	Category     Category
 	Price        float64
+	ArchivedAt   *time.Time `gorm:"index"`
 	ProductImage []ProductImage
 	ComboProduct []ComboProduct
+
+	// ComboPricing, ComboDiscount and ComboSlots are only filled for the combos created by the combo endpoints
+	ComboPricing  string
+	ComboDiscount float64
+	ComboSlots    []ComboSlot `gorm:"foreignKey:ComboID"`
 }
 
 type ProductImage struct {
@@ -31,3 +63,22 @@ type ComboProduct struct {
 	ProductID      uint
 	ComboProductID uint
 }
+
+// ComboSlot is one item of a combo. The customer picks one of the options, or any
+// product of the category when the slot has a category instead of options
+type ComboSlot struct {
+	gorm.Model
+	ComboID    uint `gorm:"index"`
+	Position   int
+	Name       string
+	CategoryID *uint
+	Category   *Category
+	Options    []ComboSlotOption
+}
+
+type ComboSlotOption struct {
+	gorm.Model
+	ComboSlotID uint `gorm:"index"`
+	ProductID   uint `gorm:"index"`
+	Product     Product
+}
@@ -2,6 +2,7 @@ package remote
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -37,6 +38,13 @@ func (ds *CustomerRemoteDataSourceImpl) GetCustomerByCPF(ctx context.Context, cp
 		model.Customer{},
 	)
 
+	var netError *responses.NetworkError
+
+	// a not found customer is kept as 404, so callers can tell it from an unavailable service
+	if errors.As(err, &netError) && netError.Code == http.StatusNotFound {
+		return model.Customer{}, netError
+	}
+
 	if err != nil {
 		return model.Customer{}, &responses.NetworkError{
 			Code:    http.StatusUnprocessableEntity,
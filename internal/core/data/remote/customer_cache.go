@@ -0,0 +1,201 @@
+package remote
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultCustomerLookupTimeout bounds the shared lookup when the settings do not
+const defaultCustomerLookupTimeout = 5 * time.Second
+
+// CustomerCacheSettings limits how many customers are kept and for how long.
+// NegativeTTL is used for the CPFs the customer service answered with 404.
+// LookupTimeout bounds the call shared by the concurrent lookups of a CPF
+type CustomerCacheSettings struct {
+	Size          int
+	TTL           time.Duration
+	NegativeTTL   time.Duration
+	LookupTimeout time.Duration
+}
+
+type CustomerCacheStats struct {
+	Hits         uint64 `json:"hits"`
+	NegativeHits uint64 `json:"negativeHits"`
+	Misses       uint64 `json:"misses"`
+	Evictions    uint64 `json:"evictions"`
+	Size         int    `json:"size"`
+}
+
+// CachedCustomerRemoteDataSource keeps the last customers looked up in memory. Concurrent
+// lookups of the same CPF share a single call to the customer service
+type CachedCustomerRemoteDataSource struct {
+	ds       CustomerRemoteDataSource
+	settings CustomerCacheSettings
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	recent  *list.List
+	group   singleflight.Group
+
+	hits         atomic.Uint64
+	negativeHits atomic.Uint64
+	misses       atomic.Uint64
+	evictions    atomic.Uint64
+}
+
+type customerCacheEntry struct {
+	cpf       string
+	customer  model.Customer
+	err       error
+	expiresAt time.Time
+}
+
+func NewCachedCustomerRemoteDataSource(
+	ds CustomerRemoteDataSource,
+	settings CustomerCacheSettings,
+) *CachedCustomerRemoteDataSource {
+	return &CachedCustomerRemoteDataSource{
+		ds:       ds,
+		settings: settings,
+		entries:  map[string]*list.Element{},
+		recent:   list.New(),
+	}
+}
+
+func (cache *CachedCustomerRemoteDataSource) GetCustomerByCPF(ctx context.Context, cpf string) (model.Customer, error) {
+	if entry, ok := cache.get(cpf); ok {
+		if entry.err != nil {
+			cache.negativeHits.Add(1)
+		} else {
+			cache.hits.Add(1)
+		}
+
+		return entry.customer, entry.err
+	}
+
+	cache.misses.Add(1)
+
+	// the lookup is shared, so it must not be cancelled by the first caller going away,
+	// but it is still bounded, or a hung customer service would hold the CPF forever
+	lookup := cache.group.DoChan(cpf, func() (interface{}, error) {
+		lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cache.lookupTimeout())
+		defer cancel()
+
+		customer, err := cache.ds.GetCustomerByCPF(lookupCtx, cpf)
+		cache.put(cpf, customer, err)
+
+		return customer, err
+	})
+
+	select {
+	case result := <-lookup:
+		if result.Err != nil {
+			return model.Customer{}, result.Err
+		}
+
+		return result.Val.(model.Customer), nil
+	case <-ctx.Done():
+		return model.Customer{}, ctx.Err()
+	}
+}
+
+func (cache *CachedCustomerRemoteDataSource) Stats() CustomerCacheStats {
+	cache.mu.Lock()
+	size := cache.recent.Len()
+	cache.mu.Unlock()
+
+	return CustomerCacheStats{
+		Hits:         cache.hits.Load(),
+		NegativeHits: cache.negativeHits.Load(),
+		Misses:       cache.misses.Load(),
+		Evictions:    cache.evictions.Load(),
+		Size:         size,
+	}
+}
+
+func (cache *CachedCustomerRemoteDataSource) lookupTimeout() time.Duration {
+	if cache.settings.LookupTimeout <= 0 {
+		return defaultCustomerLookupTimeout
+	}
+
+	return cache.settings.LookupTimeout
+}
+
+func (cache *CachedCustomerRemoteDataSource) get(cpf string) (customerCacheEntry, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	element, ok := cache.entries[cpf]
+
+	if !ok {
+		return customerCacheEntry{}, false
+	}
+
+	entry := element.Value.(*customerCacheEntry)
+
+	if time.Now().After(entry.expiresAt) {
+		cache.recent.Remove(element)
+		delete(cache.entries, cpf)
+
+		return customerCacheEntry{}, false
+	}
+
+	cache.recent.MoveToFront(element)
+
+	return *entry, true
+}
+
+// put keeps the customers found and the ones not found. Any other error is not
+// cached, so the next lookup tries the customer service again
+func (cache *CachedCustomerRemoteDataSource) put(cpf string, customer model.Customer, err error) {
+	ttl := cache.settings.TTL
+
+	if err != nil {
+		var netError *responses.NetworkError
+
+		if !errors.As(err, &netError) || netError.Code != http.StatusNotFound {
+			return
+		}
+
+		ttl = cache.settings.NegativeTTL
+	}
+
+	if ttl <= 0 || cache.settings.Size <= 0 {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry := &customerCacheEntry{
+		cpf:       cpf,
+		customer:  customer,
+		err:       err,
+		expiresAt: time.Now().Add(ttl),
+	}
+
+	if element, ok := cache.entries[cpf]; ok {
+		element.Value = entry
+		cache.recent.MoveToFront(element)
+
+		return
+	}
+
+	cache.entries[cpf] = cache.recent.PushFront(entry)
+
+	for cache.recent.Len() > cache.settings.Size {
+		oldest := cache.recent.Back()
+		cache.recent.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*customerCacheEntry).cpf)
+		cache.evictions.Add(1)
+	}
+}
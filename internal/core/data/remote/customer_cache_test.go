@@ -0,0 +1,221 @@
+package remote_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/remote"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+// countingCustomerDataSource answers with the customer named after the CPF,
+// after the delay, or with the error when it is set
+type countingCustomerDataSource struct {
+	calls atomic.Int32
+	delay time.Duration
+	err   error
+}
+
+func (ds *countingCustomerDataSource) GetCustomerByCPF(ctx context.Context, cpf string) (model.Customer, error) {
+	ds.calls.Add(1)
+	time.Sleep(ds.delay)
+
+	if ds.err != nil {
+		return model.Customer{}, ds.err
+	}
+
+	return model.Customer{Name: "Customer " + cpf, CPF: cpf}, nil
+}
+
+// hungCustomerDataSource only answers when the lookup context is done
+type hungCustomerDataSource struct{}
+
+func (ds *hungCustomerDataSource) GetCustomerByCPF(ctx context.Context, cpf string) (model.Customer, error) {
+	<-ctx.Done()
+
+	return model.Customer{}, ctx.Err()
+}
+
+var customerCacheSettings = remote.CustomerCacheSettings{
+	Size:        100,
+	TTL:         time.Minute,
+	NegativeTTL: time.Minute,
+}
+
+func TestCachedCustomerRemote(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got customer from cache when looking up the same cpf again", func(t *testing.T) {
+		t.Parallel()
+
+		ds := &countingCustomerDataSource{}
+		sut := remote.NewCachedCustomerRemoteDataSource(ds, customerCacheSettings)
+
+		for i := 0; i < 3; i++ {
+			response, err := sut.GetCustomerByCPF(context.TODO(), "12345678910")
+
+			assert.NoError(t, err)
+			assert.Equal(t, "Customer 12345678910", response.Name)
+		}
+
+		assert.Equal(t, int32(1), ds.calls.Load())
+		assert.Equal(t, remote.CustomerCacheStats{Hits: 2, Misses: 1, Size: 1}, sut.Stats())
+	})
+
+	t.Run("got not found from cache when the customer does not exist", func(t *testing.T) {
+		t.Parallel()
+
+		ds := &countingCustomerDataSource{
+			err: &responses.NetworkError{Code: http.StatusNotFound, Message: "customer not found"},
+		}
+		sut := remote.NewCachedCustomerRemoteDataSource(ds, customerCacheSettings)
+
+		for i := 0; i < 2; i++ {
+			_, err := sut.GetCustomerByCPF(context.TODO(), "12345678910")
+
+			var netError *responses.NetworkError
+			assert.True(t, errors.As(err, &netError))
+			assert.Equal(t, http.StatusNotFound, netError.Code)
+		}
+
+		assert.Equal(t, int32(1), ds.calls.Load())
+		assert.Equal(t, uint64(1), sut.Stats().NegativeHits)
+	})
+
+	t.Run("got customer service called again when it failed", func(t *testing.T) {
+		t.Parallel()
+
+		ds := &countingCustomerDataSource{
+			err: &responses.NetworkError{Code: http.StatusServiceUnavailable, Message: "unavailable"},
+		}
+		sut := remote.NewCachedCustomerRemoteDataSource(ds, customerCacheSettings)
+
+		for i := 0; i < 2; i++ {
+			_, err := sut.GetCustomerByCPF(context.TODO(), "12345678910")
+			assert.Error(t, err)
+		}
+
+		assert.Equal(t, int32(2), ds.calls.Load())
+		assert.Equal(t, 0, sut.Stats().Size)
+	})
+
+	t.Run("got customer service called again when the entry expired", func(t *testing.T) {
+		t.Parallel()
+
+		ds := &countingCustomerDataSource{}
+		sut := remote.NewCachedCustomerRemoteDataSource(ds, remote.CustomerCacheSettings{
+			Size:        10,
+			TTL:         20 * time.Millisecond,
+			NegativeTTL: 20 * time.Millisecond,
+		})
+
+		_, err := sut.GetCustomerByCPF(context.TODO(), "12345678910")
+		assert.NoError(t, err)
+
+		time.Sleep(40 * time.Millisecond)
+
+		_, err = sut.GetCustomerByCPF(context.TODO(), "12345678910")
+		assert.NoError(t, err)
+
+		assert.Equal(t, int32(2), ds.calls.Load())
+		assert.Equal(t, uint64(2), sut.Stats().Misses)
+	})
+
+	t.Run("got least recently used customer evicted when the cache is full", func(t *testing.T) {
+		t.Parallel()
+
+		ds := &countingCustomerDataSource{}
+		sut := remote.NewCachedCustomerRemoteDataSource(ds, remote.CustomerCacheSettings{
+			Size:        2,
+			TTL:         time.Minute,
+			NegativeTTL: time.Minute,
+		})
+
+		ctx := context.TODO()
+
+		for _, cpf := range []string{"1", "2", "1", "3"} {
+			_, err := sut.GetCustomerByCPF(ctx, cpf)
+			assert.NoError(t, err)
+		}
+
+		// 2 was the least recently used when 3 came in
+		_, err := sut.GetCustomerByCPF(ctx, "1")
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), ds.calls.Load())
+
+		_, err = sut.GetCustomerByCPF(ctx, "2")
+		assert.NoError(t, err)
+		assert.Equal(t, int32(4), ds.calls.Load())
+
+		stats := sut.Stats()
+		assert.Equal(t, 2, stats.Size)
+		assert.Equal(t, uint64(2), stats.Evictions)
+	})
+
+	t.Run("got one customer service call when looking up the same cpf concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		ds := &countingCustomerDataSource{delay: 100 * time.Millisecond}
+		sut := remote.NewCachedCustomerRemoteDataSource(ds, customerCacheSettings)
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				response, err := sut.GetCustomerByCPF(context.TODO(), "12345678910")
+
+				assert.NoError(t, err)
+				assert.Equal(t, "Customer 12345678910", response.Name)
+			}()
+		}
+
+		wg.Wait()
+
+		assert.Equal(t, int32(1), ds.calls.Load())
+	})
+
+	t.Run("got context error when the caller gives up waiting for the customer", func(t *testing.T) {
+		t.Parallel()
+
+		ds := &countingCustomerDataSource{delay: 100 * time.Millisecond}
+		sut := remote.NewCachedCustomerRemoteDataSource(ds, customerCacheSettings)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := sut.GetCustomerByCPF(ctx, "12345678910")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// the shared lookup goes on and is cached for the next callers
+		time.Sleep(150 * time.Millisecond)
+
+		response, err := sut.GetCustomerByCPF(context.TODO(), "12345678910")
+		assert.NoError(t, err)
+		assert.Equal(t, "Customer 12345678910", response.Name)
+		assert.Equal(t, int32(1), ds.calls.Load())
+	})
+
+	t.Run("got shared lookup stopped when the customer service hangs", func(t *testing.T) {
+		t.Parallel()
+
+		settings := customerCacheSettings
+		settings.LookupTimeout = 20 * time.Millisecond
+
+		sut := remote.NewCachedCustomerRemoteDataSource(&hungCustomerDataSource{}, settings)
+
+		_, err := sut.GetCustomerByCPF(context.TODO(), "12345678910")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 0, sut.Stats().Size)
+	})
+}
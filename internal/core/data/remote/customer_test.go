@@ -22,6 +22,10 @@ func setup() {
 	os.Setenv(environment.DBName, "Name")
 	os.Setenv(environment.Region, "Region")
 	os.Setenv(environment.CustomerRootAPI, "rootURL")
+	os.Setenv(environment.WebhookSecret, "WebhookSecret")
+	os.Setenv(environment.PixKey, "PixKey")
+	os.Setenv(environment.PixMerchantName, "PixMerchantName")
+	os.Setenv(environment.PixMerchantCity, "PixMerchantCity")
 }
 
 func TestCustomerRemote(t *testing.T) {
@@ -88,4 +92,30 @@ func TestCustomerRemote(t *testing.T) {
 		assert.Equal(t, true, isNetError)
 		assert.Equal(t, http.StatusUnprocessableEntity, netError.Code)
 	})
+
+	t.Run("got not found error when the customer does not exist remote", func(t *testing.T) {
+		t.Parallel()
+
+		environment.LoadEnvironmentVariables()
+
+		recorder := httptest.NewRecorder()
+		recorder.WriteHeader(http.StatusNotFound)
+		_, err := recorder.WriteString("customer not found")
+
+		assert.NoError(t, err)
+
+		mockClient := &http.Client{
+			Transport: &MockRoundTripper{
+				Response: recorder.Result(),
+			},
+		}
+
+		ds := remote.NewCustomerRemoteDataSource(mockClient, "rootURL")
+
+		_, err = ds.GetCustomerByCPF(context.TODO(), "12345678910")
+
+		var netError *responses.NetworkError
+		assert.True(t, errors.As(err, &netError))
+		assert.Equal(t, http.StatusNotFound, netError.Code)
+	})
 }
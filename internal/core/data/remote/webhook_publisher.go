@@ -0,0 +1,82 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+// WebhookEventPublisher POSTs every domain event to the downstream systems URL. When a secret
+// is given, the body is signed in the X-Signature header, like the payment webhook we receive
+type WebhookEventPublisher struct {
+	client *http.Client
+	url    string
+	secret string
+}
+
+func NewWebhookEventPublisher(client *http.Client, url string, secret string) events.EventPublisher {
+	return &WebhookEventPublisher{
+		client: client,
+		url:    url,
+		secret: secret,
+	}
+}
+
+func (publisher *WebhookEventPublisher) Publish(ctx context.Context, event events.DomainEvent) error {
+	body, err := json.Marshal(event)
+
+	if err != nil {
+		return &responses.NetworkError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, publisher.url, bytes.NewReader(body))
+
+	if err != nil {
+		return &responses.NetworkError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", fmt.Sprint(event.ID))
+	req.Header.Set("X-Event-Type", event.Type)
+
+	if publisher.secret != "" {
+		mac := hmac.New(sha256.New, []byte(publisher.secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	response, err := publisher.client.Do(req)
+
+	if err != nil {
+		return responses.GetNetworkError(err.(*url.Error))
+	}
+
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+
+	if err != nil {
+		return &responses.NetworkError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		}
+	}
+
+	return responses.IsNetworkResponseOk(response, string(responseBody))
+}
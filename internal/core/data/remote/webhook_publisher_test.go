@@ -0,0 +1,116 @@
+package remote_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/remote"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestWebhookEventPublisher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when publishing signed event to webhook", func(t *testing.T) {
+		t.Parallel()
+
+		var received events.DomainEvent
+		var headers http.Header
+		var body []byte
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = r.Header
+			body, _ = io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &received)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		sut := remote.NewWebhookEventPublisher(server.Client(), server.URL, "secret")
+
+		err := sut.Publish(context.TODO(), events.DomainEvent{
+			ID:          7,
+			Type:        events.OrderCreated,
+			OrderID:     12,
+			OrderStatus: "Criado",
+			TotalPrice:  45.9,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), received.ID)
+		assert.Equal(t, uint(12), received.OrderID)
+		assert.Equal(t, "Criado", received.OrderStatus)
+		assert.Equal(t, "7", headers.Get("X-Event-ID"))
+		assert.Equal(t, events.OrderCreated, headers.Get("X-Event-Type"))
+
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), headers.Get("X-Signature"))
+	})
+
+	t.Run("got no signature when publishing without secret to webhook", func(t *testing.T) {
+		t.Parallel()
+
+		var headers http.Header
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = r.Header
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		sut := remote.NewWebhookEventPublisher(server.Client(), server.URL, "")
+
+		err := sut.Publish(context.TODO(), events.DomainEvent{ID: 1, Type: events.OrderDeleted, OrderID: 1})
+
+		assert.NoError(t, err)
+		assert.Empty(t, headers.Get("X-Signature"))
+	})
+
+	t.Run("got error when webhook does not accept the event", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("down for maintenance"))
+		}))
+		defer server.Close()
+
+		sut := remote.NewWebhookEventPublisher(server.Client(), server.URL, "secret")
+
+		err := sut.Publish(context.TODO(), events.DomainEvent{ID: 1, Type: events.OrderCreated, OrderID: 1})
+
+		assert.Error(t, err)
+
+		var netError *responses.NetworkError
+		assert.True(t, errors.As(err, &netError))
+		assert.Equal(t, http.StatusServiceUnavailable, netError.Code)
+		assert.Equal(t, "down for maintenance", netError.Message)
+	})
+
+	t.Run("got error when webhook is unreachable", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		sut := remote.NewWebhookEventPublisher(server.Client(), server.URL, "secret")
+
+		err := sut.Publish(context.TODO(), events.DomainEvent{ID: 1, Type: events.OrderCreated, OrderID: 1})
+
+		assert.Error(t, err)
+
+		var netError *responses.NetworkError
+		assert.True(t, errors.As(err, &netError))
+	})
+}
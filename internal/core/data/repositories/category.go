@@ -0,0 +1,243 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1-orders/pkg/database"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CategoryRepository struct {
+	db *database.Database
+}
+
+func NewCategoryRepository(db *database.Database) repository.CategoryRepository {
+	return &CategoryRepository{
+		db: db,
+	}
+}
+
+func (repository *CategoryRepository) CreateCategory(ctx context.Context, category dto.CategoryForm) (uint, error) {
+	categoryEntity := &model.Category{
+		Name:           category.Name,
+		DisplayOrder:   category.DisplayOrder,
+		Active:         isCategoryActive(category),
+		ImageUrl:       category.ImageUrl,
+		AllowedInCombo: category.AllowedInCombo,
+	}
+
+	err := repository.db.Connection.WithContext(ctx).Create(categoryEntity).Error
+
+	if err != nil {
+		return 0, responses.GetDatabaseError(err)
+	}
+
+	return categoryEntity.ID, nil
+}
+
+func (repository *CategoryRepository) GetCategories(ctx context.Context, onlyActive bool) ([]dto.CategoryResponse, error) {
+	var categoryEntities []model.Category
+
+	query := repository.db.Connection.WithContext(ctx).Model(&model.Category{})
+
+	if onlyActive {
+		query = query.Where("active = ?", true)
+	}
+
+	err := query.Order("display_order, name").Find(&categoryEntities).Error
+
+	if err != nil {
+		return []dto.CategoryResponse{}, responses.GetDatabaseError(err)
+	}
+
+	categories := []dto.CategoryResponse{}
+
+	for _, value := range categoryEntities {
+		categories = append(categories, buildCategory(value))
+	}
+
+	return categories, nil
+}
+
+func (repository *CategoryRepository) GetCategoryById(ctx context.Context, id uint) (dto.CategoryResponse, error) {
+	var categoryEntity model.Category
+
+	err := repository.db.Connection.WithContext(ctx).First(&categoryEntity, id).Error
+
+	if err != nil {
+		return dto.CategoryResponse{}, responses.GetDatabaseError(err)
+	}
+
+	return buildCategory(categoryEntity), nil
+}
+
+func (repository *CategoryRepository) GetCategoryByName(ctx context.Context, name string) (dto.CategoryResponse, error) {
+	var categoryEntity model.Category
+
+	err := repository.db.Connection.WithContext(ctx).Where("name = ?", name).First(&categoryEntity).Error
+
+	if err != nil {
+		return dto.CategoryResponse{}, responses.GetDatabaseError(err)
+	}
+
+	return buildCategory(categoryEntity), nil
+}
+
+// UpdateCategory keeps the name of a system category, since the combos and add-ons look it up by name
+func (repository *CategoryRepository) UpdateCategory(ctx context.Context, category dto.CategoryForm) error {
+	var categoryEntity model.Category
+
+	err := repository.db.Connection.WithContext(ctx).First(&categoryEntity, category.Id).Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	if categoryEntity.System && categoryEntity.Name != category.Name {
+		return systemCategoryError(categoryEntity, "renamed")
+	}
+
+	result := repository.db.Connection.WithContext(ctx).
+		Model(&model.Category{Model: gorm.Model{ID: category.Id}}).
+		Select("Name", "DisplayOrder", "Active", "ImageUrl", "AllowedInCombo").
+		Updates(model.Category{
+			Name:           category.Name,
+			DisplayOrder:   category.DisplayOrder,
+			Active:         isCategoryActive(category),
+			ImageUrl:       category.ImageUrl,
+			AllowedInCombo: category.AllowedInCombo,
+		})
+
+	if result.Error != nil {
+		return responses.GetDatabaseError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Category not found",
+		}
+	}
+
+	return nil
+}
+
+// DeleteCategory removes the row, so the name can be used again. A category with products
+// or offered in a combo slot can not be deleted, they must be moved to another one or it can be deactivated.
+// System categories are never deleted
+func (repository *CategoryRepository) DeleteCategory(ctx context.Context, id uint) error {
+	tx := repository.db.Connection.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	var categoryEntity model.Category
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&categoryEntity, id).Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	if categoryEntity.System {
+		tx.Rollback()
+		return systemCategoryError(categoryEntity, "deleted")
+	}
+
+	var products int64
+
+	// deleted products still point to the category for the order history
+	err = tx.Unscoped().Model(&model.Product{}).Where("category_id = ?", id).Count(&products).Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	if products > 0 {
+		tx.Rollback()
+		return &responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: fmt.Sprintf("Category has %v products", products),
+		}
+	}
+
+	var slots int64
+
+	err = tx.Model(&model.ComboSlot{}).Where("category_id = ?", id).Count(&slots).Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	if slots > 0 {
+		tx.Rollback()
+		return &responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: fmt.Sprintf("Category is a choice in %v combo slots", slots),
+		}
+	}
+
+	result := tx.Unscoped().Delete(&model.Category{}, id)
+
+	if result.Error != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Category not found",
+		}
+	}
+
+	err = tx.Commit().Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+func systemCategoryError(category model.Category, action string) error {
+	return &responses.LocalError{
+		Code:    responses.DATABASE_CONFLICT_ERROR,
+		Message: fmt.Sprintf("Category %v is used by the system and can not be %v", category.Name, action),
+	}
+}
+
+// isCategoryActive is true when the form does not say otherwise, so a new category shows up in the menu
+func isCategoryActive(category dto.CategoryForm) bool {
+	return category.Active == nil || *category.Active
+}
+
+func buildCategory(value model.Category) dto.CategoryResponse {
+	return dto.CategoryResponse{
+		Id:             value.ID,
+		Name:           value.Name,
+		DisplayOrder:   value.DisplayOrder,
+		Active:         value.Active,
+		ImageUrl:       value.ImageUrl,
+		AllowedInCombo: value.AllowedInCombo,
+		System:         value.System,
+	}
+}
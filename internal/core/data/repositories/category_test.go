@@ -0,0 +1,345 @@
+package repositories_test
+
+import (
+	"errors"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/repositories"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/pkg/database"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func (suite *RepositoryTestSuite) TestGetCategoriesWithSuccess() {
+	repo := repositories.NewCategoryRepository(suite.db)
+
+	categories, err := repo.GetCategories(suite.ctx, true)
+	suite.NoError(err)
+	suite.Equal(5, len(categories))
+
+	suite.Equal(model.CategoryCombo, categories[0].Name)
+	suite.Equal(model.CategorySnack, categories[1].Name)
+	suite.Equal(model.CategoryBeverage, categories[2].Name)
+	suite.Equal(model.CategoryToppings, categories[3].Name)
+	suite.Equal(model.CategoryDesert, categories[4].Name)
+}
+
+func (suite *RepositoryTestSuite) TestCategoryLifecycleWithSuccess() {
+	repo := repositories.NewCategoryRepository(suite.db)
+
+	inactive := false
+
+	newId, err := repo.CreateCategory(suite.ctx, dto.CategoryForm{
+		Name:         "Saladas",
+		DisplayOrder: 0,
+		Active:       &inactive,
+		ImageUrl:     "ImageUrl",
+	})
+	suite.NoError(err)
+
+	categories, err := repo.GetCategories(suite.ctx, true)
+	suite.NoError(err)
+	suite.Equal(5, len(categories))
+
+	categories, err = repo.GetCategories(suite.ctx, false)
+	suite.NoError(err)
+	suite.Equal(6, len(categories))
+	suite.Equal("Saladas", categories[0].Name)
+
+	err = repo.UpdateCategory(suite.ctx, dto.CategoryForm{
+		Id:           newId,
+		Name:         "Saladas",
+		DisplayOrder: 6,
+		ImageUrl:     "NewImageUrl",
+	})
+	suite.NoError(err)
+
+	category, err := repo.GetCategoryById(suite.ctx, newId)
+	suite.NoError(err)
+	suite.True(category.Active)
+	suite.Equal(6, category.DisplayOrder)
+	suite.Equal("NewImageUrl", category.ImageUrl)
+
+	err = repo.DeleteCategory(suite.ctx, newId)
+	suite.NoError(err)
+
+	_, err = repo.GetCategoryByName(suite.ctx, "Saladas")
+
+	var localError *responses.LocalError
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.NOT_FOUND_ERROR, localError.Code)
+
+	// the name can be used again after the category is deleted
+	_, err = repo.CreateCategory(suite.ctx, dto.CategoryForm{Name: "Saladas"})
+	suite.NoError(err)
+}
+
+func (suite *RepositoryTestSuite) TestCreateCategoryWithConflictError() {
+	repo := repositories.NewCategoryRepository(suite.db)
+
+	_, err := repo.CreateCategory(suite.ctx, dto.CategoryForm{Name: model.CategoryBeverage})
+
+	var localError *responses.LocalError
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+}
+
+func (suite *RepositoryTestSuite) TestDeleteCategoryWithProductsError() {
+	repo := repositories.NewCategoryRepository(suite.db)
+	repoProduct := repositories.NewProductRepository(suite.db)
+
+	_, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategoryBeverage,
+		Price:       990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	category, err := repo.GetCategoryByName(suite.ctx, model.CategoryBeverage)
+	suite.NoError(err)
+
+	err = repo.DeleteCategory(suite.ctx, category.Id)
+
+	var localError *responses.LocalError
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+}
+
+func (suite *RepositoryTestSuite) TestProductsHiddenWhenCategoryIsInactiveSuccess() {
+	repo := repositories.NewCategoryRepository(suite.db)
+	repoProduct := repositories.NewProductRepository(suite.db)
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategoryDesert,
+		Price:       990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	category, err := repo.GetCategoryByName(suite.ctx, model.CategoryDesert)
+	suite.NoError(err)
+
+	inactive := false
+
+	err = repo.UpdateCategory(suite.ctx, dto.CategoryForm{
+		Id:           category.Id,
+		Name:         category.Name,
+		DisplayOrder: category.DisplayOrder,
+		Active:       &inactive,
+	})
+	suite.NoError(err)
+
+	products, err := repoProduct.GetProductsByCategory(suite.ctx, model.CategoryDesert)
+	suite.NoError(err)
+	suite.Empty(products)
+
+	// still resolvable by id, like for the orders
+	product, err := repoProduct.GetProductById(suite.ctx, newId)
+	suite.NoError(err)
+	suite.Equal(model.CategoryDesert, product.Category)
+	suite.Equal(category.Id, product.CategoryId)
+}
+
+func (suite *RepositoryTestSuite) TestCreateProductWithUnknownCategoryError() {
+	repoProduct := repositories.NewProductRepository(suite.db)
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    "Bebidas",
+		Price:       990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+
+	suite.Equal(uint(0), newId)
+
+	var localError *responses.LocalError
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.NOT_FOUND_ERROR, localError.Code)
+}
+
+func (suite *RepositoryTestSuite) TestMigrateCategoriesFromProductColumnSuccess() {
+	// products as they were before the categories table
+	suite.db.Connection.Exec("DROP TABLE IF EXISTS categories CASCADE;")
+	suite.db.Connection.Exec("DROP TABLE IF EXISTS products CASCADE;")
+
+	err := suite.db.Connection.Exec(`CREATE TABLE products (
+		id bigserial PRIMARY KEY,
+		created_at timestamptz,
+		updated_at timestamptz,
+		deleted_at timestamptz,
+		name text UNIQUE,
+		description text,
+		category text,
+		price numeric
+	)`).Error
+	suite.NoError(err)
+
+	err = suite.db.Connection.Exec(`INSERT INTO products (name, description, category, price) VALUES
+		('Burger', 'Burger', 'Lanche', 10),
+		('Soda', 'Soda', 'Bebidas', 5)`).Error
+	suite.NoError(err)
+
+	err = database.MigrateCategories(suite.db.Connection)
+	suite.NoError(err)
+
+	err = suite.db.Connection.AutoMigrate(&model.Category{}, &model.Product{})
+	suite.NoError(err)
+
+	suite.False(suite.db.Connection.Migrator().HasColumn("products", "category"))
+
+	repo := repositories.NewCategoryRepository(suite.db)
+	repoProduct := repositories.NewProductRepository(suite.db)
+
+	burgers, err := repoProduct.GetProductsByCategory(suite.ctx, model.CategorySnack)
+	suite.NoError(err)
+	suite.Equal(1, len(burgers))
+	suite.Equal("Burger", burgers[0].Name)
+
+	// the unknown category is kept for the admin, still hidden from the menu
+	imported, err := repo.GetCategoryByName(suite.ctx, "Bebidas")
+	suite.NoError(err)
+	suite.False(imported.Active)
+	suite.False(imported.AllowedInCombo)
+	suite.Equal(6, imported.DisplayOrder)
+
+	sodas, err := repoProduct.GetProductsByCategory(suite.ctx, "Bebidas")
+	suite.NoError(err)
+	suite.Empty(sodas)
+
+	// running it again changes nothing
+	err = database.MigrateCategories(suite.db.Connection)
+	suite.NoError(err)
+
+	categories, err := repo.GetCategories(suite.ctx, false)
+	suite.NoError(err)
+	suite.Equal(6, len(categories))
+}
+
+func (suite *RepositoryTestSuite) TestMigrateCategoriesAllowedInComboSuccess() {
+	// categories as they were before the combo flag
+	suite.db.Connection.Exec("DROP TABLE IF EXISTS categories CASCADE;")
+
+	err := suite.db.Connection.Exec(`CREATE TABLE categories (
+		id bigserial PRIMARY KEY,
+		created_at timestamptz,
+		updated_at timestamptz,
+		deleted_at timestamptz,
+		name text UNIQUE,
+		display_order bigint,
+		active boolean,
+		image_url text
+	)`).Error
+	suite.NoError(err)
+
+	err = suite.db.Connection.Exec(`INSERT INTO categories (name, display_order, active, image_url) VALUES
+		('Combo', 1, true, ''),
+		('Lanche', 2, true, ''),
+		('Promo', 6, true, '')`).Error
+	suite.NoError(err)
+
+	err = database.MigrateCategories(suite.db.Connection)
+	suite.NoError(err)
+
+	repo := repositories.NewCategoryRepository(suite.db)
+
+	snack, err := repo.GetCategoryByName(suite.ctx, model.CategorySnack)
+	suite.NoError(err)
+	suite.True(snack.AllowedInCombo)
+
+	combo, err := repo.GetCategoryByName(suite.ctx, model.CategoryCombo)
+	suite.NoError(err)
+	suite.False(combo.AllowedInCombo)
+	suite.True(combo.System)
+
+	promo, err := repo.GetCategoryByName(suite.ctx, "Promo")
+	suite.NoError(err)
+	suite.False(promo.AllowedInCombo)
+}
+
+func (suite *RepositoryTestSuite) TestUpdateAndDeleteSystemCategoryError() {
+	repo := repositories.NewCategoryRepository(suite.db)
+
+	combo, err := repo.GetCategoryByName(suite.ctx, model.CategoryCombo)
+	suite.NoError(err)
+	suite.True(combo.System)
+
+	err = repo.UpdateCategory(suite.ctx, dto.CategoryForm{
+		Id:   combo.Id,
+		Name: "Combos",
+	})
+
+	var localError *responses.LocalError
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+
+	// the other fields of a system category can still be changed
+	err = repo.UpdateCategory(suite.ctx, dto.CategoryForm{
+		Id:           combo.Id,
+		Name:         model.CategoryCombo,
+		DisplayOrder: 9,
+	})
+	suite.NoError(err)
+
+	toppings, err := repo.GetCategoryByName(suite.ctx, model.CategoryToppings)
+	suite.NoError(err)
+
+	err = repo.DeleteCategory(suite.ctx, toppings.Id)
+
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+
+	_, err = repo.GetCategoryByName(suite.ctx, model.CategoryToppings)
+	suite.NoError(err)
+}
+
+func (suite *RepositoryTestSuite) TestMigrateCategoriesKeepsDeletedCategorySuccess() {
+	repo := repositories.NewCategoryRepository(suite.db)
+
+	desert, err := repo.GetCategoryByName(suite.ctx, model.CategoryDesert)
+	suite.NoError(err)
+	suite.False(desert.System)
+
+	err = repo.DeleteCategory(suite.ctx, desert.Id)
+	suite.NoError(err)
+
+	snack, err := repo.GetCategoryByName(suite.ctx, model.CategorySnack)
+	suite.NoError(err)
+
+	err = repo.UpdateCategory(suite.ctx, dto.CategoryForm{
+		Id:             snack.Id,
+		Name:           "Sanduíches",
+		DisplayOrder:   snack.DisplayOrder,
+		AllowedInCombo: true,
+	})
+	suite.NoError(err)
+
+	err = database.MigrateCategories(suite.db.Connection)
+	suite.NoError(err)
+
+	categories, err := repo.GetCategories(suite.ctx, false)
+	suite.NoError(err)
+	suite.Equal(4, len(categories))
+
+	_, err = repo.GetCategoryByName(suite.ctx, model.CategoryDesert)
+	suite.Error(err)
+
+	_, err = repo.GetCategoryByName(suite.ctx, model.CategorySnack)
+	suite.Error(err)
+}
@@ -0,0 +1,481 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1-orders/pkg/database"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+type ComboRepository struct {
+	db *database.Database
+}
+
+func NewComboRepository(db *database.Database) repository.ComboRepository {
+	return &ComboRepository{
+		db: db,
+	}
+}
+
+// CreateCombo saves the combo as a product of the Combo category, so it is listed, ordered
+// and archived like any other product, with its slots and the options of each slot
+func (repository *ComboRepository) CreateCombo(ctx context.Context, combo dto.ComboForm) (uint, error) {
+	tx := repository.db.Connection.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return 0, responses.GetDatabaseError(err)
+	}
+
+	categoryId, err := findCategoryId(tx, model.CategoryCombo)
+
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	comboEntity := &model.Product{
+		Name:          combo.Name,
+		Description:   combo.Description,
+		CategoryID:    categoryId,
+		Price:         combo.Price,
+		ComboPricing:  combo.Pricing,
+		ComboDiscount: combo.Discount,
+	}
+
+	err = tx.Create(comboEntity).Error
+
+	if err != nil {
+		tx.Rollback()
+		return 0, responses.GetDatabaseError(err)
+	}
+
+	err = updateProductImages(tx, comboEntity.ID, combo.Images)
+
+	if err != nil {
+		tx.Rollback()
+		return 0, responses.GetDatabaseError(err)
+	}
+
+	err = createComboSlots(tx, comboEntity.ID, combo.Slots)
+
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	err = tx.Commit().Error
+
+	if err != nil {
+		tx.Rollback()
+		return 0, responses.GetDatabaseError(err)
+	}
+
+	return comboEntity.ID, nil
+}
+
+// UpdateCombo replaces the slots of the combo. The old slots are soft deleted, the orders
+// keep pointing to them. A combo created as a product has its products moved to the slots
+func (repository *ComboRepository) UpdateCombo(ctx context.Context, combo dto.ComboForm) error {
+	tx := repository.db.Connection.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	categoryId, err := findCategoryId(tx, model.CategoryCombo)
+
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result := tx.Model(&model.Product{Model: gorm.Model{ID: combo.Id}}).
+		Where("category_id = ?", categoryId).
+		Select("Name", "Description", "Price", "ComboPricing", "ComboDiscount").
+		Updates(model.Product{
+			Name:          combo.Name,
+			Description:   combo.Description,
+			Price:         combo.Price,
+			ComboPricing:  combo.Pricing,
+			ComboDiscount: combo.Discount,
+		})
+
+	if result.Error != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Combo not found",
+		}
+	}
+
+	err = updateProductImages(tx, combo.Id, combo.Images)
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	var slotIds []uint
+
+	err = tx.Model(&model.ComboSlot{}).Where("combo_id = ?", combo.Id).Pluck("id", &slotIds).Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	if len(slotIds) > 0 {
+		err = tx.Where("combo_slot_id IN ?", slotIds).Delete(&model.ComboSlotOption{}).Error
+
+		if err != nil {
+			tx.Rollback()
+			return responses.GetDatabaseError(err)
+		}
+
+		err = tx.Delete(&model.ComboSlot{}, slotIds).Error
+
+		if err != nil {
+			tx.Rollback()
+			return responses.GetDatabaseError(err)
+		}
+	}
+
+	err = tx.Where("product_id = ?", combo.Id).Unscoped().Delete(&model.ComboProduct{}).Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	err = createComboSlots(tx, combo.Id, combo.Slots)
+
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit().Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+func createComboSlots(tx *gorm.DB, comboId uint, slots []dto.ComboSlotForm) error {
+	productIds := []uint{}
+
+	for _, slot := range slots {
+		productIds = append(productIds, slot.ProductIds...)
+	}
+
+	err := lockComboProducts(tx, productIds)
+
+	if err != nil {
+		return err
+	}
+
+	for position, slot := range slots {
+		slotEntity := &model.ComboSlot{
+			ComboID:  comboId,
+			Position: position,
+			Name:     slot.Name,
+		}
+
+		if slot.Category != "" {
+			categoryId, err := findCategoryId(tx, slot.Category)
+
+			if err != nil {
+				return err
+			}
+
+			slotEntity.CategoryID = &categoryId
+		}
+
+		err = tx.Create(slotEntity).Error
+
+		if err != nil {
+			return responses.GetDatabaseError(err)
+		}
+
+		if len(slot.ProductIds) == 0 {
+			continue
+		}
+
+		options := []*model.ComboSlotOption{}
+
+		for _, productId := range slot.ProductIds {
+			options = append(options, &model.ComboSlotOption{
+				ComboSlotID: slotEntity.ID,
+				ProductID:   productId,
+			})
+		}
+
+		err = tx.Create(options).Error
+
+		if err != nil {
+			return responses.GetDatabaseError(err)
+		}
+	}
+
+	return nil
+}
+
+// GetCombos lists the combos of the menu
+func (repository *ComboRepository) GetCombos(ctx context.Context) ([]dto.Combo, error) {
+	var comboEntities []model.Product
+
+	err := repository.comboQuery(ctx).
+		Joins("JOIN categories ON categories.id = products.category_id AND categories.deleted_at IS NULL").
+		Where("categories.name = ? AND categories.active = ? AND products.archived_at IS NULL", model.CategoryCombo, true).
+		Order("products.id").
+		Find(&comboEntities).
+		Error
+
+	if err != nil {
+		return []dto.Combo{}, responses.GetDatabaseError(err)
+	}
+
+	return repository.buildCombos(ctx, comboEntities)
+}
+
+func (repository *ComboRepository) GetComboById(ctx context.Context, id uint) (dto.Combo, error) {
+	combos, err := repository.GetCombosByIds(ctx, []uint{id})
+
+	if err != nil {
+		return dto.Combo{}, err
+	}
+
+	if len(combos) == 0 {
+		return dto.Combo{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Combo not found",
+		}
+	}
+
+	return combos[0], nil
+}
+
+// GetCombosByIds answers the archived combos too, the orders and the admin still need them
+func (repository *ComboRepository) GetCombosByIds(ctx context.Context, ids []uint) ([]dto.Combo, error) {
+	var comboEntities []model.Product
+
+	err := repository.comboQuery(ctx).
+		Joins("JOIN categories ON categories.id = products.category_id").
+		Where("categories.name = ? AND products.id IN ?", model.CategoryCombo, ids).
+		Order("products.id").
+		Find(&comboEntities).
+		Error
+
+	if err != nil {
+		return []dto.Combo{}, responses.GetDatabaseError(err)
+	}
+
+	return repository.buildCombos(ctx, comboEntities)
+}
+
+func (repository *ComboRepository) comboQuery(ctx context.Context) *gorm.DB {
+	return repository.db.Connection.WithContext(ctx).
+		Model(&model.Product{}).
+		Preload("ProductImage").
+		Preload("ComboProduct").
+		Preload("ComboSlots", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position")
+		}).
+		Preload("ComboSlots.Category").
+		Preload("ComboSlots.Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Preload("ComboSlots.Options.Product.Category").
+		Preload("ComboSlots.Options.Product.ProductImage")
+}
+
+// buildCombos loads, with one query each, the products of the category slots and the
+// products of the combos created as a product, which have no slots
+func (repository *ComboRepository) buildCombos(ctx context.Context, comboEntities []model.Product) ([]dto.Combo, error) {
+	categoryIds := []uint{}
+	productIds := []uint{}
+
+	for _, value := range comboEntities {
+		for _, slot := range value.ComboSlots {
+			if slot.CategoryID != nil {
+				categoryIds = append(categoryIds, *slot.CategoryID)
+			}
+		}
+
+		if len(value.ComboSlots) == 0 {
+			for _, comboProduct := range value.ComboProduct {
+				productIds = append(productIds, comboProduct.ComboProductID)
+			}
+		}
+	}
+
+	categoryProducts := map[uint][]dto.ProductResponse{}
+
+	if len(categoryIds) > 0 {
+		var productEntities []model.Product
+
+		err := repository.componentQuery(ctx).
+			Where("category_id IN ? AND archived_at IS NULL", categoryIds).
+			Find(&productEntities).
+			Error
+
+		if err != nil {
+			return []dto.Combo{}, responses.GetDatabaseError(err)
+		}
+
+		for _, value := range productEntities {
+			categoryProducts[value.CategoryID] = append(categoryProducts[value.CategoryID], buildComboOption(value))
+		}
+	}
+
+	products := map[uint]dto.ProductResponse{}
+
+	if len(productIds) > 0 {
+		var productEntities []model.Product
+
+		err := repository.componentQuery(ctx).
+			Where("id IN ?", productIds).
+			Find(&productEntities).
+			Error
+
+		if err != nil {
+			return []dto.Combo{}, responses.GetDatabaseError(err)
+		}
+
+		for _, value := range productEntities {
+			products[value.ID] = buildComboOption(value)
+		}
+	}
+
+	combos := []dto.Combo{}
+
+	for _, value := range comboEntities {
+		combos = append(combos, buildCombo(value, categoryProducts, products))
+	}
+
+	return combos, nil
+}
+
+func (repository *ComboRepository) componentQuery(ctx context.Context) *gorm.DB {
+	return repository.db.Connection.WithContext(ctx).
+		Model(&model.Product{}).
+		Preload("Category").
+		Preload("ProductImage").
+		Order("id")
+}
+
+func buildCombo(
+	value model.Product,
+	categoryProducts map[uint][]dto.ProductResponse,
+	products map[uint]dto.ProductResponse,
+) dto.Combo {
+	images := []dto.ProducImage{}
+
+	for _, valueImage := range value.ProductImage {
+		images = append(images, dto.ProducImage{
+			ImageUrl: valueImage.ImageUrl,
+		})
+	}
+
+	slots := []dto.ComboSlot{}
+
+	for _, slot := range value.ComboSlots {
+		comboSlot := dto.ComboSlot{
+			Id:      slot.ID,
+			Name:    slot.Name,
+			Options: []dto.ProductResponse{},
+		}
+
+		if slot.CategoryID != nil {
+			if slot.Category != nil {
+				comboSlot.Category = slot.Category.Name
+			}
+
+			comboSlot.Options = append(comboSlot.Options, categoryProducts[*slot.CategoryID]...)
+		}
+
+		for _, option := range slot.Options {
+			comboSlot.Options = append(comboSlot.Options, buildComboOption(option.Product))
+		}
+
+		slots = append(slots, comboSlot)
+	}
+
+	// a combo created as a product has a fixed slot for each of its products
+	if len(value.ComboSlots) == 0 {
+		for _, comboProduct := range value.ComboProduct {
+			product, ok := products[comboProduct.ComboProductID]
+
+			if !ok {
+				continue
+			}
+
+			slots = append(slots, dto.ComboSlot{
+				Name:    product.Name,
+				Options: []dto.ProductResponse{product},
+			})
+		}
+	}
+
+	pricing := value.ComboPricing
+
+	if pricing == "" {
+		pricing = model.ComboPricingFixed
+	}
+
+	return dto.Combo{
+		Id:          value.ID,
+		Name:        value.Name,
+		Description: value.Description,
+		Pricing:     pricing,
+		Price:       value.Price,
+		Discount:    value.ComboDiscount,
+		Images:      images,
+		ArchivedAt:  value.ArchivedAt,
+		Slots:       slots,
+	}
+}
+
+func buildComboOption(value model.Product) dto.ProductResponse {
+	images := []dto.ProducImage{}
+
+	for _, valueImage := range value.ProductImage {
+		images = append(images, dto.ProducImage{
+			ImageUrl: valueImage.ImageUrl,
+		})
+	}
+
+	return dto.ProductResponse{
+		Id:          value.ID,
+		Name:        value.Name,
+		Description: value.Description,
+		CategoryId:  value.CategoryID,
+		Category:    value.Category.Name,
+		Price:       value.Price,
+		Images:      images,
+		ArchivedAt:  value.ArchivedAt,
+	}
+}
@@ -1,12 +1,15 @@
 package repositories_test
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/data/repositories"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
 )
 
 func TestComboRepository(t *testing.T) {
@@ -26,7 +29,7 @@ func (suite *RepositoryTestSuite) TestCreateComboWithSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -43,7 +46,7 @@ func (suite *RepositoryTestSuite) TestCreateComboWithSuccess() {
 	newProduct2 := dto.ProductForm{
 		Name:        "New Product Created 2",
 		Description: "New Description Product Created 2",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       990,
 		Images: []dto.ProducImage{
 			{
@@ -60,7 +63,7 @@ func (suite *RepositoryTestSuite) TestCreateComboWithSuccess() {
 	newProduct3 := dto.ProductForm{
 		Name:        "New Product Created 3",
 		Description: "New Description Product Created 3",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       1990,
 		Images: []dto.ProducImage{
 			{
@@ -82,7 +85,7 @@ func (suite *RepositoryTestSuite) TestCreateComboWithSuccess() {
 	newCombo := dto.ProductForm{
 		Name:        "New Combo",
 		Description: "New Description Combo",
-		Category:    "Combo",
+		Category:    model.CategoryCombo,
 		Price:       1990,
 		Images: []dto.ProducImage{
 			{
@@ -96,3 +99,459 @@ func (suite *RepositoryTestSuite) TestCreateComboWithSuccess() {
 	suite.NoError(err)
 	suite.Equal(uint(4), comboId)
 }
+
+func (suite *RepositoryTestSuite) createComboComponents() (uint, uint, uint) {
+	repo := repositories.NewProductRepository(suite.db)
+
+	burgerId, err := repo.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "Burger",
+		Description: "Burger Description",
+		Category:    model.CategorySnack,
+		Price:       2000,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	sodaId, err := repo.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "Soda",
+		Description: "Soda Description",
+		Category:    model.CategoryBeverage,
+		Price:       600,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	juiceId, err := repo.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "Juice",
+		Description: "Juice Description",
+		Category:    model.CategoryBeverage,
+		Price:       800,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	return burgerId, sodaId, juiceId
+}
+
+func (suite *RepositoryTestSuite) TestCreateComboWithSlotsSuccess() {
+	burgerId, sodaId, juiceId := suite.createComboComponents()
+
+	repo := repositories.NewComboRepository(suite.db)
+
+	comboId, err := repo.CreateCombo(suite.ctx, dto.ComboForm{
+		Name:        "Burger Combo",
+		Description: "Burger Combo Description",
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+		Pricing:  model.ComboPricingDiscount,
+		Price:    2340,
+		Discount: 10,
+		Slots: []dto.ComboSlotForm{
+			{
+				Name:       "Lanche",
+				ProductIds: []uint{burgerId},
+			},
+			{
+				Name:     "Bebida",
+				Category: model.CategoryBeverage,
+			},
+		},
+	})
+	suite.NoError(err)
+
+	combo, err := repo.GetComboById(suite.ctx, comboId)
+	suite.NoError(err)
+	suite.Equal("Burger Combo", combo.Name)
+	suite.Equal(model.ComboPricingDiscount, combo.Pricing)
+	suite.Equal(float64(10), combo.Discount)
+	suite.Equal(2, len(combo.Slots))
+	suite.Equal("Lanche", combo.Slots[0].Name)
+	suite.Equal(1, len(combo.Slots[0].Options))
+	suite.Equal(burgerId, combo.Slots[0].Options[0].Id)
+	suite.Equal(model.CategoryBeverage, combo.Slots[1].Category)
+	suite.Equal(2, len(combo.Slots[1].Options))
+	suite.Equal(sodaId, combo.Slots[1].Options[0].Id)
+	suite.Equal(juiceId, combo.Slots[1].Options[1].Id)
+
+	combos, err := repo.GetCombos(suite.ctx)
+	suite.NoError(err)
+	suite.Equal(1, len(combos))
+	suite.Equal(comboId, combos[0].Id)
+
+	// the combo is a product of the Combo category for the menu and the orders
+	product, err := repositories.NewProductRepository(suite.db).GetProductById(suite.ctx, comboId)
+	suite.NoError(err)
+	suite.Equal(model.CategoryCombo, product.Category)
+}
+
+func (suite *RepositoryTestSuite) TestUpdateComboReplacesSlotsSuccess() {
+	burgerId, sodaId, _ := suite.createComboComponents()
+
+	repo := repositories.NewComboRepository(suite.db)
+
+	combo := dto.ComboForm{
+		Name:        "Burger Combo",
+		Description: "Burger Combo Description",
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+		Pricing: model.ComboPricingFixed,
+		Price:   2200,
+		Slots: []dto.ComboSlotForm{
+			{
+				Name:       "Lanche",
+				ProductIds: []uint{burgerId},
+			},
+			{
+				Name:     "Bebida",
+				Category: model.CategoryBeverage,
+			},
+		},
+	}
+
+	comboId, err := repo.CreateCombo(suite.ctx, combo)
+	suite.NoError(err)
+
+	combo.Id = comboId
+	combo.Price = 2500
+	combo.Slots = []dto.ComboSlotForm{
+		{
+			Name:       "Bebida",
+			ProductIds: []uint{sodaId},
+		},
+	}
+
+	err = repo.UpdateCombo(suite.ctx, combo)
+	suite.NoError(err)
+
+	updatedCombo, err := repo.GetComboById(suite.ctx, comboId)
+	suite.NoError(err)
+	suite.Equal(float64(2500), updatedCombo.Price)
+	suite.Equal(1, len(updatedCombo.Slots))
+	suite.Equal(sodaId, updatedCombo.Slots[0].Options[0].Id)
+
+	// the burger is no longer in the combo, so it can be archived
+	err = repositories.NewProductRepository(suite.db).ArchiveProduct(suite.ctx, burgerId)
+	suite.NoError(err)
+}
+
+func (suite *RepositoryTestSuite) TestUpdateComboWithUnknownIdError() {
+	burgerId, _, _ := suite.createComboComponents()
+
+	repo := repositories.NewComboRepository(suite.db)
+
+	err := repo.UpdateCombo(suite.ctx, dto.ComboForm{
+		Id:          burgerId,
+		Name:        "Burger Combo",
+		Description: "Burger Combo Description",
+		Pricing:     model.ComboPricingFixed,
+		Price:       2200,
+		Slots: []dto.ComboSlotForm{
+			{
+				Name:       "Lanche",
+				ProductIds: []uint{burgerId},
+			},
+		},
+	})
+	suite.Error(err)
+
+	var localError *responses.LocalError
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.NOT_FOUND_ERROR, localError.Code)
+}
+
+func (suite *RepositoryTestSuite) TestArchiveComboSlotOptionConflictError() {
+	burgerId, sodaId, _ := suite.createComboComponents()
+
+	comboId, err := repositories.NewComboRepository(suite.db).CreateCombo(suite.ctx, dto.ComboForm{
+		Name:        "Burger Combo",
+		Description: "Burger Combo Description",
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+		Pricing: model.ComboPricingFixed,
+		Price:   2200,
+		Slots: []dto.ComboSlotForm{
+			{
+				Name:       "Menu",
+				ProductIds: []uint{burgerId, sodaId},
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repo := repositories.NewProductRepository(suite.db)
+
+	err = repo.ArchiveProduct(suite.ctx, sodaId)
+	suite.Error(err)
+
+	var localError *responses.LocalError
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+
+	err = repo.ArchiveProduct(suite.ctx, comboId)
+	suite.NoError(err)
+
+	err = repo.ArchiveProduct(suite.ctx, sodaId)
+	suite.NoError(err)
+}
+
+func (suite *RepositoryTestSuite) TestGetOrdersToPrepareWithComboItemsSuccess() {
+	burgerId, _, juiceId := suite.createComboComponents()
+
+	comboRepo := repositories.NewComboRepository(suite.db)
+
+	comboId, err := comboRepo.CreateCombo(suite.ctx, dto.ComboForm{
+		Name:        "Burger Combo",
+		Description: "Burger Combo Description",
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+		Pricing: model.ComboPricingFixed,
+		Price:   2200,
+		Slots: []dto.ComboSlotForm{
+			{
+				Name:       "Lanche",
+				ProductIds: []uint{burgerId},
+			},
+			{
+				Name:     "Bebida",
+				Category: model.CategoryBeverage,
+			},
+		},
+	})
+	suite.NoError(err)
+
+	combo, err := comboRepo.GetComboById(suite.ctx, comboId)
+	suite.NoError(err)
+
+	repo := repositories.NewOrderRespository(suite.db, new(MockCustomerRemoteDataSource))
+
+	_, err = repo.CreateOrder(suite.ctx, dto.Order{
+		TotalPrice: 2200,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID:    comboId,
+				ProductPrice: 2200,
+				Quantity:     1,
+				ComboChoices: []dto.ComboChoice{
+					{
+						SlotID:    combo.Slots[0].Id,
+						ProductID: burgerId,
+					},
+					{
+						SlotID:    combo.Slots[1].Id,
+						ProductID: juiceId,
+					},
+				},
+			},
+		},
+	}, time.Now().UnixMilli())
+	suite.NoError(err)
+
+	ordersToPrepare, err := repo.GetOrdersToPrepare(suite.ctx)
+	suite.NoError(err)
+	suite.Equal(1, len(ordersToPrepare))
+	suite.Equal("Burger Combo", ordersToPrepare[0].OrderProduct[0].ProductName)
+	suite.Equal(2, len(ordersToPrepare[0].OrderProduct[0].ComboItems))
+	suite.Equal("Burger", ordersToPrepare[0].OrderProduct[0].ComboItems[0].ProductName)
+	suite.Equal("Juice", ordersToPrepare[0].OrderProduct[0].ComboItems[1].ProductName)
+}
+
+func (suite *RepositoryTestSuite) TestDeletePayingOrderWithComboItemsSuccess() {
+	burgerId, _, juiceId := suite.createComboComponents()
+
+	comboRepo := repositories.NewComboRepository(suite.db)
+
+	comboId, err := comboRepo.CreateCombo(suite.ctx, dto.ComboForm{
+		Name:        "Burger Combo",
+		Description: "Burger Combo Description",
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+		Pricing: model.ComboPricingFixed,
+		Price:   2200,
+		Slots: []dto.ComboSlotForm{
+			{
+				Name:       "Lanche",
+				ProductIds: []uint{burgerId},
+			},
+			{
+				Name:     "Bebida",
+				Category: model.CategoryBeverage,
+			},
+		},
+	})
+	suite.NoError(err)
+
+	combo, err := comboRepo.GetComboById(suite.ctx, comboId)
+	suite.NoError(err)
+
+	repo := repositories.NewOrderRespository(suite.db, new(MockCustomerRemoteDataSource))
+
+	orderResponse, err := repo.CreatePayingOrder(suite.ctx, dto.Order{
+		TotalPrice: 2200,
+		PaymentID:  "wertr",
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID:    comboId,
+				ProductPrice: 2200,
+				Quantity:     1,
+				ComboChoices: []dto.ComboChoice{
+					{
+						SlotID:    combo.Slots[0].Id,
+						ProductID: burgerId,
+					},
+					{
+						SlotID:    combo.Slots[1].Id,
+						ProductID: juiceId,
+					},
+				},
+			},
+		},
+	})
+	suite.NoError(err)
+
+	err = repo.DeleteOrder(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+
+	var comboItems []model.OrderProductComboItem
+	err = suite.db.Connection.Find(&comboItems).Error
+	suite.NoError(err)
+	suite.Empty(comboItems)
+}
+
+func (suite *RepositoryTestSuite) TestUpdateProductOfSlotComboKeepsSlotsSuccess() {
+	burgerId, sodaId, _ := suite.createComboComponents()
+
+	comboRepo := repositories.NewComboRepository(suite.db)
+
+	comboId, err := comboRepo.CreateCombo(suite.ctx, dto.ComboForm{
+		Name:        "Burger Combo",
+		Description: "Burger Combo Description",
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+		Pricing: model.ComboPricingFixed,
+		Price:   2200,
+		Slots: []dto.ComboSlotForm{
+			{
+				Name:       "Lanche",
+				ProductIds: []uint{burgerId},
+			},
+			{
+				Name:     "Bebida",
+				Category: model.CategoryBeverage,
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repo := repositories.NewProductRepository(suite.db)
+
+	hasComponents, err := repo.HasComboComponents(suite.ctx, comboId)
+	suite.NoError(err)
+	suite.True(hasComponents)
+
+	form := dto.ProductForm{
+		Id:          comboId,
+		Name:        "Burger Combo",
+		Description: "New Description",
+		Category:    model.CategoryCombo,
+		Price:       2200,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
+
+	err = repo.UpdateProduct(suite.ctx, form)
+	suite.NoError(err)
+
+	combo, err := comboRepo.GetComboById(suite.ctx, comboId)
+	suite.NoError(err)
+	suite.Equal("New Description", combo.Description)
+	suite.Equal(2, len(combo.Slots))
+
+	form.ComboProductsIds = &[]uint{sodaId}
+
+	err = repo.UpdateProduct(suite.ctx, form)
+	suite.Error(err)
+
+	var localError *responses.LocalError
+	suite.True(errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+
+	form.ComboProductsIds = nil
+	form.Category = model.CategorySnack
+
+	err = repo.UpdateProduct(suite.ctx, form)
+	suite.Error(err)
+	suite.True(errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+}
+
+func (suite *RepositoryTestSuite) TestUpdateProductOfComboWithoutComboProductsKeepsThemSuccess() {
+	burgerId, sodaId, _ := suite.createComboComponents()
+
+	repo := repositories.NewProductRepository(suite.db)
+
+	comboId, err := repo.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "Combo",
+		Description: "Combo",
+		Category:    model.CategoryCombo,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+		ComboProductsIds: &[]uint{burgerId, sodaId},
+	})
+	suite.NoError(err)
+
+	err = repo.UpdateProduct(suite.ctx, dto.ProductForm{
+		Id:          comboId,
+		Name:        "New Combo",
+		Description: "Combo",
+		Category:    model.CategoryCombo,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	var comboProducts []model.ComboProduct
+	err = suite.db.Connection.Where("product_id = ?", comboId).Find(&comboProducts).Error
+	suite.NoError(err)
+	suite.Equal(2, len(comboProducts))
+}
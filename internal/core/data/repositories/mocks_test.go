@@ -84,28 +84,52 @@ func (suite *RepositoryTestSuite) TearDownSuite() {
 }
 
 func (suite *RepositoryTestSuite) SetupTest() {
-	err := suite.db.Connection.AutoMigrate(
+	err := database.MigrateCategories(suite.db.Connection)
+	suite.NoError(err)
+
+	err = suite.db.Connection.AutoMigrate(
+		&model.Category{},
 		&model.Product{},
 		&model.ProductImage{},
 		&model.ComboProduct{},
+		&model.ComboSlot{},
+		&model.ComboSlotOption{},
 		&model.Order{},
 		&model.OrderProduct{},
+		&model.OrderProductAddOn{},
+		&model.OrderProductComboItem{},
 		&model.OrderTicketNumber{},
+		&model.Refund{},
+		&model.OrderStatusEvent{},
+		&model.OutboxEvent{},
 	)
 	suite.NoError(err)
 }
 
 func (suite *RepositoryTestSuite) TearDownTest() {
+	suite.db.Connection.Exec("DROP TABLE IF EXISTS categories CASCADE;")
 	suite.db.Connection.Exec("DROP TABLE IF EXISTS products CASCADE;")
 	suite.db.Connection.Exec("DROP TABLE IF EXISTS product_images CASCADE;")
 	suite.db.Connection.Exec("DROP TABLE IF EXISTS combo_products CASCADE;")
+	suite.db.Connection.Exec("DROP TABLE IF EXISTS combo_slots CASCADE;")
+	suite.db.Connection.Exec("DROP TABLE IF EXISTS combo_slot_options CASCADE;")
 	suite.db.Connection.Exec("DROP TABLE IF EXISTS orders CASCADE;")
 	suite.db.Connection.Exec("DROP TABLE IF EXISTS order_products CASCADE;")
+	suite.db.Connection.Exec("DROP TABLE IF EXISTS order_product_add_ons CASCADE;")
+	suite.db.Connection.Exec("DROP TABLE IF EXISTS order_product_combo_items CASCADE;")
 	suite.db.Connection.Exec("DROP TABLE IF EXISTS order_ticket_numbers CASCADE;")
+	suite.db.Connection.Exec("DROP TABLE IF EXISTS refunds CASCADE;")
+	suite.db.Connection.Exec("DROP TABLE IF EXISTS order_status_events CASCADE;")
+	suite.db.Connection.Exec("DROP TABLE IF EXISTS outbox_events CASCADE;")
 }
 
 func SetupDBMocks() (*gorm.DB, sqlmock.Sqlmock, error) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	return SetupDBMocksWithMatcher(sqlmock.QueryMatcherEqual)
+}
+
+// SetupDBMocksWithMatcher is SetupDBMocks for the tests that match the generated queries by pattern
+func SetupDBMocksWithMatcher(matcher sqlmock.QueryMatcher) (*gorm.DB, sqlmock.Sqlmock, error) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(matcher))
 
 	if err != nil {
 		return nil, nil, err
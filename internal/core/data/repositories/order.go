@@ -2,14 +2,34 @@ package repositories
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/data/remote"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
 	"github.com/thiagoluis88git/tech1-orders/pkg/database"
 	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// customerLookupConcurrency limits the calls made at the same time to the customer service
+	customerLookupConcurrency = 8
+
+	// customerLookupTimeout is how long an order list waits for the customer names. A slow
+	// customer service must not hold the kitchen panel
+	customerLookupTimeout = 2 * time.Second
+
+	// payingOrdersExpiryLock is the advisory lock that makes only one replica expire paying orders at a time
+	payingOrdersExpiryLock = 7220014
 )
 
 type OrderRespository struct {
@@ -24,15 +44,23 @@ func NewOrderRespository(db *database.Database, customerDS remote.CustomerRemote
 	}
 }
 
-func (repository *OrderRespository) CreateOrder(ctx context.Context, order dto.Order) (dto.OrderResponse, error) {
-	return repository.createOrder(ctx, order, model.OrderStatusCreated)
+func (repository *OrderRespository) CreateOrder(ctx context.Context, order dto.Order, ticketDate int64) (dto.OrderResponse, error) {
+	return repository.createOrder(ctx, order, model.OrderStatusCreated, ticketDate)
 }
 
 func (repository *OrderRespository) CreatePayingOrder(ctx context.Context, order dto.Order) (dto.OrderResponse, error) {
-	return repository.createOrder(ctx, order, model.OrderStatusPaying)
+	return repository.createOrder(ctx, order, model.OrderStatusPaying, 0)
 }
 
-func (repository *OrderRespository) createOrder(ctx context.Context, order dto.Order, status string) (dto.OrderResponse, error) {
+// createOrder saves the order and its products in one transaction. When ticketDate
+// is informed the ticket number is allocated inside the same transaction, so a
+// failed order does not consume a ticket and two replicas never get the same one
+func (repository *OrderRespository) createOrder(
+	ctx context.Context,
+	order dto.Order,
+	status string,
+	ticketDate int64,
+) (dto.OrderResponse, error) {
 	tx := repository.db.Connection.WithContext(ctx).Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -44,12 +72,30 @@ func (repository *OrderRespository) createOrder(ctx context.Context, order dto.O
 		return dto.OrderResponse{}, responses.GetDatabaseError(err)
 	}
 
+	if ticketDate != 0 {
+		ticketNumber, err := nextTicketNumber(tx, ticketDate)
+
+		if err != nil {
+			tx.Rollback()
+			return dto.OrderResponse{}, err
+		}
+
+		order.TicketNumber = ticketNumber
+	}
+
+	paymentStatus := model.PaymentStatusApproved
+
+	if status == model.OrderStatusPaying {
+		paymentStatus = model.PaymentStatusPending
+	}
+
 	orderEntity := &model.Order{
-		OrderStatus:  status,
-		TotalPrice:   order.TotalPrice,
-		CPF:          order.CPF,
-		PaymentID:    order.PaymentID,
-		TicketNumber: order.TicketNumber,
+		OrderStatus:   status,
+		PaymentStatus: paymentStatus,
+		TotalPrice:    order.TotalPrice,
+		CPF:           order.CPF,
+		PaymentID:     order.PaymentID,
+		TicketNumber:  order.TicketNumber,
 	}
 
 	err := tx.Create(orderEntity).Error
@@ -62,9 +108,32 @@ func (repository *OrderRespository) createOrder(ctx context.Context, order dto.O
 	orderProductsEntity := []*model.OrderProduct{}
 
 	for _, value := range order.OrderProduct {
+		addOns := []model.OrderProductAddOn{}
+
+		for _, addOn := range value.AddOns {
+			addOns = append(addOns, model.OrderProductAddOn{
+				ProductID:    addOn.ProductID,
+				ProductPrice: addOn.ProductPrice,
+			})
+		}
+
+		comboItems := []model.OrderProductComboItem{}
+
+		for _, choice := range value.ComboChoices {
+			comboItems = append(comboItems, model.OrderProductComboItem{
+				ComboSlotID: choice.SlotID,
+				ProductID:   choice.ProductID,
+			})
+		}
+
 		orderProductsEntity = append(orderProductsEntity, &model.OrderProduct{
-			ProductID: value.ProductID,
-			OrderID:   orderEntity.ID,
+			ProductID:    value.ProductID,
+			ProductPrice: value.ProductPrice,
+			Quantity:     value.Quantity,
+			Observations: value.Observations,
+			AddOns:       addOns,
+			ComboItems:   comboItems,
+			OrderID:      orderEntity.ID,
 		})
 	}
 
@@ -75,6 +144,27 @@ func (repository *OrderRespository) createOrder(ctx context.Context, order dto.O
 		return dto.OrderResponse{}, responses.GetDatabaseError(err)
 	}
 
+	err = createStatusEvent(ctx, tx, orderEntity.ID, "", status)
+
+	if err != nil {
+		tx.Rollback()
+		return dto.OrderResponse{}, err
+	}
+
+	err = createOutboxEvent(tx, events.DomainEvent{
+		Type:        events.OrderCreated,
+		OrderID:     orderEntity.ID,
+		OrderStatus: status,
+		TotalPrice:  orderEntity.TotalPrice,
+		PaymentID:   orderEntity.PaymentID,
+		CPF:         orderEntity.CPF,
+	})
+
+	if err != nil {
+		tx.Rollback()
+		return dto.OrderResponse{}, err
+	}
+
 	err = tx.Commit().Error
 
 	if err != nil {
@@ -89,6 +179,9 @@ func (repository *OrderRespository) createOrder(ctx context.Context, order dto.O
 	}, nil
 }
 
+// DeleteOrder removes an order still waiting for payment. The order is only deleted
+// while it is in Em pagamento, so a payment confirmed at the same time wins the race
+// and the delete gets a conflict error
 func (repository *OrderRespository) DeleteOrder(ctx context.Context, orderID uint) error {
 	tx := repository.db.Connection.WithContext(ctx).Begin()
 	defer func() {
@@ -101,20 +194,212 @@ func (repository *OrderRespository) DeleteOrder(ctx context.Context, orderID uin
 		return responses.GetDatabaseError(err)
 	}
 
-	err := tx.Where("order_id = ?", orderID).Delete(&model.OrderProduct{}).Error
+	err := deletePayingOrder(tx, orderID)
+
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit().Error
 
 	if err != nil {
 		tx.Rollback()
 		return responses.GetDatabaseError(err)
 	}
 
-	err = tx.Delete(&model.Order{}, orderID).Error
+	return nil
+}
+
+// DeleteExpiredPayingOrders removes the orders created before expiredBefore and still waiting
+// for payment. Only one replica expires orders at a time, the others get no order back.
+// Orders locked by a payment confirmation in progress are skipped
+func (repository *OrderRespository) DeleteExpiredPayingOrders(ctx context.Context, expiredBefore time.Time) ([]uint, error) {
+	tx := repository.db.Connection.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return []uint{}, responses.GetDatabaseError(err)
+	}
+
+	var locked bool
+	err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", payingOrdersExpiryLock).Scan(&locked).Error
+
+	if err != nil {
+		tx.Rollback()
+		return []uint{}, responses.GetDatabaseError(err)
+	}
+
+	if !locked {
+		tx.Rollback()
+		return []uint{}, nil
+	}
+
+	orderIDs := []uint{}
+	err = tx.
+		Model(&model.Order{}).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("order_status = ? AND created_at < ?", model.OrderStatusPaying, expiredBefore).
+		Order("created_at").
+		Pluck("id", &orderIDs).
+		Error
+
+	if err != nil {
+		tx.Rollback()
+		return []uint{}, responses.GetDatabaseError(err)
+	}
+
+	for _, orderID := range orderIDs {
+		err = deletePayingOrder(tx, orderID)
+
+		if err != nil {
+			tx.Rollback()
+			return []uint{}, err
+		}
+	}
+
+	err = tx.Commit().Error
 
 	if err != nil {
 		tx.Rollback()
+		return []uint{}, responses.GetDatabaseError(err)
+	}
+
+	return orderIDs, nil
+}
+
+// deletePayingOrder must be called within a transaction, so the products of the order
+// are kept when the order already left Em pagamento
+func deletePayingOrder(tx *gorm.DB, orderID uint) error {
+	var orderEntity model.Order
+
+	err := tx.Where("id = ?", orderID).First(&orderEntity).Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	err = tx.
+		Where("order_product_id IN (?)", tx.Model(&model.OrderProduct{}).Select("id").Where("order_id = ?", orderID)).
+		Delete(&model.OrderProductAddOn{}).
+		Error
+
+	if err != nil {
 		return responses.GetDatabaseError(err)
 	}
 
+	err = tx.
+		Where("order_product_id IN (?)", tx.Model(&model.OrderProduct{}).Select("id").Where("order_id = ?", orderID)).
+		Delete(&model.OrderProductComboItem{}).
+		Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	err = tx.Where("order_id = ?", orderID).Delete(&model.OrderProduct{}).Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	result := tx.Where("id = ? AND order_status = ?", orderID, model.OrderStatusPaying).Delete(&model.Order{})
+
+	if result.Error != nil {
+		return responses.GetDatabaseError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return &responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: fmt.Sprintf("The order is no longer in %v status", model.OrderStatusPaying),
+		}
+	}
+
+	return createOutboxEvent(tx, events.DomainEvent{
+		Type:           events.OrderDeleted,
+		OrderID:        orderID,
+		PreviousStatus: model.OrderStatusPaying,
+		PaymentID:      orderEntity.PaymentID,
+	})
+}
+
+// FinishOrderWithPayment moves a paying order to Criado. The ticket number is only
+// allocated here, so abandoned payments never consume a ticket of the day.
+// If the order already left Em pagamento, e.g. a replayed payment notification
+// raced with the first one, nothing is changed, the ticket is given back and a
+// conflict error is returned
+func (repository *OrderRespository) FinishOrderWithPayment(
+	ctx context.Context,
+	orderID uint,
+	paymentID string,
+	ticketDate int64,
+) error {
+	tx := repository.db.Connection.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	ticketNumber, err := nextTicketNumber(tx, ticketDate)
+
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result := tx.
+		Model(&model.Order{}).
+		Where("id = ? AND order_status = ?", orderID, model.OrderStatusPaying).
+		Updates(map[string]interface{}{
+			"payment_id":     paymentID,
+			"payment_status": model.PaymentStatusApproved,
+			"order_status":   model.OrderStatusCreated,
+			"ticket_number":  ticketNumber,
+		})
+
+	if result.Error != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return &responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: fmt.Sprintf("The order is no longer in %v status", model.OrderStatusPaying),
+		}
+	}
+
+	err = createStatusEvent(ctx, tx, orderID, model.OrderStatusPaying, model.OrderStatusCreated)
+
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = createOutboxEvent(tx, events.DomainEvent{
+		Type:           events.OrderStatusChanged,
+		OrderID:        orderID,
+		PreviousStatus: model.OrderStatusPaying,
+		OrderStatus:    model.OrderStatusCreated,
+		PaymentID:      paymentID,
+	})
+
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	err = tx.Commit().Error
 
 	if err != nil {
@@ -125,12 +410,19 @@ func (repository *OrderRespository) DeleteOrder(ctx context.Context, orderID uin
 	return nil
 }
 
-func (repository *OrderRespository) FinishOrderWithPayment(ctx context.Context, orderID uint, paymentID string) error {
+func (repository *OrderRespository) GetOrderById(ctx context.Context, orderId uint) (dto.OrderResponse, error) {
+	return repository.getOrder(ctx, "id = ?", orderId)
+}
+
+func (repository *OrderRespository) GetOrderByPaymentId(ctx context.Context, paymentID string) (dto.OrderResponse, error) {
+	return repository.getOrder(ctx, "payment_id = ?", paymentID)
+}
+
+func (repository *OrderRespository) UpdatePaymentStatus(ctx context.Context, orderID uint, paymentStatus string) error {
 	err := repository.db.Connection.WithContext(ctx).
 		Model(&model.Order{}).
 		Where("id = ?", orderID).
-		Update("payment_id", paymentID).
-		Update("order_status", model.OrderStatusCreated).
+		Update("payment_status", paymentStatus).
 		Error
 
 	if err != nil {
@@ -140,13 +432,96 @@ func (repository *OrderRespository) FinishOrderWithPayment(ctx context.Context,
 	return nil
 }
 
-func (repository *OrderRespository) GetOrderById(ctx context.Context, orderId uint) (dto.OrderResponse, error) {
+// RefundPayment records a pending refund for a payment approved after its order stopped
+// waiting for it, e.g. the order was canceled while the customer was paying. The refund is
+// created once per order, so a replayed notification does not refund the payment twice.
+// Expired paying orders are soft deleted, so the order is looked up with Unscoped
+func (repository *OrderRespository) RefundPayment(ctx context.Context, paymentID string) error {
+	tx := repository.db.Connection.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	var orderEntity model.Order
+
+	err := tx.Unscoped().Where("payment_id = ?", paymentID).First(&orderEntity).Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	err = tx.
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "order_id"}}, DoNothing: true}).
+		Create(&model.Refund{
+			OrderID:   orderEntity.ID,
+			PaymentID: paymentID,
+			Amount:    orderEntity.TotalPrice,
+			Status:    model.RefundStatusPending,
+		}).
+		Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	err = tx.
+		Unscoped().
+		Model(&orderEntity).
+		Update("payment_status", model.PaymentStatusApproved).
+		Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	err = tx.Commit().Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+func (repository *OrderRespository) UpdateOrderPriority(ctx context.Context, orderID uint, priority int) error {
+	result := repository.db.Connection.WithContext(ctx).
+		Model(&model.Order{}).
+		Where("id = ?", orderID).
+		Update("priority", priority)
+
+	if result.Error != nil {
+		return responses.GetDatabaseError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Order not found",
+		}
+	}
+
+	return nil
+}
+
+func (repository *OrderRespository) getOrder(ctx context.Context, query string, args ...interface{}) (dto.OrderResponse, error) {
 	var orderEntity model.Order
 	err := repository.
 		db.Connection.WithContext(ctx).
 		Model(&model.Order{}).
 		Preload("OrderProduct.Product").
-		Where("id = ?", orderId).
+		Preload("OrderProduct.AddOns.Product").
+		Preload("OrderProduct.ComboItems.Product").
+		Where(query, args...).
 		Find(&orderEntity).
 		Limit(1).
 		Error
@@ -162,16 +537,6 @@ func (repository *OrderRespository) GetOrderById(ctx context.Context, orderId ui
 		}
 	}
 
-	orderProduct := []dto.OrderProductResponse{}
-
-	for _, value := range orderEntity.OrderProduct {
-		orderProduct = append(orderProduct, dto.OrderProductResponse{
-			ProductID:   value.ProductID,
-			ProductName: value.Product.Name,
-			Description: value.Product.Description,
-		})
-	}
-
 	var customerName *string
 
 	if orderEntity.CPF != nil {
@@ -187,13 +552,21 @@ func (repository *OrderRespository) GetOrderById(ctx context.Context, orderId ui
 	return dto.OrderResponse{
 		OrderId:        orderEntity.ID,
 		OrderDate:      orderEntity.CreatedAt,
+		UpdatedAt:      orderEntity.UpdatedAt,
 		PreparingAt:    orderEntity.PreparingAt,
 		DoneAt:         orderEntity.DoneAt,
 		DeliveredAt:    orderEntity.DeliveredAt,
 		NotDeliveredAt: orderEntity.NotDeliveredAt,
+		CanceledAt:     orderEntity.CanceledAt,
+		CancelReason:   orderEntity.CancelReason,
+		CancelNote:     orderEntity.CancelNote,
 		TicketNumber:   orderEntity.TicketNumber,
+		Priority:       orderEntity.Priority,
 		OrderStatus:    orderEntity.OrderStatus,
-		OrderProduct:   orderProduct,
+		PaymentStatus:  orderEntity.PaymentStatus,
+		TotalPrice:     orderEntity.TotalPrice,
+		ItemsQuantity:  itemsQuantity(orderEntity.OrderProduct),
+		OrderProduct:   buildOrderProducts(orderEntity.OrderProduct),
 		CustomerName:   customerName,
 	}, nil
 }
@@ -204,6 +577,8 @@ func (repository *OrderRespository) GetOrdersToPrepare(ctx context.Context) ([]d
 		db.Connection.WithContext(ctx).
 		Model(&model.Order{}).
 		Preload("OrderProduct.Product").
+		Preload("OrderProduct.AddOns.Product").
+		Preload("OrderProduct.ComboItems.Product").
 		Where("order_status = ?", model.OrderStatusCreated).
 		Order("created_at").
 		Find(&orderEntity).
@@ -213,7 +588,7 @@ func (repository *OrderRespository) GetOrdersToPrepare(ctx context.Context) ([]d
 		return []dto.OrderResponse{}, responses.GetDatabaseError(err)
 	}
 
-	return repository.buildOrdersList(orderEntity), nil
+	return repository.buildOrdersList(ctx, orderEntity), nil
 }
 
 func (repository *OrderRespository) GetOrdersToFollow(ctx context.Context) ([]dto.OrderResponse, error) {
@@ -222,6 +597,8 @@ func (repository *OrderRespository) GetOrdersToFollow(ctx context.Context) ([]dt
 		db.Connection.WithContext(ctx).
 		Model(&model.Order{}).
 		Preload("OrderProduct.Product").
+		Preload("OrderProduct.AddOns.Product").
+		Preload("OrderProduct.ComboItems.Product").
 		Where("order_status in (?, ?,?)",
 			model.OrderStatusCreated,
 			model.OrderStatusPreparing,
@@ -235,7 +612,7 @@ func (repository *OrderRespository) GetOrdersToFollow(ctx context.Context) ([]dt
 		return []dto.OrderResponse{}, responses.GetDatabaseError(err)
 	}
 
-	return repository.buildOrdersList(orderEntity), nil
+	return repository.buildOrdersList(ctx, orderEntity), nil
 }
 
 func (repository *OrderRespository) GetOrdersWaitingPayment(ctx context.Context) ([]dto.OrderResponse, error) {
@@ -244,6 +621,8 @@ func (repository *OrderRespository) GetOrdersWaitingPayment(ctx context.Context)
 		db.Connection.WithContext(ctx).
 		Model(&model.Order{}).
 		Preload("OrderProduct.Product").
+		Preload("OrderProduct.AddOns.Product").
+		Preload("OrderProduct.ComboItems.Product").
 		Where("order_status = ?", model.OrderStatusPaying).
 		Order("created_at").
 		Find(&orderEntity).
@@ -253,43 +632,151 @@ func (repository *OrderRespository) GetOrdersWaitingPayment(ctx context.Context)
 		return []dto.OrderResponse{}, responses.GetDatabaseError(err)
 	}
 
-	return repository.buildOrdersList(orderEntity), nil
+	return repository.buildOrdersList(ctx, orderEntity), nil
 }
 
-func (repository *OrderRespository) buildOrdersList(orderEntity []model.Order) []dto.OrderResponse {
-	orders := []dto.OrderResponse{}
+// orderSearchColumns are the columns the order search can be sorted by. Each one has
+// an index together with the id, the tie-break of the keyset pagination
+var orderSearchColumns = map[string]string{
+	dto.OrderSortOrderDate:  "orders.created_at",
+	dto.OrderSortUpdatedAt:  "orders.updated_at",
+	dto.OrderSortTotalPrice: "orders.total_price",
+}
 
-	for _, value := range orderEntity {
-		orderProduct := []dto.OrderProductResponse{}
+// SearchOrders returns a page of orders after the `after` cursor. The page is read with the
+// keyset of the sort column and the id, so deep pages cost the same as the first one
+func (repository *OrderRespository) SearchOrders(
+	ctx context.Context,
+	search dto.OrderSearch,
+	after *dto.OrderCursor,
+) (dto.OrderSearchPage, error) {
+	column := orderSearchColumns[search.SortBy]
+	direction := "ASC"
+	comparison := ">"
+
+	if search.Descending {
+		direction = "DESC"
+		comparison = "<"
+	}
 
-		for _, value := range value.OrderProduct {
-			orderProduct = append(orderProduct, dto.OrderProductResponse{
-				ProductID:   value.ProductID,
-				ProductName: value.Product.Name,
-				Description: value.Product.Description,
-			})
+	query := repository.
+		db.Connection.WithContext(ctx).
+		Model(&model.Order{}).
+		Preload("OrderProduct.Product").
+		Preload("OrderProduct.AddOns.Product").
+		Preload("OrderProduct.ComboItems.Product")
+
+	if len(search.Statuses) > 0 {
+		query = query.Where("orders.order_status IN ?", search.Statuses)
+	}
+
+	if search.From != nil {
+		query = query.Where("orders.created_at >= ?", *search.From)
+	}
+
+	if search.To != nil {
+		query = query.Where("orders.created_at < ?", *search.To)
+	}
+
+	if search.CPF != nil {
+		query = query.Where("orders.cpf = ?", *search.CPF)
+	}
+
+	if search.TicketNumber != nil {
+		query = query.Where("orders.ticket_number = ?", *search.TicketNumber)
+	}
+
+	if search.ProductID != nil {
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM order_products WHERE order_products.order_id = orders.id "+
+				"AND order_products.product_id = ? AND order_products.deleted_at IS NULL)",
+			*search.ProductID,
+		)
+	}
+
+	if after != nil {
+		query = query.Where(
+			fmt.Sprintf("(%v, orders.id) %v (?, ?)", column, comparison),
+			orderCursorValue(*after),
+			after.ID,
+		)
+	}
+
+	var orderEntity []model.Order
+	err := query.
+		Order(fmt.Sprintf("%v %v, orders.id %v", column, direction, direction)).
+		Limit(search.Limit + 1).
+		Find(&orderEntity).
+		Error
+
+	if err != nil {
+		return dto.OrderSearchPage{}, responses.GetDatabaseError(err)
+	}
+
+	var next *dto.OrderCursor
+
+	if len(orderEntity) > search.Limit {
+		orderEntity = orderEntity[:search.Limit]
+		last := orderEntity[len(orderEntity)-1]
+
+		next = &dto.OrderCursor{
+			SortBy:     search.SortBy,
+			Descending: search.Descending,
+			ID:         last.ID,
+			OrderDate:  last.CreatedAt,
+			UpdatedAt:  last.UpdatedAt,
+			TotalPrice: last.TotalPrice,
 		}
+	}
+
+	return dto.OrderSearchPage{
+		Orders: repository.buildOrdersList(ctx, orderEntity),
+		Next:   next,
+	}, nil
+}
 
+func orderCursorValue(cursor dto.OrderCursor) interface{} {
+	switch cursor.SortBy {
+	case dto.OrderSortUpdatedAt:
+		return cursor.UpdatedAt
+	case dto.OrderSortTotalPrice:
+		return cursor.TotalPrice
+	default:
+		return cursor.OrderDate
+	}
+}
+
+func (repository *OrderRespository) buildOrdersList(ctx context.Context, orderEntity []model.Order) []dto.OrderResponse {
+	orders := []dto.OrderResponse{}
+	customerNames := repository.getCustomerNames(ctx, orderEntity)
+
+	for _, value := range orderEntity {
 		var customerName *string
 
 		if value.CPF != nil {
-			customerResponse, err := repository.customerDS.GetCustomerByCPF(context.Background(), *value.CPF)
-
-			if err == nil {
-				customerName = &customerResponse.Name
+			if name, ok := customerNames[*value.CPF]; ok {
+				customerName = &name
 			}
 		}
 
 		orders = append(orders, dto.OrderResponse{
 			OrderId:        value.ID,
 			OrderDate:      value.CreatedAt,
+			UpdatedAt:      value.UpdatedAt,
 			PreparingAt:    value.PreparingAt,
 			DoneAt:         value.DoneAt,
 			DeliveredAt:    value.DeliveredAt,
 			NotDeliveredAt: value.NotDeliveredAt,
+			CanceledAt:     value.CanceledAt,
+			CancelReason:   value.CancelReason,
+			CancelNote:     value.CancelNote,
 			TicketNumber:   value.TicketNumber,
+			Priority:       value.Priority,
 			OrderStatus:    value.OrderStatus,
-			OrderProduct:   orderProduct,
+			PaymentStatus:  value.PaymentStatus,
+			TotalPrice:     value.TotalPrice,
+			ItemsQuantity:  itemsQuantity(value.OrderProduct),
+			OrderProduct:   buildOrderProducts(value.OrderProduct),
 			CustomerName:   customerName,
 		})
 	}
@@ -297,109 +784,357 @@ func (repository *OrderRespository) buildOrdersList(orderEntity []model.Order) [
 	return orders
 }
 
-func (repository *OrderRespository) UpdateToPreparing(ctx context.Context, orderId uint) error {
-	err := repository.db.Connection.WithContext(ctx).
-		Model(&model.Order{}).
-		Where("id = ?", orderId).
-		Update("order_status", model.OrderStatusPreparing).
-		Update("preparing_at", time.Now()).
-		Error
+// getCustomerNames looks up each CPF of the orders once, a few at a time. The lookups share
+// the caller deadline, capped by customerLookupTimeout, and the customers not found in time
+// are left out, so their orders are listed without the name
+func (repository *OrderRespository) getCustomerNames(ctx context.Context, orderEntity []model.Order) map[string]string {
+	cpfs := map[string]bool{}
 
-	if err != nil {
-		return responses.GetDatabaseError(err)
+	for _, value := range orderEntity {
+		if value.CPF != nil {
+			cpfs[*value.CPF] = true
+		}
 	}
 
-	return nil
+	names := map[string]string{}
+
+	if len(cpfs) == 0 {
+		return names
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, customerLookupTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	group := errgroup.Group{}
+	group.SetLimit(customerLookupConcurrency)
+
+	for cpf := range cpfs {
+		group.Go(func() error {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			customer, err := repository.customerDS.GetCustomerByCPF(ctx, cpf)
+
+			if err != nil {
+				return nil
+			}
+
+			mu.Lock()
+			names[cpf] = customer.Name
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	group.Wait()
+
+	return names
 }
 
-func (repository *OrderRespository) UpdateToDone(ctx context.Context, orderId uint) error {
-	err := repository.db.Connection.WithContext(ctx).
+func buildOrderProducts(orderProducts []model.OrderProduct) []dto.OrderProductResponse {
+	response := []dto.OrderProductResponse{}
+
+	for _, value := range orderProducts {
+		addOns := []dto.OrderProductAddOnResponse{}
+
+		for _, addOn := range value.AddOns {
+			addOns = append(addOns, dto.OrderProductAddOnResponse{
+				ProductID:    addOn.ProductID,
+				ProductName:  addOn.Product.Name,
+				ProductPrice: addOn.ProductPrice,
+			})
+		}
+
+		comboItems := []dto.OrderProductComboItem{}
+
+		for _, comboItem := range value.ComboItems {
+			comboItems = append(comboItems, dto.OrderProductComboItem{
+				ProductID:   comboItem.ProductID,
+				ProductName: comboItem.Product.Name,
+			})
+		}
+
+		response = append(response, dto.OrderProductResponse{
+			ProductID:    value.ProductID,
+			ProductName:  value.Product.Name,
+			Description:  value.Product.Description,
+			ProductPrice: value.ProductPrice,
+			Quantity:     value.Quantity,
+			Observations: value.Observations,
+			AddOns:       addOns,
+			ComboItems:   comboItems,
+		})
+	}
+
+	return response
+}
+
+func itemsQuantity(orderProducts []model.OrderProduct) int {
+	quantity := 0
+
+	for _, value := range orderProducts {
+		quantity += value.Quantity
+	}
+
+	return quantity
+}
+
+// UpdateOrderStatus only changes the order if it is still in transition.From, so two clients
+// validating the same transition at the same time can not both apply it
+func (repository *OrderRespository) UpdateOrderStatus(
+	ctx context.Context,
+	orderId uint,
+	transition statemachine.OrderTransition,
+) error {
+	values := map[string]interface{}{
+		"order_status": transition.To,
+	}
+
+	if transition.TimestampColumn != "" {
+		values[transition.TimestampColumn] = time.Now()
+	}
+
+	tx := repository.db.Connection.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	result := tx.
 		Model(&model.Order{}).
-		Where("id = ?", orderId).
-		Update("order_status", model.OrderStatusDone).
-		Update("done_at", time.Now()).
-		Error
+		Where("id = ? AND order_status = ?", orderId, transition.From).
+		Updates(values)
+
+	if result.Error != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return statusChangedError(transition)
+	}
+
+	err := createStatusEvent(ctx, tx, orderId, transition.From, transition.To)
 
 	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = createOutboxEvent(tx, events.DomainEvent{
+		Type:           events.OrderStatusChanged,
+		OrderID:        orderId,
+		PreviousStatus: transition.From,
+		OrderStatus:    transition.To,
+	})
+
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit().Error
+
+	if err != nil {
+		tx.Rollback()
 		return responses.GetDatabaseError(err)
 	}
 
 	return nil
 }
 
-func (repository *OrderRespository) UpdateToDelivered(ctx context.Context, orderId uint) error {
-	err := repository.db.Connection.WithContext(ctx).
-		Model(&model.Order{}).
-		Where("id = ?", orderId).
-		Update("order_status", model.OrderStatusDelivered).
-		Update("delivered_at", time.Now()).
-		Error
+// CancelOrder cancels the order and, when it was already paid, creates a pending
+// refund in the same transaction. Orders still in Em pagamento may have a PaymentID
+// from the QR Code, but no money was taken yet
+func (repository *OrderRespository) CancelOrder(
+	ctx context.Context,
+	orderId uint,
+	transition statemachine.OrderTransition,
+	cancel dto.OrderCancelForm,
+) error {
+	tx := repository.db.Connection.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	var orderEntity model.Order
+
+	err := tx.Where("id = ?", orderId).First(&orderEntity).Error
 
 	if err != nil {
+		tx.Rollback()
 		return responses.GetDatabaseError(err)
 	}
 
-	return nil
-}
+	result := tx.
+		Model(&orderEntity).
+		Where("order_status = ?", transition.From).
+		Updates(map[string]interface{}{
+			"order_status":             transition.To,
+			transition.TimestampColumn: time.Now(),
+			"cancel_reason":            cancel.Reason,
+			"cancel_note":              cancel.Note,
+		})
 
-func (repository *OrderRespository) UpdateToNotDelivered(ctx context.Context, orderId uint) error {
-	err := repository.db.Connection.WithContext(ctx).
-		Model(&model.Order{}).
-		Where("id = ?", orderId).
-		Update("order_status", model.OrderStatusNotDelivered).
-		Update("not_delivered_at", time.Now()).
-		Error
+	if result.Error != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return statusChangedError(transition)
+	}
+
+	err = createStatusEvent(ctx, tx, orderEntity.ID, transition.From, transition.To)
+
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = createOutboxEvent(tx, events.DomainEvent{
+		Type:           events.OrderCanceled,
+		OrderID:        orderEntity.ID,
+		PreviousStatus: transition.From,
+		OrderStatus:    transition.To,
+		TotalPrice:     orderEntity.TotalPrice,
+		PaymentID:      orderEntity.PaymentID,
+	})
+
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if orderEntity.PaymentID != "" && transition.From != model.OrderStatusPaying {
+		err = tx.Create(&model.Refund{
+			OrderID:   orderEntity.ID,
+			PaymentID: orderEntity.PaymentID,
+			Amount:    orderEntity.TotalPrice,
+			Status:    model.RefundStatusPending,
+		}).Error
+
+		if err != nil {
+			tx.Rollback()
+			return responses.GetDatabaseError(err)
+		}
+	}
+
+	err = tx.Commit().Error
 
 	if err != nil {
+		tx.Rollback()
 		return responses.GetDatabaseError(err)
 	}
 
 	return nil
 }
 
-func (repository *OrderRespository) GetNextTicketNumber(ctx context.Context, date int64) int {
-	var orderTicketNumber model.OrderTicketNumber
-	err := repository.db.Connection.WithContext(ctx).
-		Model(&model.OrderTicketNumber{}).
-		Where("date = ?", date).
-		Find(&orderTicketNumber).
-		Limit(1).
+func (repository *OrderRespository) GetOrderHistory(ctx context.Context, orderId uint) ([]dto.OrderStatusEventResponse, error) {
+	var events []model.OrderStatusEvent
+	err := repository.
+		db.Connection.WithContext(ctx).
+		Where("order_id = ?", orderId).
+		Order("id").
+		Find(&events).
 		Error
 
-	if err != nil || orderTicketNumber.Date == 0 {
-		return repository.createNewTicketForDate(ctx, date)
+	if err != nil {
+		return []dto.OrderStatusEventResponse{}, responses.GetDatabaseError(err)
 	}
 
-	newTicketNumber := orderTicketNumber.TicketNumber + 1
+	if len(events) == 0 {
+		return []dto.OrderStatusEventResponse{}, &responses.LocalError{
+			Message: "Order history not found",
+			Code:    responses.NOT_FOUND_ERROR,
+		}
+	}
 
-	return repository.updateTicketForDate(ctx, date, newTicketNumber)
+	history := []dto.OrderStatusEventResponse{}
+
+	for _, event := range events {
+		history = append(history, dto.OrderStatusEventResponse{
+			PreviousStatus: event.PreviousStatus,
+			NewStatus:      event.NewStatus,
+			Actor:          event.Actor,
+			RequestID:      event.RequestID,
+			CreatedAt:      event.CreatedAt,
+		})
+	}
+
+	return history, nil
 }
 
-func (repository *OrderRespository) createNewTicketForDate(ctx context.Context, date int64) int {
-	orderTicketNumber := model.OrderTicketNumber{
-		Date:         date,
-		TicketNumber: 1,
+func statusChangedError(transition statemachine.OrderTransition) error {
+	return &responses.LocalError{
+		Code:    responses.DATABASE_CONFLICT_ERROR,
+		Message: fmt.Sprintf("The order is no longer in %v status", transition.From),
 	}
+}
 
-	errCreate := repository.db.Connection.WithContext(ctx).Create(&orderTicketNumber).Error
+// createStatusEvent appends the status change to the order history inside the transaction
+// that changed the status. The actor is the role set by the server with statemachine.WithRole,
+// from the staff token of the request or RoleSystem, never a value sent by the client.
+// The request ID comes from the chi RequestID middleware
+func createStatusEvent(ctx context.Context, tx *gorm.DB, orderId uint, previousStatus, newStatus string) error {
+	err := tx.Create(&model.OrderStatusEvent{
+		OrderID:        orderId,
+		PreviousStatus: previousStatus,
+		NewStatus:      newStatus,
+		Actor:          statemachine.RoleFromContext(ctx),
+		RequestID:      middleware.GetReqID(ctx),
+	}).Error
 
-	if errCreate != nil {
-		return 999
+	if err != nil {
+		return responses.GetDatabaseError(err)
 	}
 
-	return 1
+	return nil
 }
 
-func (repository *OrderRespository) updateTicketForDate(ctx context.Context, date int64, newTicketNumber int) int {
-	err := repository.db.Connection.WithContext(ctx).
-		Model(&model.OrderTicketNumber{}).
-		Where("date = ?", date).
-		Update("ticket_number", newTicketNumber).
-		Error
+// nextTicketNumber increments the ticket of the day with a single upsert. The row stays
+// locked until the caller's transaction ends, which serializes concurrent orders
+// across every replica. It must only run inside the transaction that saves the order,
+// so a failed order never takes a ticket
+func nextTicketNumber(tx *gorm.DB, date int64) (int, error) {
+	var ticketNumber int
+
+	err := tx.Raw(`
+		INSERT INTO order_ticket_numbers (date, ticket_number) VALUES (?, 1)
+		ON CONFLICT (date) DO UPDATE SET ticket_number = order_ticket_numbers.ticket_number + 1
+		RETURNING ticket_number`,
+		date,
+	).Scan(&ticketNumber).Error
 
 	if err != nil {
-		return 999
+		return 0, &responses.LocalError{
+			Code:    responses.TICKET_ALLOCATION_ERROR,
+			Message: fmt.Sprintf("could not allocate a ticket number: %v", responses.GetDatabaseError(err).Message),
+		}
+	}
+
+	if ticketNumber == 0 {
+		return 0, &responses.LocalError{
+			Code:    responses.TICKET_ALLOCATION_ERROR,
+			Message: "could not allocate a ticket number: no ticket returned",
+		}
 	}
 
-	return newTicketNumber
+	return ticketNumber, nil
 }
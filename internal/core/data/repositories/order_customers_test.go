@@ -0,0 +1,100 @@
+package repositories_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/repositories"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+)
+
+func (suite *RepositoryTestSuite) createOrdersForCustomers(customerDS *MockCustomerRemoteDataSource, cpfs []*string) {
+	repoProduct := repositories.NewProductRepository(suite.db)
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+
+	for i, cpf := range cpfs {
+		_, err := repo.CreateOrder(suite.ctx, dto.Order{
+			TotalPrice: 2990,
+			PaymentID:  fmt.Sprintf("wertr-%d", i),
+			CPF:        cpf,
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID: newId,
+				},
+			},
+		}, time.Now().UnixMilli())
+		suite.NoError(err)
+	}
+}
+
+func (suite *RepositoryTestSuite) TestGetOrdersToPrepareLooksUpEachCustomerOnceSuccess() {
+	firstCPF := "12345678910"
+	secondCPF := "10987654321"
+
+	customerDS := new(MockCustomerRemoteDataSource)
+	customerDS.On("GetCustomerByCPF", mock.Anything, firstCPF).Return(model.Customer{Name: "First"}, nil).Once()
+	customerDS.On("GetCustomerByCPF", mock.Anything, secondCPF).Return(model.Customer{Name: "Second"}, nil).Once()
+
+	suite.createOrdersForCustomers(customerDS, []*string{&firstCPF, &secondCPF, nil, &firstCPF, &firstCPF})
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+
+	ordersToPrepare, err := repo.GetOrdersToPrepare(suite.ctx)
+	suite.NoError(err)
+	suite.Equal(5, len(ordersToPrepare))
+
+	suite.Equal("First", *ordersToPrepare[0].CustomerName)
+	suite.Equal("Second", *ordersToPrepare[1].CustomerName)
+	suite.Nil(ordersToPrepare[2].CustomerName)
+	suite.Equal("First", *ordersToPrepare[3].CustomerName)
+	suite.Equal("First", *ordersToPrepare[4].CustomerName)
+
+	customerDS.AssertNumberOfCalls(suite.T(), "GetCustomerByCPF", 2)
+}
+
+func (suite *RepositoryTestSuite) TestGetOrdersToPrepareWithSlowCustomerServiceSuccess() {
+	cpf := "12345678910"
+
+	customerDS := new(MockCustomerRemoteDataSource)
+
+	// the customer service only answers when the lookup gives up
+	customerDS.On("GetCustomerByCPF", mock.Anything, cpf).
+		Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Return(model.Customer{}, context.DeadlineExceeded)
+
+	suite.createOrdersForCustomers(customerDS, []*string{&cpf})
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+
+	ctx, cancel := context.WithTimeout(suite.ctx, 500*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	ordersToPrepare, err := repo.GetOrdersToPrepare(ctx)
+	suite.NoError(err)
+	suite.Equal(1, len(ordersToPrepare))
+	suite.Nil(ordersToPrepare[0].CustomerName)
+
+	// the caller deadline is shorter than the lookup timeout, so it is the one respected
+	suite.Less(time.Since(start), time.Second)
+}
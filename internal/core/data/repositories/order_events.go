@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/pkg/database"
+	"gorm.io/gorm"
+)
+
+const (
+	OrderEventsChannel = "order_events"
+
+	orderEventsSequence    = "order_events_id_seq"
+	listenReconnectBackoff = 2 * time.Second
+
+	// orderEventsLock makes the replicas number the events one at a time. The lock is held
+	// until the notification is sent, so the IDs reach the listeners in order
+	orderEventsLock = 7220015
+)
+
+// OrderEventNotifier sends the order events to every replica through Postgres NOTIFY.
+// Each replica listens on a dedicated pgx connection and delivers the events,
+// including the ones it sent, to its in-memory bus. The event ID comes from a
+// sequence, so a client can resume with Last-Event-ID on any replica. A value of the
+// sequence that was never notified is a gap, and the bus asks the client to reload
+type OrderEventNotifier struct {
+	db  *database.Database
+	dsn string
+	bus *events.OrderEventBus
+}
+
+func NewOrderEventNotifier(db *database.Database, dsn string, bus *events.OrderEventBus) *OrderEventNotifier {
+	return &OrderEventNotifier{
+		db:  db,
+		dsn: dsn,
+		bus: bus,
+	}
+}
+
+// Publish never fails the use case. If the notification can not be sent the event is
+// lost and the panels only see the change when they reload the orders
+func (notifier *OrderEventNotifier) Publish(event events.OrderEvent) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	payload, err := json.Marshal(event)
+
+	if err == nil {
+		err = notifier.db.Connection.Transaction(func(tx *gorm.DB) error {
+			err := tx.Exec("SELECT pg_advisory_xact_lock(?)", orderEventsLock).Error
+
+			if err != nil {
+				return err
+			}
+
+			return tx.Exec(
+				"SELECT pg_notify(?, jsonb_set(?::jsonb, '{id}', to_jsonb(nextval('"+orderEventsSequence+"')))::text)",
+				OrderEventsChannel,
+				string(payload),
+			).Error
+		})
+	}
+
+	if err != nil {
+		log.Print("notify order event", map[string]interface{}{
+			"error":   err.Error(),
+			"orderId": event.OrderID,
+		})
+	}
+}
+
+// Start listens on the order events channel before returning, so no event sent after
+// it is missed. Lost connections are reopened until ctx is done
+func (notifier *OrderEventNotifier) Start(ctx context.Context) error {
+	err := notifier.db.Connection.WithContext(ctx).
+		Exec("CREATE SEQUENCE IF NOT EXISTS " + orderEventsSequence).
+		Error
+
+	if err != nil {
+		return err
+	}
+
+	conn, err := notifier.listen(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	go notifier.receive(ctx, conn)
+
+	return nil
+}
+
+func (notifier *OrderEventNotifier) listen(ctx context.Context) (*pgx.Conn, error) {
+	conn, err := pgx.Connect(ctx, notifier.dsn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = conn.Exec(ctx, "LISTEN "+OrderEventsChannel)
+
+	if err != nil {
+		conn.Close(context.Background())
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (notifier *OrderEventNotifier) receive(ctx context.Context, conn *pgx.Conn) {
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+
+		if err != nil {
+			conn.Close(context.Background())
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			log.Print("wait order event notification", map[string]interface{}{
+				"error": err.Error(),
+			})
+
+			conn = notifier.reconnect(ctx)
+
+			if conn == nil {
+				return
+			}
+
+			continue
+		}
+
+		var event events.OrderEvent
+		err = json.Unmarshal([]byte(notification.Payload), &event)
+
+		if err != nil {
+			log.Print("decode order event notification", map[string]interface{}{
+				"error": err.Error(),
+			})
+			continue
+		}
+
+		notifier.bus.Publish(event)
+	}
+}
+
+// reconnect returns nil only when ctx is done. Events sent while the replica
+// was not listening never reach its clients
+func (notifier *OrderEventNotifier) reconnect(ctx context.Context) *pgx.Conn {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(listenReconnectBackoff):
+		}
+
+		conn, err := notifier.listen(ctx)
+
+		if err == nil {
+			return conn
+		}
+
+		log.Print("listen order events", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
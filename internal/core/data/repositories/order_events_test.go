@@ -0,0 +1,132 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/repositories"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/pkg/database"
+)
+
+const lockOrderEventsQuery = "SELECT pg_advisory_xact_lock(?)"
+
+const notifyOrderEventQuery = "SELECT pg_notify(?, jsonb_set(?::jsonb, '{id}', to_jsonb(nextval('order_events_id_seq')))::text)"
+
+func TestOrderEventNotifier(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got order event notified to the order events channel", func(t *testing.T) {
+		t.Parallel()
+
+		gormDB, mock, err := SetupDBMocks()
+		assert.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(lockOrderEventsQuery).
+			WithArgs(7220015).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(notifyOrderEventQuery).
+			WithArgs(repositories.OrderEventsChannel, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		bus := events.NewOrderEventBus(10)
+		subscription, _, _ := bus.Subscribe(0)
+		sut := repositories.NewOrderEventNotifier(&database.Database{Connection: gormDB}, "", bus)
+
+		sut.Publish(events.OrderEvent{Type: events.OrderCreated, OrderID: 1})
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		// the event only reaches the bus when it comes back from LISTEN
+		assert.Empty(t, subscription.Events())
+	})
+
+	t.Run("got no panic when the order event can not be notified", func(t *testing.T) {
+		t.Parallel()
+
+		gormDB, mock, err := SetupDBMocks()
+		assert.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(lockOrderEventsQuery).
+			WithArgs(7220015).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(notifyOrderEventQuery).
+			WithArgs(repositories.OrderEventsChannel, sqlmock.AnyArg()).
+			WillReturnError(errors.New("connection refused"))
+		mock.ExpectRollback()
+
+		sut := repositories.NewOrderEventNotifier(&database.Database{Connection: gormDB}, "", events.NewOrderEventBus(10))
+
+		sut.Publish(events.OrderEvent{Type: events.OrderCreated, OrderID: 1})
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func (suite *RepositoryTestSuite) TestOrderEventNotifierFanOutSuccess() {
+	ctx, cancel := context.WithCancel(suite.ctx)
+	defer cancel()
+
+	// two replicas of the service, each one with its own bus
+	firstBus := events.NewOrderEventBus(10)
+	firstReplica := repositories.NewOrderEventNotifier(suite.db, suite.pgConnectionString, firstBus)
+	suite.NoError(firstReplica.Start(ctx))
+
+	secondBus := events.NewOrderEventBus(10)
+	secondReplica := repositories.NewOrderEventNotifier(suite.db, suite.pgConnectionString, secondBus)
+	suite.NoError(secondReplica.Start(ctx))
+
+	firstSubscription, _, _ := firstBus.Subscribe(0)
+	secondSubscription, _, _ := secondBus.Subscribe(0)
+
+	firstReplica.Publish(events.OrderEvent{
+		Type:           events.OrderStatusChanged,
+		OrderID:        12,
+		PreviousStatus: "Criado",
+		OrderStatus:    "Preparando",
+	})
+	secondReplica.Publish(events.OrderEvent{
+		Type:           events.OrderCanceled,
+		OrderID:        13,
+		PreviousStatus: "Criado",
+		OrderStatus:    "Cancelado",
+	})
+
+	replicaEvents := [][]events.OrderEvent{}
+
+	for _, subscription := range []*events.Subscription{firstSubscription, secondSubscription} {
+		received := []events.OrderEvent{}
+
+		for len(received) < 2 {
+			select {
+			case event := <-subscription.Events():
+				received = append(received, event)
+			case <-time.After(5 * time.Second):
+				suite.FailNow("order event not received")
+			}
+		}
+
+		suite.Equal(uint(12), received[0].OrderID)
+		suite.Equal("Preparando", received[0].OrderStatus)
+		suite.Equal(uint(13), received[1].OrderID)
+		suite.Equal(events.OrderCanceled, received[1].Type)
+		suite.Less(received[0].ID, received[1].ID)
+
+		replicaEvents = append(replicaEvents, received)
+	}
+
+	// the same IDs on every replica, so clients can resume on any of them
+	suite.Equal(replicaEvents[0], replicaEvents[1])
+
+	_, missed, complete := secondBus.Subscribe(replicaEvents[0][0].ID)
+	suite.True(complete)
+	suite.Equal(1, len(missed))
+	suite.Equal(uint(13), missed[0].OrderID)
+}
@@ -0,0 +1,141 @@
+package repositories_test
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/repositories"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
+)
+
+func (suite *RepositoryTestSuite) TestSearchOrdersSuccess() {
+	repoProduct := repositories.NewProductRepository(suite.db)
+
+	productIds := []uint{}
+
+	for _, name := range []string{"Burger", "Soda"} {
+		newId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+			Name:        name,
+			Description: "Description " + name,
+			Category:    model.CategorySnack,
+			Price:       2990,
+			Images: []dto.ProducImage{
+				{
+					ImageUrl: "NewImageUrl",
+				},
+			},
+		})
+		suite.NoError(err)
+
+		productIds = append(productIds, newId)
+	}
+
+	customerDS := new(MockCustomerRemoteDataSource)
+	customerDS.On("GetCustomerByCPF", mock.Anything, "12345678910").Return(MockCustomer(), nil)
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+
+	cpf := "12345678910"
+	orders := []dto.Order{
+		{TotalPrice: 10, PaymentID: "first", CPF: &cpf, OrderProduct: []dto.OrderProduct{{ProductID: productIds[0]}}},
+		{TotalPrice: 30, PaymentID: "second", OrderProduct: []dto.OrderProduct{{ProductID: productIds[1]}}},
+		{TotalPrice: 20, PaymentID: "third", OrderProduct: []dto.OrderProduct{{ProductID: productIds[0]}}},
+	}
+
+	orderIds := []uint{}
+	ticketNumbers := []int{}
+
+	for _, order := range orders {
+		response, err := repo.CreateOrder(suite.ctx, order, time.Now().UnixMilli())
+		suite.NoError(err)
+
+		orderIds = append(orderIds, response.OrderId)
+		ticketNumbers = append(ticketNumbers, response.TicketNumber)
+	}
+
+	transition, err := statemachine.NewOrderStateMachine().Transition(model.OrderStatusCreated, model.OrderStatusPreparing, statemachine.RoleManager)
+	suite.NoError(err)
+
+	err = repo.UpdateOrderStatus(suite.ctx, orderIds[1], transition)
+	suite.NoError(err)
+
+	// orders with the burger, oldest first, one per page
+	search := dto.OrderSearch{
+		ProductID: &productIds[0],
+		SortBy:    dto.OrderSortOrderDate,
+		Limit:     1,
+	}
+
+	page, err := repo.SearchOrders(suite.ctx, search, nil)
+	suite.NoError(err)
+	suite.Equal(1, len(page.Orders))
+	suite.Equal(orderIds[0], page.Orders[0].OrderId)
+	suite.Equal("CustomerName", *page.Orders[0].CustomerName)
+	suite.NotNil(page.Next)
+
+	page, err = repo.SearchOrders(suite.ctx, search, page.Next)
+	suite.NoError(err)
+	suite.Equal(1, len(page.Orders))
+	suite.Equal(orderIds[2], page.Orders[0].OrderId)
+	suite.Nil(page.Next)
+
+	// most expensive first
+	page, err = repo.SearchOrders(suite.ctx, dto.OrderSearch{
+		SortBy:     dto.OrderSortTotalPrice,
+		Descending: true,
+		Limit:      2,
+	}, nil)
+	suite.NoError(err)
+	suite.Equal(2, len(page.Orders))
+	suite.Equal(orderIds[1], page.Orders[0].OrderId)
+	suite.Equal(orderIds[2], page.Orders[1].OrderId)
+
+	page, err = repo.SearchOrders(suite.ctx, dto.OrderSearch{
+		SortBy:     dto.OrderSortTotalPrice,
+		Descending: true,
+		Limit:      2,
+	}, page.Next)
+	suite.NoError(err)
+	suite.Equal(1, len(page.Orders))
+	suite.Equal(orderIds[0], page.Orders[0].OrderId)
+
+	page, err = repo.SearchOrders(suite.ctx, dto.OrderSearch{
+		Statuses: []string{model.OrderStatusPreparing, model.OrderStatusDone},
+		SortBy:   dto.OrderSortOrderDate,
+		Limit:    10,
+	}, nil)
+	suite.NoError(err)
+	suite.Equal(1, len(page.Orders))
+	suite.Equal(orderIds[1], page.Orders[0].OrderId)
+
+	page, err = repo.SearchOrders(suite.ctx, dto.OrderSearch{
+		CPF:    &cpf,
+		SortBy: dto.OrderSortOrderDate,
+		Limit:  10,
+	}, nil)
+	suite.NoError(err)
+	suite.Equal(1, len(page.Orders))
+	suite.Equal(orderIds[0], page.Orders[0].OrderId)
+
+	page, err = repo.SearchOrders(suite.ctx, dto.OrderSearch{
+		TicketNumber: &ticketNumbers[2],
+		SortBy:       dto.OrderSortOrderDate,
+		Limit:        10,
+	}, nil)
+	suite.NoError(err)
+	suite.Equal(1, len(page.Orders))
+	suite.Equal(orderIds[2], page.Orders[0].OrderId)
+
+	future := time.Now().Add(time.Hour)
+
+	page, err = repo.SearchOrders(suite.ctx, dto.OrderSearch{
+		From:   &future,
+		SortBy: dto.OrderSortOrderDate,
+		Limit:  10,
+	}, nil)
+	suite.NoError(err)
+	suite.Empty(page.Orders)
+	suite.Nil(page.Next)
+}
@@ -1,13 +1,20 @@
 package repositories_test
 
 import (
+	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/data/repositories"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
 )
 
 func TestOrderRepository(t *testing.T) {
@@ -25,7 +32,7 @@ func (suite *RepositoryTestSuite) TestCreateOrderWithSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -51,7 +58,7 @@ func (suite *RepositoryTestSuite) TestCreateOrderWithSuccess() {
 			},
 		},
 	}
-	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder)
+	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder, time.Now().UnixMilli())
 	suite.NoError(err)
 	suite.Equal(uint(1), orderResponse.OrderId)
 }
@@ -67,7 +74,7 @@ func (suite *RepositoryTestSuite) TestCreatePayingOrderWithSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -109,7 +116,7 @@ func (suite *RepositoryTestSuite) TestDeleteOrderWithSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -144,6 +151,122 @@ func (suite *RepositoryTestSuite) TestDeleteOrderWithSuccess() {
 	suite.NoError(err)
 }
 
+func (suite *RepositoryTestSuite) TestDeleteOrderAlreadyPaidWithConflict() {
+	// ensure that the postgres database is empty
+	var products []model.Product
+	result := suite.db.Connection.Find(&products)
+	suite.NoError(result.Error)
+	suite.Empty(products)
+
+	repoProduct := repositories.NewProductRepository(suite.db)
+	newProduct := dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
+	suite.Equal(uint(1), newId)
+
+	customerDS := new(MockCustomerRemoteDataSource)
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+	newOrder := dto.Order{
+		TotalPrice: 5090,
+		PaymentID:  "wertr",
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: uint(1),
+			},
+		},
+	}
+
+	orderResponse, err := repo.CreatePayingOrder(suite.ctx, newOrder)
+	suite.NoError(err)
+
+	err = repo.FinishOrderWithPayment(suite.ctx, orderResponse.OrderId, "12345", time.Now().UnixMilli())
+	suite.NoError(err)
+
+	err = repo.DeleteOrder(suite.ctx, orderResponse.OrderId)
+	suite.Error(err)
+
+	var localError *responses.LocalError
+	suite.True(errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+
+	order, err := repo.GetOrderById(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Equal(model.OrderStatusCreated, order.OrderStatus)
+	suite.Len(order.OrderProduct, 1)
+}
+
+func (suite *RepositoryTestSuite) TestDeleteExpiredPayingOrdersSuccess() {
+	// ensure that the postgres database is empty
+	var products []model.Product
+	result := suite.db.Connection.Find(&products)
+	suite.NoError(result.Error)
+	suite.Empty(products)
+
+	repoProduct := repositories.NewProductRepository(suite.db)
+	newProduct := dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
+	suite.Equal(uint(1), newId)
+
+	customerDS := new(MockCustomerRemoteDataSource)
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+	newOrder := dto.Order{
+		TotalPrice: 5090,
+		PaymentID:  "wertr",
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: uint(1),
+			},
+		},
+	}
+
+	expiredOrder, err := repo.CreatePayingOrder(suite.ctx, newOrder)
+	suite.NoError(err)
+
+	newOrder.PaymentID = "12345"
+
+	paidOrder, err := repo.CreatePayingOrder(suite.ctx, newOrder)
+	suite.NoError(err)
+
+	err = repo.FinishOrderWithPayment(suite.ctx, paidOrder.OrderId, "12345", time.Now().UnixMilli())
+	suite.NoError(err)
+
+	orderIDs, err := repo.DeleteExpiredPayingOrders(suite.ctx, time.Now().Add(time.Minute))
+	suite.NoError(err)
+	suite.Equal([]uint{expiredOrder.OrderId}, orderIDs)
+
+	_, err = repo.GetOrderById(suite.ctx, expiredOrder.OrderId)
+	suite.Error(err)
+
+	order, err := repo.GetOrderById(suite.ctx, paidOrder.OrderId)
+	suite.NoError(err)
+	suite.Equal(model.OrderStatusCreated, order.OrderStatus)
+}
+
 func (suite *RepositoryTestSuite) TestFinishOrderWithPaymentSuccess() {
 	// ensure that the postgres database is empty
 	var products []model.Product
@@ -155,7 +278,7 @@ func (suite *RepositoryTestSuite) TestFinishOrderWithPaymentSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -172,9 +295,8 @@ func (suite *RepositoryTestSuite) TestFinishOrderWithPaymentSuccess() {
 
 	repo := repositories.NewOrderRespository(suite.db, customerDS)
 	newOrder := dto.Order{
-		TotalPrice:   5090,
-		PaymentID:    "wertr",
-		TicketNumber: 12,
+		TotalPrice: 5090,
+		PaymentID:  "wertr",
 		OrderProduct: []dto.OrderProduct{
 			{
 				ProductID: uint(1),
@@ -182,12 +304,29 @@ func (suite *RepositoryTestSuite) TestFinishOrderWithPaymentSuccess() {
 		},
 	}
 
-	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder)
+	orderResponse, err := repo.CreatePayingOrder(suite.ctx, newOrder)
 	suite.NoError(err)
 	suite.Equal(uint(1), orderResponse.OrderId)
+	suite.Equal(0, orderResponse.TicketNumber)
 
-	err = repo.FinishOrderWithPayment(suite.ctx, orderResponse.OrderId, "12345")
+	err = repo.FinishOrderWithPayment(suite.ctx, orderResponse.OrderId, "12345", time.Now().UnixMilli())
 	suite.NoError(err)
+
+	order, err := repo.GetOrderById(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Equal(model.OrderStatusCreated, order.OrderStatus)
+	suite.Equal(1, order.TicketNumber)
+
+	err = repo.FinishOrderWithPayment(suite.ctx, orderResponse.OrderId, "12345", time.Now().UnixMilli())
+	suite.Error(err)
+
+	var localError *responses.LocalError
+	suite.True(errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+
+	order, err = repo.GetOrderById(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Equal(1, order.TicketNumber)
 }
 
 func (suite *RepositoryTestSuite) TestGetOrderByIDSuccess() {
@@ -201,7 +340,7 @@ func (suite *RepositoryTestSuite) TestGetOrderByIDSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -233,13 +372,13 @@ func (suite *RepositoryTestSuite) TestGetOrderByIDSuccess() {
 
 	customerDS.On("GetCustomerByCPF", suite.ctx, cpf).Return(MockCustomer(), nil)
 
-	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder)
+	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder, time.Now().UnixMilli())
 	suite.NoError(err)
 	suite.Equal(uint(1), orderResponse.OrderId)
 
 	orderResult, err := repo.GetOrderById(suite.ctx, orderResponse.OrderId)
 	suite.NoError(err)
-	suite.Equal(12, orderResult.TicketNumber)
+	suite.Equal(1, orderResult.TicketNumber)
 	suite.Equal("CustomerName", *orderResult.CustomerName)
 }
 
@@ -254,7 +393,7 @@ func (suite *RepositoryTestSuite) TestGetOrderByIDWithoutCustomerSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -281,13 +420,13 @@ func (suite *RepositoryTestSuite) TestGetOrderByIDWithoutCustomerSuccess() {
 		},
 	}
 
-	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder)
+	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder, time.Now().UnixMilli())
 	suite.NoError(err)
 	suite.Equal(uint(1), orderResponse.OrderId)
 
 	orderResult, err := repo.GetOrderById(suite.ctx, orderResponse.OrderId)
 	suite.NoError(err)
-	suite.Equal(12, orderResult.TicketNumber)
+	suite.Equal(1, orderResult.TicketNumber)
 	suite.Nil(orderResult.CustomerName)
 }
 
@@ -302,7 +441,7 @@ func (suite *RepositoryTestSuite) TestGetOrderByIDWithUnknownIDError() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -329,7 +468,7 @@ func (suite *RepositoryTestSuite) TestGetOrderByIDWithUnknownIDError() {
 		},
 	}
 
-	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder)
+	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder, time.Now().UnixMilli())
 	suite.NoError(err)
 	suite.Equal(uint(1), orderResponse.OrderId)
 
@@ -349,7 +488,7 @@ func (suite *RepositoryTestSuite) TestGetOrdersToPrepareSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -380,9 +519,9 @@ func (suite *RepositoryTestSuite) TestGetOrdersToPrepareSuccess() {
 		},
 	}
 
-	customerDS.On("GetCustomerByCPF", suite.ctx, cpf).Return(MockCustomer(), nil)
+	customerDS.On("GetCustomerByCPF", mock.Anything, cpf).Return(MockCustomer(), nil)
 
-	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder)
+	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder, time.Now().UnixMilli())
 	suite.NoError(err)
 	suite.Equal(uint(1), orderResponse.OrderId)
 
@@ -403,7 +542,7 @@ func (suite *RepositoryTestSuite) TestGetOrdersToPrepareWithoutCustomerSuccess()
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -430,7 +569,7 @@ func (suite *RepositoryTestSuite) TestGetOrdersToPrepareWithoutCustomerSuccess()
 		},
 	}
 
-	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder)
+	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder, time.Now().UnixMilli())
 	suite.NoError(err)
 	suite.Equal(uint(1), orderResponse.OrderId)
 
@@ -451,7 +590,7 @@ func (suite *RepositoryTestSuite) TestGetOrdersToFollowSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -478,11 +617,14 @@ func (suite *RepositoryTestSuite) TestGetOrdersToFollowSuccess() {
 		},
 	}
 
-	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder)
+	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder, time.Now().UnixMilli())
 	suite.NoError(err)
 	suite.Equal(uint(1), orderResponse.OrderId)
 
-	err = repo.UpdateToPreparing(suite.ctx, orderResponse.OrderId)
+	transition, err := statemachine.NewOrderStateMachine().Transition(model.OrderStatusCreated, model.OrderStatusPreparing, statemachine.RoleManager)
+	suite.NoError(err)
+
+	err = repo.UpdateOrderStatus(suite.ctx, orderResponse.OrderId, transition)
 	suite.NoError(err)
 
 	ordersToFollow, err := repo.GetOrdersToFollow(suite.ctx)
@@ -501,7 +643,7 @@ func (suite *RepositoryTestSuite) TestGetOrdersWaitingPaymentSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -548,7 +690,7 @@ func (suite *RepositoryTestSuite) TestUpdateToDoneSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -579,7 +721,10 @@ func (suite *RepositoryTestSuite) TestUpdateToDoneSuccess() {
 	suite.NoError(err)
 	suite.Equal(uint(1), orderResponse.OrderId)
 
-	err = repo.UpdateToDone(suite.ctx, orderResponse.OrderId)
+	transition, err := statemachine.NewOrderStateMachine().Transition(model.OrderStatusPreparing, model.OrderStatusDone, statemachine.RoleManager)
+	suite.NoError(err)
+
+	err = repo.UpdateOrderStatus(suite.ctx, orderResponse.OrderId, transition)
 	suite.NoError(err)
 }
 
@@ -594,7 +739,7 @@ func (suite *RepositoryTestSuite) TestUpdateToDeliveredSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -625,7 +770,10 @@ func (suite *RepositoryTestSuite) TestUpdateToDeliveredSuccess() {
 	suite.NoError(err)
 	suite.Equal(uint(1), orderResponse.OrderId)
 
-	err = repo.UpdateToDelivered(suite.ctx, orderResponse.OrderId)
+	transition, err := statemachine.NewOrderStateMachine().Transition(model.OrderStatusDone, model.OrderStatusDelivered, statemachine.RoleManager)
+	suite.NoError(err)
+
+	err = repo.UpdateOrderStatus(suite.ctx, orderResponse.OrderId, transition)
 	suite.NoError(err)
 }
 
@@ -640,7 +788,7 @@ func (suite *RepositoryTestSuite) TestUpdateToNotDeliveredSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -671,46 +819,678 @@ func (suite *RepositoryTestSuite) TestUpdateToNotDeliveredSuccess() {
 	suite.NoError(err)
 	suite.Equal(uint(1), orderResponse.OrderId)
 
-	err = repo.UpdateToNotDelivered(suite.ctx, orderResponse.OrderId)
+	transition, err := statemachine.NewOrderStateMachine().Transition(model.OrderStatusDone, model.OrderStatusNotDelivered, statemachine.RoleManager)
+	suite.NoError(err)
+
+	err = repo.UpdateOrderStatus(suite.ctx, orderResponse.OrderId, transition)
 	suite.NoError(err)
 }
 
-func (suite *RepositoryTestSuite) TestGetNextTicketNumberSuccess() {
-	// ensure that the postgres database is empty
-	var tickets []model.OrderTicketNumber
-	result := suite.db.Connection.Find(&tickets)
-	suite.NoError(result.Error)
-	suite.Empty(tickets)
+func (suite *RepositoryTestSuite) TestCreateOrderAllocatesTicketNumberSuccess() {
+	repoProduct := repositories.NewProductRepository(suite.db)
+	newProduct := dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
 
 	customerDS := new(MockCustomerRemoteDataSource)
 
 	repo := repositories.NewOrderRespository(suite.db, customerDS)
+	newOrder := dto.Order{
+		TotalPrice: 5090,
+		PaymentID:  "wertr",
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: newId,
+			},
+		},
+	}
 
 	date := time.Now().UnixMilli()
 
-	ticket := repo.GetNextTicketNumber(suite.ctx, date)
+	firstOrder, err := repo.CreateOrder(suite.ctx, newOrder, date)
+	suite.NoError(err)
+	suite.Equal(1, firstOrder.TicketNumber)
+
+	newOrder.PaymentID = "wertr-2"
 
-	suite.Equal(1, ticket)
+	secondOrder, err := repo.CreateOrder(suite.ctx, newOrder, date)
+	suite.NoError(err)
+	suite.Equal(2, secondOrder.TicketNumber)
 }
 
-func (suite *RepositoryTestSuite) TestGetNextTicketNumberWithPlus1Success() {
-	// ensure that the postgres database is empty
-	var tickets []model.OrderTicketNumber
-	result := suite.db.Connection.Find(&tickets)
-	suite.NoError(result.Error)
-	suite.Empty(tickets)
+func (suite *RepositoryTestSuite) TestCreateOrderConcurrentlyWithoutDuplicateTicketsSuccess() {
+	repoProduct := repositories.NewProductRepository(suite.db)
+	newProduct := dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
 
 	customerDS := new(MockCustomerRemoteDataSource)
 
 	repo := repositories.NewOrderRespository(suite.db, customerDS)
+	newOrder := dto.Order{
+		TotalPrice: 2990,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: newId,
+			},
+		},
+	}
 
 	date := time.Now().UnixMilli()
+	total := 20
+
+	var wg sync.WaitGroup
+	tickets := make(chan int, total)
+
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			order, err := repo.CreateOrder(suite.ctx, newOrder, date)
+			suite.NoError(err)
+
+			tickets <- order.TicketNumber
+		}()
+	}
+
+	wg.Wait()
+	close(tickets)
+
+	seen := map[int]bool{}
+
+	for ticket := range tickets {
+		suite.False(seen[ticket])
+		seen[ticket] = true
+	}
+
+	suite.Equal(total, len(seen))
+}
+
+func (suite *RepositoryTestSuite) TestGetOrderByIDKeepsProductPriceSnapshotSuccess() {
+	repoProduct := repositories.NewProductRepository(suite.db)
+	newProduct := dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
+
+	customerDS := new(MockCustomerRemoteDataSource)
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+	newOrder := dto.Order{
+		TotalPrice: 2990,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID:    newId,
+				ProductPrice: 2990,
+			},
+		},
+	}
+
+	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder, time.Now().UnixMilli())
+	suite.NoError(err)
+
+	newProduct.Id = newId
+	newProduct.Price = 3990
+	err = repoProduct.UpdateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
+
+	orderResult, err := repo.GetOrderById(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Equal(float64(2990), orderResult.OrderProduct[0].ProductPrice)
+}
+
+func (suite *RepositoryTestSuite) TestGetOrdersToPrepareWithQuantityAndAddOnsSuccess() {
+	repoProduct := repositories.NewProductRepository(suite.db)
+
+	productId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "Burger",
+		Description: "Burger Description",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	addOnId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "Bacon",
+		Description: "Bacon Description",
+		Category:    model.CategoryToppings,
+		Price:       450,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	customerDS := new(MockCustomerRemoteDataSource)
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+	newOrder := dto.Order{
+		TotalPrice: 10320,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID:    productId,
+				ProductPrice: 2990,
+				Quantity:     3,
+				Observations: "No onions",
+				AddOns: []dto.OrderProductAddOn{
+					{
+						ProductID:    addOnId,
+						ProductPrice: 450,
+					},
+				},
+			},
+		},
+	}
+
+	_, err = repo.CreateOrder(suite.ctx, newOrder, time.Now().UnixMilli())
+	suite.NoError(err)
+
+	ordersToPrepare, err := repo.GetOrdersToPrepare(suite.ctx)
+	suite.NoError(err)
+	suite.Equal(1, len(ordersToPrepare))
+	suite.Equal(3, ordersToPrepare[0].ItemsQuantity)
+	suite.Equal(float64(10320), ordersToPrepare[0].TotalPrice)
+	suite.Equal("No onions", ordersToPrepare[0].OrderProduct[0].Observations)
+	suite.Equal("Bacon", ordersToPrepare[0].OrderProduct[0].AddOns[0].ProductName)
+}
 
-	ticket := repo.GetNextTicketNumber(suite.ctx, date)
+func (suite *RepositoryTestSuite) TestGetOrderByPaymentIdAndUpdatePaymentStatusSuccess() {
+	repoProduct := repositories.NewProductRepository(suite.db)
+	newProduct := dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
 
-	suite.Equal(1, ticket)
+	newId, err := repoProduct.CreateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
 
-	newTicket := repo.GetNextTicketNumber(suite.ctx, date)
+	customerDS := new(MockCustomerRemoteDataSource)
 
-	suite.Equal(2, newTicket)
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+	newOrder := dto.Order{
+		TotalPrice: 2990,
+		PaymentID:  "gateway-123",
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: newId,
+			},
+		},
+	}
+
+	orderResponse, err := repo.CreatePayingOrder(suite.ctx, newOrder)
+	suite.NoError(err)
+
+	order, err := repo.GetOrderByPaymentId(suite.ctx, "gateway-123")
+	suite.NoError(err)
+	suite.Equal(orderResponse.OrderId, order.OrderId)
+	suite.Equal(model.PaymentStatusPending, order.PaymentStatus)
+
+	err = repo.UpdatePaymentStatus(suite.ctx, order.OrderId, model.PaymentStatusFailed)
+	suite.NoError(err)
+
+	order, err = repo.GetOrderByPaymentId(suite.ctx, "gateway-123")
+	suite.NoError(err)
+	suite.Equal(model.OrderStatusPaying, order.OrderStatus)
+	suite.Equal(model.PaymentStatusFailed, order.PaymentStatus)
+
+	_, err = repo.GetOrderByPaymentId(suite.ctx, "unknown")
+	suite.Error(err)
+}
+
+func (suite *RepositoryTestSuite) TestCancelOrderWithRefundSuccess() {
+	repoProduct := repositories.NewProductRepository(suite.db)
+	newProduct := dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
+
+	customerDS := new(MockCustomerRemoteDataSource)
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+	newOrder := dto.Order{
+		TotalPrice: 2990,
+		PaymentID:  "wertr",
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: newId,
+			},
+		},
+	}
+
+	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder, time.Now().UnixMilli())
+	suite.NoError(err)
+
+	transition, err := statemachine.NewOrderStateMachine().Transition(model.OrderStatusCreated, model.OrderStatusCanceled, statemachine.RoleManager)
+	suite.NoError(err)
+
+	err = repo.CancelOrder(suite.ctx, orderResponse.OrderId, transition, dto.OrderCancelForm{
+		Reason: model.CancelReasonCustomerRequest,
+		Note:   "Customer gave up",
+	})
+	suite.NoError(err)
+
+	order, err := repo.GetOrderById(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Equal(model.OrderStatusCanceled, order.OrderStatus)
+	suite.Equal(model.CancelReasonCustomerRequest, order.CancelReason)
+	suite.NotNil(order.CanceledAt)
+
+	var refunds []model.Refund
+	result := suite.db.Connection.Find(&refunds)
+	suite.NoError(result.Error)
+	suite.Equal(1, len(refunds))
+	suite.Equal("wertr", refunds[0].PaymentID)
+	suite.Equal(float64(2990), refunds[0].Amount)
+	suite.Equal(model.RefundStatusPending, refunds[0].Status)
+
+	ordersToPrepare, err := repo.GetOrdersToPrepare(suite.ctx)
+	suite.NoError(err)
+	suite.Empty(ordersToPrepare)
+
+	ordersToFollow, err := repo.GetOrdersToFollow(suite.ctx)
+	suite.NoError(err)
+	suite.Empty(ordersToFollow)
+}
+
+func (suite *RepositoryTestSuite) TestCancelPayingOrderWithoutRefundSuccess() {
+	repoProduct := repositories.NewProductRepository(suite.db)
+	newProduct := dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
+
+	customerDS := new(MockCustomerRemoteDataSource)
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+	newOrder := dto.Order{
+		TotalPrice: 2990,
+		PaymentID:  "qrcode-txid",
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: newId,
+			},
+		},
+	}
+
+	orderResponse, err := repo.CreatePayingOrder(suite.ctx, newOrder)
+	suite.NoError(err)
+
+	transition, err := statemachine.NewOrderStateMachine().Transition(model.OrderStatusPaying, model.OrderStatusCanceled, statemachine.RoleManager)
+	suite.NoError(err)
+
+	err = repo.CancelOrder(suite.ctx, orderResponse.OrderId, transition, dto.OrderCancelForm{
+		Reason: model.CancelReasonPaymentIssue,
+	})
+	suite.NoError(err)
+
+	var refunds []model.Refund
+	result := suite.db.Connection.Find(&refunds)
+	suite.NoError(result.Error)
+	suite.Empty(refunds)
+}
+
+func (suite *RepositoryTestSuite) TestRefundPaymentOfCanceledPayingOrderSuccess() {
+	repoProduct := repositories.NewProductRepository(suite.db)
+	newProduct := dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
+
+	customerDS := new(MockCustomerRemoteDataSource)
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+	newOrder := dto.Order{
+		TotalPrice: 2990,
+		PaymentID:  "qrcode-txid",
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: newId,
+			},
+		},
+	}
+
+	orderResponse, err := repo.CreatePayingOrder(suite.ctx, newOrder)
+	suite.NoError(err)
+
+	transition, err := statemachine.NewOrderStateMachine().Transition(model.OrderStatusPaying, model.OrderStatusCanceled, statemachine.RoleManager)
+	suite.NoError(err)
+
+	err = repo.CancelOrder(suite.ctx, orderResponse.OrderId, transition, dto.OrderCancelForm{
+		Reason: model.CancelReasonCustomerRequest,
+	})
+	suite.NoError(err)
+
+	err = repo.RefundPayment(suite.ctx, "qrcode-txid")
+	suite.NoError(err)
+
+	// a replayed notification does not refund the payment twice
+	err = repo.RefundPayment(suite.ctx, "qrcode-txid")
+	suite.NoError(err)
+
+	var refunds []model.Refund
+	result := suite.db.Connection.Find(&refunds)
+	suite.NoError(result.Error)
+	suite.Equal(1, len(refunds))
+	suite.Equal(orderResponse.OrderId, refunds[0].OrderID)
+	suite.Equal(float64(2990), refunds[0].Amount)
+	suite.Equal(model.RefundStatusPending, refunds[0].Status)
+
+	order, err := repo.GetOrderById(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Equal(model.PaymentStatusApproved, order.PaymentStatus)
+}
+
+func (suite *RepositoryTestSuite) TestRefundPaymentOfExpiredOrderSuccess() {
+	repoProduct := repositories.NewProductRepository(suite.db)
+	newProduct := dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
+
+	customerDS := new(MockCustomerRemoteDataSource)
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+	newOrder := dto.Order{
+		TotalPrice: 2990,
+		PaymentID:  "qrcode-txid",
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: newId,
+			},
+		},
+	}
+
+	orderResponse, err := repo.CreatePayingOrder(suite.ctx, newOrder)
+	suite.NoError(err)
+
+	orderIDs, err := repo.DeleteExpiredPayingOrders(suite.ctx, time.Now().Add(time.Minute))
+	suite.NoError(err)
+	suite.Equal([]uint{orderResponse.OrderId}, orderIDs)
+
+	_, err = repo.GetOrderByPaymentId(suite.ctx, "qrcode-txid")
+	suite.Error(err)
+
+	err = repo.RefundPayment(suite.ctx, "qrcode-txid")
+	suite.NoError(err)
+
+	var refunds []model.Refund
+	result := suite.db.Connection.Find(&refunds)
+	suite.NoError(result.Error)
+	suite.Equal(1, len(refunds))
+	suite.Equal(orderResponse.OrderId, refunds[0].OrderID)
+
+	err = repo.RefundPayment(suite.ctx, "unknown")
+	suite.Error(err)
+
+	var localError *responses.LocalError
+	suite.True(errors.As(err, &localError))
+	suite.Equal(responses.NOT_FOUND_ERROR, localError.Code)
+}
+
+func (suite *RepositoryTestSuite) TestCreateOrderWithDuplicatedPaymentIdConflict() {
+	repoProduct := repositories.NewProductRepository(suite.db)
+	newProduct := dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
+
+	customerDS := new(MockCustomerRemoteDataSource)
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+	newOrder := dto.Order{
+		TotalPrice: 2990,
+		PaymentID:  "qrcode-txid",
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: newId,
+			},
+		},
+	}
+
+	_, err = repo.CreatePayingOrder(suite.ctx, newOrder)
+	suite.NoError(err)
+
+	_, err = repo.CreateOrder(suite.ctx, newOrder, time.Now().UnixMilli())
+	suite.Error(err)
+
+	var localError *responses.LocalError
+	suite.True(errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+}
+
+func (suite *RepositoryTestSuite) TestGetOrderHistorySuccess() {
+	repoProduct := repositories.NewProductRepository(suite.db)
+	newProduct := dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
+
+	customerDS := new(MockCustomerRemoteDataSource)
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+	newOrder := dto.Order{
+		TotalPrice: 2990,
+		PaymentID:  "wertr",
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: newId,
+			},
+		},
+	}
+
+	ctx := context.WithValue(suite.ctx, chiMiddleware.RequestIDKey, "host/abc-000001")
+	ctx = statemachine.WithRole(ctx, statemachine.RoleWaiter)
+
+	orderResponse, err := repo.CreateOrder(ctx, newOrder, time.Now().UnixMilli())
+	suite.NoError(err)
+
+	sm := statemachine.NewOrderStateMachine()
+
+	transition, err := sm.Transition(model.OrderStatusCreated, model.OrderStatusPreparing, statemachine.RoleManager)
+	suite.NoError(err)
+
+	ctx = context.WithValue(suite.ctx, chiMiddleware.RequestIDKey, "host/abc-000002")
+	ctx = statemachine.WithRole(ctx, statemachine.RoleKitchen)
+
+	err = repo.UpdateOrderStatus(ctx, orderResponse.OrderId, transition)
+	suite.NoError(err)
+
+	transition, err = sm.Transition(model.OrderStatusPreparing, model.OrderStatusCanceled, statemachine.RoleManager)
+	suite.NoError(err)
+
+	ctx = context.WithValue(suite.ctx, chiMiddleware.RequestIDKey, "host/abc-000003")
+	ctx = statemachine.WithRole(ctx, statemachine.RoleManager)
+
+	err = repo.CancelOrder(ctx, orderResponse.OrderId, transition, dto.OrderCancelForm{
+		Reason: model.CancelReasonOutOfStock,
+	})
+	suite.NoError(err)
+
+	history, err := repo.GetOrderHistory(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Equal(3, len(history))
+
+	suite.Equal("", history[0].PreviousStatus)
+	suite.Equal(model.OrderStatusCreated, history[0].NewStatus)
+	suite.Equal(statemachine.RoleWaiter, history[0].Actor)
+	suite.Equal("host/abc-000001", history[0].RequestID)
+
+	suite.Equal(model.OrderStatusCreated, history[1].PreviousStatus)
+	suite.Equal(model.OrderStatusPreparing, history[1].NewStatus)
+	suite.Equal(statemachine.RoleKitchen, history[1].Actor)
+
+	suite.Equal(model.OrderStatusPreparing, history[2].PreviousStatus)
+	suite.Equal(model.OrderStatusCanceled, history[2].NewStatus)
+	suite.Equal(statemachine.RoleManager, history[2].Actor)
+	suite.Equal("host/abc-000003", history[2].RequestID)
+}
+
+func (suite *RepositoryTestSuite) TestGetOrderHistoryNotFoundError() {
+	customerDS := new(MockCustomerRemoteDataSource)
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+
+	history, err := repo.GetOrderHistory(suite.ctx, uint(999))
+	suite.Error(err)
+	suite.Empty(history)
+}
+
+func (suite *RepositoryTestSuite) TestUpdateOrderStatusConflictError() {
+	repoProduct := repositories.NewProductRepository(suite.db)
+	newProduct := dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
+
+	customerDS := new(MockCustomerRemoteDataSource)
+
+	repo := repositories.NewOrderRespository(suite.db, customerDS)
+	newOrder := dto.Order{
+		TotalPrice: 2990,
+		PaymentID:  "wertr",
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: newId,
+			},
+		},
+	}
+
+	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder, time.Now().UnixMilli())
+	suite.NoError(err)
+
+	transition, err := statemachine.NewOrderStateMachine().Transition(model.OrderStatusCreated, model.OrderStatusPreparing, statemachine.RoleManager)
+	suite.NoError(err)
+
+	// two kitchen tablets validated the same transition
+	err = repo.UpdateOrderStatus(suite.ctx, orderResponse.OrderId, transition)
+	suite.NoError(err)
+
+	err = repo.UpdateOrderStatus(suite.ctx, orderResponse.OrderId, transition)
+	suite.Error(err)
+
+	var localError *responses.LocalError
+	suite.True(errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+
+	history, err := repo.GetOrderHistory(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Equal(2, len(history))
 }
@@ -0,0 +1,234 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1-orders/pkg/database"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+	"gorm.io/gorm"
+)
+
+// outboxRelayLock is the advisory lock that makes only one replica claim outbox rows at a time
+const outboxRelayLock = 7220013
+
+// claimOutboxEventsQuery leases the pending rows that are due. A row is skipped while an
+// older row of the same order is waiting for a retry, so the order events never overtake each other.
+// A dead row can never be delivered, so it does not hold the next rows of its order
+const claimOutboxEventsQuery = `
+UPDATE outbox_events SET next_attempt_at = ?
+WHERE id IN (
+	SELECT pending.id FROM outbox_events pending
+	WHERE pending.delivered_at IS NULL
+	AND pending.dead_at IS NULL
+	AND pending.next_attempt_at <= ?
+	AND NOT EXISTS (
+		SELECT 1 FROM outbox_events earlier
+		WHERE earlier.order_id = pending.order_id
+		AND earlier.delivered_at IS NULL
+		AND earlier.dead_at IS NULL
+		AND earlier.id < pending.id
+		AND earlier.next_attempt_at > ?
+	)
+	ORDER BY pending.id
+	LIMIT ?
+)
+RETURNING *`
+
+type OutboxRepository struct {
+	db *database.Database
+}
+
+func NewOutboxRepository(db *database.Database) repository.OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+// ClaimOutboxEvents returns the rows to deliver, oldest first. They are leased until now + lease,
+// so a relay that dies in the middle of the batch has its rows delivered again later.
+// A row whose payload can not be read is marked as dead in the same transaction and left out
+func (repository *OutboxRepository) ClaimOutboxEvents(
+	ctx context.Context,
+	now time.Time,
+	lease time.Duration,
+	limit int,
+) ([]dto.OutboxEvent, error) {
+	tx := repository.db.Connection.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return []dto.OutboxEvent{}, responses.GetDatabaseError(err)
+	}
+
+	var locked bool
+	err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", outboxRelayLock).Scan(&locked).Error
+
+	if err != nil {
+		tx.Rollback()
+		return []dto.OutboxEvent{}, responses.GetDatabaseError(err)
+	}
+
+	if !locked {
+		tx.Rollback()
+		return []dto.OutboxEvent{}, nil
+	}
+
+	var entities []model.OutboxEvent
+	err = tx.Raw(claimOutboxEventsQuery, now.Add(lease), now, now, limit).Scan(&entities).Error
+
+	if err != nil {
+		tx.Rollback()
+		return []dto.OutboxEvent{}, responses.GetDatabaseError(err)
+	}
+
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].ID < entities[j].ID
+	})
+
+	outboxEvents := []dto.OutboxEvent{}
+
+	for _, entity := range entities {
+		var event events.DomainEvent
+		err = json.Unmarshal([]byte(entity.Payload), &event)
+
+		if err != nil {
+			log.Print("dead outbox event", map[string]interface{}{
+				"eventId": entity.ID,
+				"orderId": entity.OrderID,
+				"error":   err.Error(),
+			})
+
+			err = markOutboxEventDead(tx, entity.ID, err.Error(), now)
+
+			if err != nil {
+				tx.Rollback()
+				return []dto.OutboxEvent{}, err
+			}
+
+			continue
+		}
+
+		event.ID = entity.ID
+
+		outboxEvents = append(outboxEvents, dto.OutboxEvent{
+			Attempts: entity.Attempts,
+			Event:    event,
+		})
+	}
+
+	err = tx.Commit().Error
+
+	if err != nil {
+		tx.Rollback()
+		return []dto.OutboxEvent{}, responses.GetDatabaseError(err)
+	}
+
+	return outboxEvents, nil
+}
+
+func (repository *OutboxRepository) MarkOutboxEventDelivered(ctx context.Context, eventID uint) error {
+	err := repository.db.Connection.WithContext(ctx).
+		Model(&model.OutboxEvent{}).
+		Where("id = ?", eventID).
+		Update("delivered_at", time.Now()).
+		Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+func (repository *OutboxRepository) MarkOutboxEventFailed(
+	ctx context.Context,
+	eventID uint,
+	reason string,
+	nextAttemptAt time.Time,
+) error {
+	err := repository.db.Connection.WithContext(ctx).
+		Model(&model.OutboxEvent{}).
+		Where("id = ?", eventID).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"last_error":      reason,
+			"next_attempt_at": nextAttemptAt,
+		}).
+		Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+// DeleteDeliveredOutboxEvents removes the rows delivered before deliveredBefore.
+// The pending and dead rows are kept
+func (repository *OutboxRepository) DeleteDeliveredOutboxEvents(ctx context.Context, deliveredBefore time.Time) (int64, error) {
+	result := repository.db.Connection.WithContext(ctx).
+		Where("delivered_at < ?", deliveredBefore).
+		Delete(&model.OutboxEvent{})
+
+	if result.Error != nil {
+		return 0, responses.GetDatabaseError(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+func markOutboxEventDead(tx *gorm.DB, eventID uint, reason string, now time.Time) error {
+	err := tx.
+		Model(&model.OutboxEvent{}).
+		Where("id = ?", eventID).
+		Updates(map[string]interface{}{
+			"last_error": reason,
+			"dead_at":    now,
+		}).
+		Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+// createOutboxEvent must be called with the transaction that changed the order,
+// so the event exists if and only if the change was committed
+func createOutboxEvent(tx *gorm.DB, event events.DomainEvent) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	payload, err := json.Marshal(event)
+
+	if err != nil {
+		return err
+	}
+
+	err = tx.Create(&model.OutboxEvent{
+		OrderID:       event.OrderID,
+		Type:          event.Type,
+		Payload:       string(payload),
+		NextAttemptAt: event.OccurredAt,
+	}).Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
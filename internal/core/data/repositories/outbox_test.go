@@ -0,0 +1,219 @@
+package repositories_test
+
+import (
+	"time"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/repositories"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
+)
+
+func (suite *RepositoryTestSuite) createOutboxOrder() dto.OrderResponse {
+	repoProduct := repositories.NewProductRepository(suite.db)
+
+	newId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repo := repositories.NewOrderRespository(suite.db, new(MockCustomerRemoteDataSource))
+
+	orderResponse, err := repo.CreateOrder(suite.ctx, dto.Order{
+		TotalPrice: 2990,
+		PaymentID:  "wertr",
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: newId,
+			},
+		},
+	}, time.Now().UnixMilli())
+	suite.NoError(err)
+
+	return orderResponse
+}
+
+func (suite *RepositoryTestSuite) TestOutboxEventsWrittenWithOrderChangesSuccess() {
+	orderResponse := suite.createOutboxOrder()
+
+	repo := repositories.NewOrderRespository(suite.db, new(MockCustomerRemoteDataSource))
+
+	transition, err := statemachine.NewOrderStateMachine().Transition(model.OrderStatusCreated, model.OrderStatusPreparing, statemachine.RoleManager)
+	suite.NoError(err)
+
+	err = repo.UpdateOrderStatus(suite.ctx, orderResponse.OrderId, transition)
+	suite.NoError(err)
+
+	err = repo.DeleteOrder(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+
+	outboxRepo := repositories.NewOutboxRepository(suite.db)
+
+	outboxEvents, err := outboxRepo.ClaimOutboxEvents(suite.ctx, time.Now(), time.Minute, 10)
+	suite.NoError(err)
+	suite.Equal(3, len(outboxEvents))
+
+	suite.Equal(events.OrderCreated, outboxEvents[0].Event.Type)
+	suite.Equal(model.OrderStatusCreated, outboxEvents[0].Event.OrderStatus)
+	suite.Equal("wertr", outboxEvents[0].Event.PaymentID)
+
+	suite.Equal(events.OrderStatusChanged, outboxEvents[1].Event.Type)
+	suite.Equal(model.OrderStatusCreated, outboxEvents[1].Event.PreviousStatus)
+	suite.Equal(model.OrderStatusPreparing, outboxEvents[1].Event.OrderStatus)
+
+	suite.Equal(events.OrderDeleted, outboxEvents[2].Event.Type)
+	suite.Equal(model.OrderStatusPreparing, outboxEvents[2].Event.PreviousStatus)
+
+	for _, outboxEvent := range outboxEvents {
+		suite.Equal(orderResponse.OrderId, outboxEvent.Event.OrderID)
+		suite.NotZero(outboxEvent.Event.ID)
+	}
+}
+
+func (suite *RepositoryTestSuite) TestOutboxEventNotWrittenWhenOrderChangeFailsSuccess() {
+	orderResponse := suite.createOutboxOrder()
+
+	repo := repositories.NewOrderRespository(suite.db, new(MockCustomerRemoteDataSource))
+
+	// the order is not Preparing, so the compare-and-set update is rolled back
+	transition, err := statemachine.NewOrderStateMachine().Transition(model.OrderStatusPreparing, model.OrderStatusDone, statemachine.RoleManager)
+	suite.NoError(err)
+
+	err = repo.UpdateOrderStatus(suite.ctx, orderResponse.OrderId, transition)
+	suite.Error(err)
+
+	outboxEvents, err := repositories.NewOutboxRepository(suite.db).ClaimOutboxEvents(suite.ctx, time.Now(), time.Minute, 10)
+	suite.NoError(err)
+	suite.Equal(1, len(outboxEvents))
+	suite.Equal(events.OrderCreated, outboxEvents[0].Event.Type)
+}
+
+func (suite *RepositoryTestSuite) TestClaimOutboxEventsKeepsOrderSequenceSuccess() {
+	orderResponse := suite.createOutboxOrder()
+
+	repo := repositories.NewOrderRespository(suite.db, new(MockCustomerRemoteDataSource))
+
+	transition, err := statemachine.NewOrderStateMachine().Transition(model.OrderStatusCreated, model.OrderStatusPreparing, statemachine.RoleManager)
+	suite.NoError(err)
+
+	err = repo.UpdateOrderStatus(suite.ctx, orderResponse.OrderId, transition)
+	suite.NoError(err)
+
+	outboxRepo := repositories.NewOutboxRepository(suite.db)
+	now := time.Now()
+
+	outboxEvents, err := outboxRepo.ClaimOutboxEvents(suite.ctx, now, time.Minute, 10)
+	suite.NoError(err)
+	suite.Equal(2, len(outboxEvents))
+
+	// leased rows are not claimed again until the lease expires
+	leased, err := outboxRepo.ClaimOutboxEvents(suite.ctx, now, time.Minute, 10)
+	suite.NoError(err)
+	suite.Empty(leased)
+
+	err = outboxRepo.MarkOutboxEventFailed(suite.ctx, outboxEvents[0].Event.ID, "connection refused", now.Add(10*time.Minute))
+	suite.NoError(err)
+
+	// after the lease, the second event still waits for the first one of the order
+	blocked, err := outboxRepo.ClaimOutboxEvents(suite.ctx, now.Add(2*time.Minute), time.Minute, 10)
+	suite.NoError(err)
+	suite.Empty(blocked)
+
+	retried, err := outboxRepo.ClaimOutboxEvents(suite.ctx, now.Add(11*time.Minute), time.Minute, 10)
+	suite.NoError(err)
+	suite.Equal(2, len(retried))
+	suite.Equal(1, retried[0].Attempts)
+	suite.Equal(outboxEvents[0].Event.ID, retried[0].Event.ID)
+
+	for _, outboxEvent := range retried {
+		suite.NoError(outboxRepo.MarkOutboxEventDelivered(suite.ctx, outboxEvent.Event.ID))
+	}
+
+	delivered, err := outboxRepo.ClaimOutboxEvents(suite.ctx, now.Add(time.Hour), time.Minute, 10)
+	suite.NoError(err)
+	suite.Empty(delivered)
+}
+
+func (suite *RepositoryTestSuite) TestClaimOutboxEventsDeadLettersUnreadablePayloadSuccess() {
+	orderResponse := suite.createOutboxOrder()
+
+	repo := repositories.NewOrderRespository(suite.db, new(MockCustomerRemoteDataSource))
+
+	transition, err := statemachine.NewOrderStateMachine().Transition(model.OrderStatusCreated, model.OrderStatusPreparing, statemachine.RoleManager)
+	suite.NoError(err)
+
+	err = repo.UpdateOrderStatus(suite.ctx, orderResponse.OrderId, transition)
+	suite.NoError(err)
+
+	var created model.OutboxEvent
+	err = suite.db.Connection.Where("order_id = ?", orderResponse.OrderId).Order("id").First(&created).Error
+	suite.NoError(err)
+
+	err = suite.db.Connection.Model(&created).Update("payload", "not json").Error
+	suite.NoError(err)
+
+	outboxRepo := repositories.NewOutboxRepository(suite.db)
+	now := time.Now()
+
+	// the unreadable row does not fail the batch nor hold the next row of the order
+	outboxEvents, err := outboxRepo.ClaimOutboxEvents(suite.ctx, now, time.Minute, 10)
+	suite.NoError(err)
+	suite.Equal(1, len(outboxEvents))
+	suite.Equal(events.OrderStatusChanged, outboxEvents[0].Event.Type)
+
+	var dead model.OutboxEvent
+	err = suite.db.Connection.First(&dead, created.ID).Error
+	suite.NoError(err)
+	suite.NotNil(dead.DeadAt)
+	suite.NotEmpty(dead.LastError)
+
+	claimed, err := outboxRepo.ClaimOutboxEvents(suite.ctx, now.Add(time.Hour), time.Minute, 10)
+	suite.NoError(err)
+	suite.Equal(1, len(claimed))
+	suite.Equal(outboxEvents[0].Event.ID, claimed[0].Event.ID)
+}
+
+func (suite *RepositoryTestSuite) TestDeleteDeliveredOutboxEventsSuccess() {
+	orderResponse := suite.createOutboxOrder()
+
+	repo := repositories.NewOrderRespository(suite.db, new(MockCustomerRemoteDataSource))
+
+	transition, err := statemachine.NewOrderStateMachine().Transition(model.OrderStatusCreated, model.OrderStatusPreparing, statemachine.RoleManager)
+	suite.NoError(err)
+
+	err = repo.UpdateOrderStatus(suite.ctx, orderResponse.OrderId, transition)
+	suite.NoError(err)
+
+	outboxRepo := repositories.NewOutboxRepository(suite.db)
+
+	outboxEvents, err := outboxRepo.ClaimOutboxEvents(suite.ctx, time.Now(), time.Minute, 10)
+	suite.NoError(err)
+	suite.Equal(2, len(outboxEvents))
+
+	err = outboxRepo.MarkOutboxEventDelivered(suite.ctx, outboxEvents[0].Event.ID)
+	suite.NoError(err)
+
+	// delivered now, so it is kept until the retention is over
+	deleted, err := outboxRepo.DeleteDeliveredOutboxEvents(suite.ctx, time.Now().Add(-time.Hour))
+	suite.NoError(err)
+	suite.Equal(int64(0), deleted)
+
+	deleted, err = outboxRepo.DeleteDeliveredOutboxEvents(suite.ctx, time.Now().Add(time.Hour))
+	suite.NoError(err)
+	suite.Equal(int64(1), deleted)
+
+	// the pending row is kept
+	var remaining int64
+	err = suite.db.Connection.Model(&model.OutboxEvent{}).Where("order_id = ?", orderResponse.OrderId).Count(&remaining).Error
+	suite.NoError(err)
+	suite.Equal(int64(1), remaining)
+}
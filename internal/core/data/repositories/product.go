@@ -2,6 +2,10 @@ package repositories
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
@@ -10,8 +14,13 @@ import (
 	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// maxComboDepth is how many levels of combo products are shown, a combo of combos
+// deeper than that lists its last level without their combo products
+const maxComboDepth = 3
+
 type ProductRepository struct {
 	db *database.Database
 }
@@ -22,16 +31,6 @@ func NewProductRepository(db *database.Database) repository.ProductRepository {
 	}
 }
 
-func (repository *ProductRepository) GetCategories() []string {
-	return []string{
-		model.CategoryCombo,
-		model.CategorySnack,
-		model.CategoryBeverage,
-		model.CategoryToppings,
-		model.CategoryDesert,
-	}
-}
-
 func (repository *ProductRepository) CreateProduct(ctx context.Context, product dto.ProductForm) (uint, error) {
 	tx := repository.db.Connection.WithContext(ctx).Begin()
 	defer func() {
@@ -44,14 +43,21 @@ func (repository *ProductRepository) CreateProduct(ctx context.Context, product
 		return 0, responses.GetDatabaseError(err)
 	}
 
+	categoryId, err := findCategoryId(tx, product.Category)
+
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
 	productEntity := &model.Product{
 		Name:        product.Name,
 		Description: product.Description,
-		Category:    product.Category,
+		CategoryID:  categoryId,
 		Price:       product.Price,
 	}
 
-	err := tx.Create(productEntity).Error
+	err = tx.Create(productEntity).Error
 
 	if err != nil {
 		tx.Rollback()
@@ -78,7 +84,7 @@ func (repository *ProductRepository) CreateProduct(ctx context.Context, product
 
 	if err != nil {
 		tx.Rollback()
-		return 0, responses.GetDatabaseError(err)
+		return 0, err
 	}
 
 	err = tx.Commit().Error
@@ -97,6 +103,12 @@ func (repository *ProductRepository) createComboIfProductsNedded(
 	comboId uint,
 ) error {
 	if productWithCombo.ComboProductsIds != nil {
+		err := lockComboProducts(tx, *productWithCombo.ComboProductsIds)
+
+		if err != nil {
+			return err
+		}
+
 		for _, value := range *productWithCombo.ComboProductsIds {
 			comboProductEntity := &model.ComboProduct{
 				ProductID:      comboId,
@@ -117,12 +129,9 @@ func (repository *ProductRepository) createComboIfProductsNedded(
 
 func (repository *ProductRepository) GetProductsByCategory(ctx context.Context, category string) ([]dto.ProductResponse, error) {
 	var productmodel []model.Product
-	err := repository.
-		db.Connection.WithContext(ctx).
-		Model(&model.Product{}).
-		Preload("ProductImage").
-		Preload("ComboProduct").
-		Where("category = ?", category).
+	err := repository.productQuery(ctx).
+		Joins("JOIN categories ON categories.id = products.category_id AND categories.deleted_at IS NULL").
+		Where("categories.name = ? AND categories.active = ? AND products.archived_at IS NULL", category, true).
 		Find(&productmodel).
 		Error
 
@@ -130,16 +139,12 @@ func (repository *ProductRepository) GetProductsByCategory(ctx context.Context,
 		return []dto.ProductResponse{}, responses.GetDatabaseError(err)
 	}
 
-	return repository.buildProducts(ctx, productmodel), nil
+	return repository.buildProducts(ctx, productmodel)
 }
 
 func (repository *ProductRepository) GetProductById(ctx context.Context, id uint) (dto.ProductResponse, error) {
 	var productEntity model.Product
-	err := repository.
-		db.Connection.WithContext(ctx).
-		Model(&model.Product{}).
-		Preload("ProductImage").
-		Preload("ComboProduct").
+	err := repository.productQuery(ctx).
 		First(&productEntity, id).
 		Error
 
@@ -147,10 +152,103 @@ func (repository *ProductRepository) GetProductById(ctx context.Context, id uint
 		return dto.ProductResponse{}, responses.GetDatabaseError(err)
 	}
 
-	return repository.buildProduct(ctx, productEntity), nil
+	products, err := repository.buildProducts(ctx, []model.Product{productEntity})
+
+	if err != nil {
+		return dto.ProductResponse{}, err
+	}
+
+	return products[0], nil
+}
+
+func (repository *ProductRepository) GetProductsByIds(ctx context.Context, ids []uint) ([]dto.ProductResponse, error) {
+	var productmodel []model.Product
+	err := repository.productQuery(ctx).
+		Where("products.id IN ?", ids).
+		Find(&productmodel).
+		Error
+
+	if err != nil {
+		return []dto.ProductResponse{}, responses.GetDatabaseError(err)
+	}
+
+	return repository.buildProducts(ctx, productmodel)
+}
+
+// ArchiveProduct hides the product from the menu. The product, its images and its combo products
+// are kept, so the orders still show it. A product in a combo that is not archived can not be archived
+func (repository *ProductRepository) ArchiveProduct(ctx context.Context, productId uint) error {
+	tx := repository.db.Connection.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	var productEntity model.Product
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&productEntity, productId).Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	if productEntity.ArchivedAt != nil {
+		tx.Rollback()
+		return nil
+	}
+
+	var combos []model.Product
+
+	err = tx.Model(&model.Product{}).
+		Select("id", "name").
+		Where(
+			"archived_at IS NULL AND (id IN (?) OR id IN (?))",
+			tx.Model(&model.ComboProduct{}).Select("product_id").Where("combo_product_id = ?", productId),
+			comboSlotOptions(tx).Select("combo_slots.combo_id").Where("combo_slot_options.product_id = ?", productId),
+		).
+		Order("id").
+		Find(&combos).
+		Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	if len(combos) > 0 {
+		tx.Rollback()
+		return &responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: fmt.Sprintf("Product is in the combos %v. Archive the combos or remove the product from them first", describeProducts(combos)),
+		}
+	}
+
+	err = tx.Model(&productEntity).Update("archived_at", time.Now()).Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	err = tx.Commit().Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
 }
 
-func (repository *ProductRepository) DeleteProduct(ctx context.Context, productId uint) error {
+// RestoreProduct shows an archived product in the menu again. A combo can only be
+// restored when none of its products are archived
+func (repository *ProductRepository) RestoreProduct(ctx context.Context, productId uint) error {
 	tx := repository.db.Connection.WithContext(ctx).Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -162,21 +260,47 @@ func (repository *ProductRepository) DeleteProduct(ctx context.Context, productI
 		return responses.GetDatabaseError(err)
 	}
 
-	err := tx.Where("product_id = ?", productId).Unscoped().Delete(&model.ProductImage{}).Error
+	var productEntity model.Product
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&productEntity, productId).Error
 
 	if err != nil {
 		tx.Rollback()
 		return responses.GetDatabaseError(err)
 	}
 
-	err = tx.Where("product_id = ?", productId).Unscoped().Delete(&model.ComboProduct{}).Error
+	if productEntity.ArchivedAt == nil {
+		tx.Rollback()
+		return nil
+	}
+
+	var archivedProducts []model.Product
+
+	err = tx.Model(&model.Product{}).
+		Select("id", "name").
+		Where(
+			"archived_at IS NOT NULL AND (id IN (?) OR id IN (?))",
+			tx.Model(&model.ComboProduct{}).Select("combo_product_id").Where("product_id = ?", productId),
+			comboSlotOptions(tx).Select("combo_slot_options.product_id").Where("combo_slots.combo_id = ?", productId),
+		).
+		Order("id").
+		Find(&archivedProducts).
+		Error
 
 	if err != nil {
 		tx.Rollback()
 		return responses.GetDatabaseError(err)
 	}
 
-	err = tx.Unscoped().Delete(&model.Product{}, productId).Error
+	if len(archivedProducts) > 0 {
+		tx.Rollback()
+		return &responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: fmt.Sprintf("Combo has the archived products %v. Restore them first", describeProducts(archivedProducts)),
+		}
+	}
+
+	err = tx.Model(&productEntity).Update("archived_at", nil).Error
 
 	if err != nil {
 		tx.Rollback()
@@ -193,35 +317,417 @@ func (repository *ProductRepository) DeleteProduct(ctx context.Context, productI
 	return nil
 }
 
+// UpdateProduct changes the product, its images and its combo products in one transaction.
+// The saved images and combo products are compared with the form, so only the changed rows
+// are created or removed and the unchanged ones keep their ids
 func (repository *ProductRepository) UpdateProduct(ctx context.Context, product dto.ProductForm) error {
-	productEntity := model.Product{
-		Model:       gorm.Model{ID: product.Id},
-		Name:        product.Name,
-		Description: product.Description,
-		Category:    product.Category,
-		Price:       product.Price,
+	tx := repository.db.Connection.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	categoryId, err := findCategoryId(tx, product.Category)
+
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result := tx.Model(&model.Product{Model: gorm.Model{ID: product.Id}}).
+		Select("Name", "Description", "CategoryID", "Price").
+		Updates(model.Product{
+			Name:        product.Name,
+			Description: product.Description,
+			CategoryID:  categoryId,
+			Price:       product.Price,
+		})
+
+	if result.Error != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Product not found",
+		}
+	}
+
+	var slots int64
+
+	err = tx.Model(&model.ComboSlot{}).Where("combo_id = ?", product.Id).Count(&slots).Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	// the combos made by the combo endpoints have slots, which are only changed there
+	if slots > 0 && (product.Category != model.CategoryCombo || product.ComboProductsIds != nil && len(*product.ComboProductsIds) > 0) {
+		tx.Rollback()
+		return &responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: "Combo has slots, it must be updated by the combo endpoints",
+		}
+	}
+
+	err = updateProductImages(tx, product.Id, product.Images)
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	// a combo sent without comboProductsIds keeps the products it has
+	if product.Category != model.CategoryCombo || product.ComboProductsIds != nil {
+		comboProductsIds := []uint{}
+
+		if product.ComboProductsIds != nil {
+			comboProductsIds = *product.ComboProductsIds
+		}
+
+		err = lockComboProducts(tx, comboProductsIds)
+
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		err = updateComboProducts(tx, product.Id, comboProductsIds)
+
+		if err != nil {
+			tx.Rollback()
+			return responses.GetDatabaseError(err)
+		}
+	}
+
+	err = tx.Commit().Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+// HasComboComponents is true when the product has combo products or, for the combos
+// made by the combo endpoints, slots
+func (repository *ProductRepository) HasComboComponents(ctx context.Context, productId uint) (bool, error) {
+	var comboProducts int64
+
+	err := repository.db.Connection.WithContext(ctx).
+		Model(&model.ComboProduct{}).
+		Where("product_id = ?", productId).
+		Count(&comboProducts).
+		Error
+
+	if err != nil {
+		return false, responses.GetDatabaseError(err)
+	}
+
+	var slots int64
+
+	err = repository.db.Connection.WithContext(ctx).
+		Model(&model.ComboSlot{}).
+		Where("combo_id = ?", productId).
+		Count(&slots).
+		Error
+
+	if err != nil {
+		return false, responses.GetDatabaseError(err)
 	}
 
-	err := repository.db.Connection.WithContext(ctx).Save(&productEntity).Error
+	return comboProducts > 0 || slots > 0, nil
+}
+
+func updateProductImages(tx *gorm.DB, productId uint, images []dto.ProducImage) error {
+	var savedImages []model.ProductImage
+
+	err := tx.Where("product_id = ?", productId).Order("id").Find(&savedImages).Error
+
+	if err != nil {
+		return err
+	}
+
+	savedUrls := []string{}
+
+	for _, value := range savedImages {
+		savedUrls = append(savedUrls, value.ImageUrl)
+	}
+
+	wantedUrls := []string{}
+
+	for _, value := range images {
+		wantedUrls = append(wantedUrls, value.ImageUrl)
+	}
+
+	removed, added := diffKeys(savedUrls, wantedUrls)
+
+	if len(removed) > 0 {
+		removedIds := []uint{}
+
+		for _, index := range removed {
+			removedIds = append(removedIds, savedImages[index].ID)
+		}
+
+		err = tx.Unscoped().Delete(&model.ProductImage{}, removedIds).Error
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(added) > 0 {
+		productImages := []*model.ProductImage{}
+
+		for _, value := range added {
+			productImages = append(productImages, &model.ProductImage{
+				ProductID: productId,
+				ImageUrl:  value,
+			})
+		}
+
+		err = tx.Create(productImages).Error
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func updateComboProducts(tx *gorm.DB, comboId uint, comboProductsIds []uint) error {
+	var savedComboProducts []model.ComboProduct
+
+	err := tx.Where("product_id = ?", comboId).Order("id").Find(&savedComboProducts).Error
+
+	if err != nil {
+		return err
+	}
+
+	savedIds := []uint{}
+
+	for _, value := range savedComboProducts {
+		savedIds = append(savedIds, value.ComboProductID)
+	}
+
+	removed, added := diffKeys(savedIds, comboProductsIds)
+
+	if len(removed) > 0 {
+		removedIds := []uint{}
+
+		for _, index := range removed {
+			removedIds = append(removedIds, savedComboProducts[index].ID)
+		}
+
+		err = tx.Unscoped().Delete(&model.ComboProduct{}, removedIds).Error
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(added) > 0 {
+		comboProducts := []*model.ComboProduct{}
+
+		for _, value := range added {
+			comboProducts = append(comboProducts, &model.ComboProduct{
+				ProductID:      comboId,
+				ComboProductID: value,
+			})
+		}
+
+		err = tx.Create(comboProducts).Error
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lockComboProducts checks that the combo products exist and are not archived, and keeps
+// them from being archived until the transaction ends
+func lockComboProducts(tx *gorm.DB, comboProductsIds []uint) error {
+	if len(comboProductsIds) == 0 {
+		return nil
+	}
+
+	var ids []uint
+
+	err := tx.Model(&model.Product{}).
+		Clauses(clause.Locking{Strength: "SHARE"}).
+		Where("id IN ? AND archived_at IS NULL", comboProductsIds).
+		Pluck("id", &ids).
+		Error
 
 	if err != nil {
 		return responses.GetDatabaseError(err)
 	}
 
+	found := map[uint]bool{}
+
+	for _, id := range ids {
+		found[id] = true
+	}
+
+	missing := []uint{}
+
+	for _, id := range comboProductsIds {
+		if !found[id] {
+			found[id] = true
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) > 0 {
+		return &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: fmt.Sprintf("Combo products %v not found or archived", missing),
+		}
+	}
+
 	return nil
 }
 
-func (repository *ProductRepository) buildProducts(ctx context.Context, productmodel []model.Product) []dto.ProductResponse {
+// comboSlotOptions queries the options of the slots the combos still have
+func comboSlotOptions(tx *gorm.DB) *gorm.DB {
+	return tx.Model(&model.ComboSlotOption{}).
+		Joins("JOIN combo_slots ON combo_slots.id = combo_slot_options.combo_slot_id AND combo_slots.deleted_at IS NULL")
+}
+
+func describeProducts(products []model.Product) string {
+	names := []string{}
+
+	for _, value := range products {
+		names = append(names, fmt.Sprintf("%v (%v)", value.Name, value.ID))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// diffKeys compares the saved rows with the ones sent in the form by their keys. It answers the
+// positions of the saved rows to remove and the keys to create. A repeated key is counted every time,
+// so a combo with two of the same product keeps both rows
+func diffKeys[K comparable](saved []K, wanted []K) ([]int, []K) {
+	missing := map[K]int{}
+
+	for _, key := range wanted {
+		missing[key]++
+	}
+
+	removed := []int{}
+
+	for index, key := range saved {
+		if missing[key] > 0 {
+			missing[key]--
+			continue
+		}
+
+		removed = append(removed, index)
+	}
+
+	added := []K{}
+
+	for _, key := range wanted {
+		if missing[key] > 0 {
+			missing[key]--
+			added = append(added, key)
+		}
+	}
+
+	return removed, added
+}
+
+func (repository *ProductRepository) productQuery(ctx context.Context) *gorm.DB {
+	return repository.db.Connection.WithContext(ctx).
+		Model(&model.Product{}).
+		Preload("Category").
+		Preload("ProductImage").
+		Preload("ComboProduct")
+}
+
+// buildProducts loads the combo products of all the products together, so the number of
+// queries depends on how deep the combos go and not on how many products they have
+func (repository *ProductRepository) buildProducts(ctx context.Context, productmodel []model.Product) ([]dto.ProductResponse, error) {
+	productsById, err := repository.loadComboProducts(ctx, productmodel)
+
+	if err != nil {
+		return []dto.ProductResponse{}, err
+	}
+
 	products := []dto.ProductResponse{}
 
 	for _, value := range productmodel {
-		products = append(products, repository.buildProduct(ctx, value))
+		products = append(products, buildProduct(value, productsById, []uint{}))
 	}
 
-	return products
+	return products, nil
 }
 
-func (repository *ProductRepository) buildProduct(ctx context.Context, value model.Product) dto.ProductResponse {
+// loadComboProducts loads the combo products with one query per level, down to maxComboDepth.
+// A product is loaded only once, so a combo that contains itself does not load forever
+func (repository *ProductRepository) loadComboProducts(ctx context.Context, productmodel []model.Product) (map[uint]model.Product, error) {
+	productsById := map[uint]model.Product{}
+
+	for _, value := range productmodel {
+		productsById[value.ID] = value
+	}
+
+	level := productmodel
+
+	for depth := 0; depth < maxComboDepth; depth++ {
+		ids := []uint{}
+		missing := map[uint]bool{}
+
+		for _, value := range level {
+			for _, comboProduct := range value.ComboProduct {
+				_, ok := productsById[comboProduct.ComboProductID]
+
+				if !ok && !missing[comboProduct.ComboProductID] {
+					missing[comboProduct.ComboProductID] = true
+					ids = append(ids, comboProduct.ComboProductID)
+				}
+			}
+		}
+
+		if len(ids) == 0 {
+			break
+		}
+
+		level = []model.Product{}
+		err := repository.productQuery(ctx).
+			Where("products.id IN ?", ids).
+			Find(&level).
+			Error
+
+		if err != nil {
+			return productsById, responses.GetDatabaseError(err)
+		}
+
+		for _, value := range level {
+			productsById[value.ID] = value
+		}
+	}
+
+	return productsById, nil
+}
+
+// buildProduct builds the combo products from the ones already loaded. combos holds the
+// combos above the product, a combo product that is one of them or is deeper than
+// maxComboDepth is left out
+func buildProduct(value model.Product, productsById map[uint]model.Product, combos []uint) dto.ProductResponse {
 	images := []dto.ProducImage{}
 
 	for _, valueImage := range value.ProductImage {
@@ -230,39 +736,52 @@ func (repository *ProductRepository) buildProduct(ctx context.Context, value mod
 		})
 	}
 
-	comboProducts := repository.getComboProductsIfNedded(ctx, value)
+	var comboProducts []dto.ProductResponse
+
+	if value.ComboProduct != nil {
+		comboProducts = make([]dto.ProductResponse, 0)
+		combos = append(combos, value.ID)
+
+		for _, comboProduct := range value.ComboProduct {
+			product, ok := productsById[comboProduct.ComboProductID]
+
+			if !ok || len(combos) > maxComboDepth || slices.Contains(combos, product.ID) {
+				continue
+			}
+
+			comboProducts = append(comboProducts, buildProduct(product, productsById, combos))
+		}
+	}
 
 	return dto.ProductResponse{
 		Id:            value.ID,
 		Name:          value.Name,
 		Description:   value.Description,
-		Category:      value.Category,
+		CategoryId:    value.CategoryID,
+		Category:      value.Category.Name,
 		Price:         value.Price,
 		Images:        images,
-		ComboProducts: comboProducts,
+		ArchivedAt:    value.ArchivedAt,
+		ComboProducts: &comboProducts,
 	}
 }
 
-func (repository *ProductRepository) getComboProductsIfNedded(ctx context.Context, value model.Product) *[]dto.ProductResponse {
-	var comboProducts []dto.ProductResponse
+// findCategoryId resolves the category name sent by the admin. An unknown name is a not found
+// error, so a product never lands in a category missing from the menu
+func findCategoryId(tx *gorm.DB, name string) (uint, error) {
+	var category model.Category
 
-	if value.ComboProduct != nil {
-		comboProducts = make([]dto.ProductResponse, 0)
+	err := tx.Where("name = ?", name).First(&category).Error
 
-		for _, comboProduct := range value.ComboProduct {
-			var product model.Product
-
-			err := repository.db.Connection.
-				WithContext(ctx).
-				Preload("ProductImage").
-				First(&product, comboProduct.ComboProductID).
-				Error
+	if err != nil {
+		localError := responses.GetDatabaseError(err)
 
-			if err == nil {
-				comboProducts = append(comboProducts, repository.buildProduct(ctx, product))
-			}
+		if localError.Code == responses.NOT_FOUND_ERROR {
+			localError.Message = fmt.Sprintf("Category %v not found", name)
 		}
+
+		return 0, localError
 	}
 
-	return &comboProducts
+	return category.ID, nil
 }
@@ -0,0 +1,239 @@
+package repositories_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/repositories"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/pkg/database"
+	"gorm.io/gorm"
+)
+
+const (
+	productsByCategoryQuery = "SELECT (.+) FROM `products` JOIN categories"
+	productByIdQuery        = "SELECT (.+) FROM `products` WHERE `products`.`id` = \\?"
+	productsByIdsQuery      = "SELECT (.+) FROM `products` WHERE products.id IN"
+	categoriesPreloadQuery  = "SELECT (.+) FROM `categories` WHERE `categories`.`id` = \\?"
+	comboProductsQuery      = "SELECT (.+) FROM `combo_products` WHERE `combo_products`.`product_id`"
+	productImagesQuery      = "SELECT (.+) FROM `product_images` WHERE `product_images`.`product_id`"
+)
+
+// comboLink is a combo_products row, the combo product of a product
+type comboLink struct {
+	productId      uint
+	comboProductId uint
+}
+
+func productRows(ids []uint) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "name", "category_id", "price"})
+
+	for _, id := range ids {
+		rows.AddRow(id, fmt.Sprintf("Product %v", id), 1, 990)
+	}
+
+	return rows
+}
+
+func comboProductRows(links []comboLink) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "product_id", "combo_product_id"})
+
+	for index, link := range links {
+		rows.AddRow(index+1, link.productId, link.comboProductId)
+	}
+
+	return rows
+}
+
+// expectProductsLevel expects the query of one level of products and its preloads,
+// which gorm runs in the order of the preload names
+func expectProductsLevel(mock sqlmock.Sqlmock, query string, ids []uint, links []comboLink) {
+	mock.ExpectQuery(query).WillReturnRows(productRows(ids))
+	mock.ExpectQuery(categoriesPreloadQuery).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Combo"))
+	mock.ExpectQuery(comboProductsQuery).WillReturnRows(comboProductRows(links))
+	mock.ExpectQuery(productImagesQuery).WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "image_url"}))
+}
+
+// expectCombosByCategory expects the combos of the category with comboSize products each
+func expectCombosByCategory(mock sqlmock.Sqlmock, combos int, comboSize int) {
+	comboIds := []uint{}
+	productIds := []uint{}
+	links := []comboLink{}
+
+	for combo := 1; combo <= combos; combo++ {
+		comboIds = append(comboIds, uint(combo))
+
+		for item := 0; item < comboSize; item++ {
+			productId := uint(combos + (combo-1)*comboSize + item + 1)
+			productIds = append(productIds, productId)
+			links = append(links, comboLink{productId: uint(combo), comboProductId: productId})
+		}
+	}
+
+	expectProductsLevel(mock, productsByCategoryQuery, comboIds, links)
+	expectProductsLevel(mock, productsByIdsQuery, productIds, []comboLink{})
+}
+
+func setupCountedProductRepository() (*gorm.DB, sqlmock.Sqlmock, *int, error) {
+	gormDB, mock, err := SetupDBMocksWithMatcher(sqlmock.QueryMatcherRegexp)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	queries := 0
+	err = gormDB.Callback().Query().After("gorm:query").Register("count_queries", func(db *gorm.DB) {
+		queries++
+	})
+
+	return gormDB, mock, &queries, err
+}
+
+func TestProductComboQueries(t *testing.T) {
+	t.Parallel()
+
+	for _, combos := range []int{1, 20} {
+		t.Run(fmt.Sprintf("got combo products in one query per level for %v combos", combos), func(t *testing.T) {
+			t.Parallel()
+
+			gormDB, mock, queries, err := setupCountedProductRepository()
+			assert.NoError(t, err)
+
+			expectCombosByCategory(mock, combos, 4)
+
+			sut := repositories.NewProductRepository(&database.Database{Connection: gormDB})
+
+			response, err := sut.GetProductsByCategory(context.TODO(), "Combo")
+
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, 8, *queries)
+			assert.Equal(t, combos, len(response))
+			assert.Equal(t, 4, len(*response[combos-1].ComboProducts))
+			assert.Equal(t, uint(combos*4+combos), (*response[combos-1].ComboProducts)[3].Id)
+		})
+	}
+
+	t.Run("got cyclic combo products left out when getting product by id", func(t *testing.T) {
+		t.Parallel()
+
+		gormDB, mock, queries, err := setupCountedProductRepository()
+		assert.NoError(t, err)
+
+		// 1 has 2, 2 has 1 and 3, 3 has 2
+		expectProductsLevel(mock, productByIdQuery, []uint{1}, []comboLink{{1, 2}})
+		expectProductsLevel(mock, productsByIdsQuery, []uint{2}, []comboLink{{2, 1}, {2, 3}})
+		expectProductsLevel(mock, productsByIdsQuery, []uint{3}, []comboLink{{3, 2}})
+
+		sut := repositories.NewProductRepository(&database.Database{Connection: gormDB})
+
+		response, err := sut.GetProductById(context.TODO(), 1)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, 12, *queries)
+
+		second := (*response.ComboProducts)[0]
+		assert.Equal(t, uint(2), second.Id)
+		assert.Equal(t, 1, len(*second.ComboProducts))
+
+		third := (*second.ComboProducts)[0]
+		assert.Equal(t, uint(3), third.Id)
+		assert.Empty(t, *third.ComboProducts)
+	})
+
+	t.Run("got combo products cut at the max depth when getting product by id", func(t *testing.T) {
+		t.Parallel()
+
+		gormDB, mock, queries, err := setupCountedProductRepository()
+		assert.NoError(t, err)
+
+		// 1 has 2, 2 has 3, 3 has 4 and 4 has 5, which is never loaded
+		expectProductsLevel(mock, productByIdQuery, []uint{1}, []comboLink{{1, 2}})
+		expectProductsLevel(mock, productsByIdsQuery, []uint{2}, []comboLink{{2, 3}})
+		expectProductsLevel(mock, productsByIdsQuery, []uint{3}, []comboLink{{3, 4}})
+		expectProductsLevel(mock, productsByIdsQuery, []uint{4}, []comboLink{{4, 5}})
+
+		sut := repositories.NewProductRepository(&database.Database{Connection: gormDB})
+
+		response, err := sut.GetProductById(context.TODO(), 1)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, 16, *queries)
+
+		product := response
+
+		for _, id := range []uint{2, 3, 4} {
+			assert.Equal(t, 1, len(*product.ComboProducts))
+			product = (*product.ComboProducts)[0]
+			assert.Equal(t, id, product.Id)
+		}
+
+		assert.Empty(t, *product.ComboProducts)
+	})
+
+	t.Run("got error when loading combo products fails", func(t *testing.T) {
+		t.Parallel()
+
+		gormDB, mock, _, err := setupCountedProductRepository()
+		assert.NoError(t, err)
+
+		expectProductsLevel(mock, productByIdQuery, []uint{1}, []comboLink{{1, 2}})
+		mock.ExpectQuery(productsByIdsQuery).WillReturnError(gorm.ErrInvalidDB)
+
+		sut := repositories.NewProductRepository(&database.Database{Connection: gormDB})
+
+		response, err := sut.GetProductById(context.TODO(), 1)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+	})
+}
+
+func BenchmarkGetProductsByCategoryCombos(b *testing.B) {
+	gormDB, mock, queries, err := setupCountedProductRepository()
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	sut := repositories.NewProductRepository(&database.Database{Connection: gormDB})
+	ctx := context.TODO()
+
+	var response []dto.ProductResponse
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		expectCombosByCategory(mock, 20, 4)
+		b.StartTimer()
+
+		response, err = sut.GetProductsByCategory(ctx, "Combo")
+
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.StopTimer()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		b.Fatal(err)
+	}
+
+	if len(response) != 20 {
+		b.Fatalf("got %v combos, want 20", len(response))
+	}
+
+	// 20 combos of 4 products are loaded by the combos query and the products query, each with 3 preloads
+	b.ReportMetric(float64(*queries)/float64(b.N), "queries/op")
+
+	if *queries != 8*b.N {
+		b.Fatalf("got %v queries for %v runs, want 8 per run", *queries, b.N)
+	}
+}
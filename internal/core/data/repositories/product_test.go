@@ -3,6 +3,7 @@ package repositories_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
@@ -27,7 +28,7 @@ func (suite *RepositoryTestSuite) TestGetProductsWithSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Lanches",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -39,7 +40,7 @@ func (suite *RepositoryTestSuite) TestGetProductsWithSuccess() {
 	suite.NoError(err)
 	suite.Equal(uint(1), newId)
 
-	createdProducts, err := repo.GetProductsByCategory(suite.ctx, "Lanches")
+	createdProducts, err := repo.GetProductsByCategory(suite.ctx, model.CategorySnack)
 
 	suite.NoError(err)
 	suite.Equal(1, len(createdProducts))
@@ -58,7 +59,7 @@ func (suite *RepositoryTestSuite) TestGetProductByIdWithSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Lanches",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -76,6 +77,30 @@ func (suite *RepositoryTestSuite) TestGetProductByIdWithSuccess() {
 	suite.Equal("New Product Created", createdProduct.Name)
 }
 
+func (suite *RepositoryTestSuite) TestGetProductsByIdsWithSuccess() {
+	repo := repositories.NewProductRepository(suite.db)
+
+	newProduct := dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	}
+	newId, err := repo.CreateProduct(suite.ctx, newProduct)
+	suite.NoError(err)
+
+	createdProducts, err := repo.GetProductsByIds(suite.ctx, []uint{newId, uint(99)})
+
+	suite.NoError(err)
+	suite.Equal(1, len(createdProducts))
+	suite.Equal(float64(2990), createdProducts[0].Price)
+}
+
 func (suite *RepositoryTestSuite) TestCreateProductWithSuccess() {
 	// ensure that the postgres database is empty
 	var products []model.Product
@@ -87,7 +112,7 @@ func (suite *RepositoryTestSuite) TestCreateProductWithSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -120,7 +145,7 @@ func (suite *RepositoryTestSuite) TestUpdateProductWithSuccess() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product",
 		Description: "New Description Product",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -145,7 +170,7 @@ func (suite *RepositoryTestSuite) TestUpdateProductWithSuccess() {
 		Id:          uint(1),
 		Name:        "Updated Product",
 		Description: "Updated Description Product",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       3990,
 		Images: []dto.ProducImage{
 			{
@@ -167,6 +192,263 @@ func (suite *RepositoryTestSuite) TestUpdateProductWithSuccess() {
 	suite.Equal("Updated Description Product", products[0].Description)
 }
 
+func (suite *RepositoryTestSuite) TestUpdateProductImagesAndComboProductsWithSuccess() {
+	repo := repositories.NewProductRepository(suite.db)
+
+	productIds := []uint{}
+
+	for _, name := range []string{"Burger", "Soda", "Fries"} {
+		newId, err := repo.CreateProduct(suite.ctx, dto.ProductForm{
+			Name:        name,
+			Description: name,
+			Category:    model.CategorySnack,
+			Price:       990,
+			Images: []dto.ProducImage{
+				{
+					ImageUrl: name + "ImageUrl",
+				},
+			},
+		})
+		suite.NoError(err)
+
+		productIds = append(productIds, newId)
+	}
+
+	comboId, err := repo.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "Combo",
+		Description: "Combo",
+		Category:    model.CategoryCombo,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "KeptImageUrl",
+			},
+			{
+				ImageUrl: "RemovedImageUrl",
+			},
+		},
+		ComboProductsIds: &[]uint{productIds[0], productIds[1]},
+	})
+	suite.NoError(err)
+
+	var keptImage model.ProductImage
+	err = suite.db.Connection.Where("image_url = ?", "KeptImageUrl").First(&keptImage).Error
+	suite.NoError(err)
+
+	var keptComboProduct model.ComboProduct
+	err = suite.db.Connection.Where("product_id = ? AND combo_product_id = ?", comboId, productIds[0]).First(&keptComboProduct).Error
+	suite.NoError(err)
+
+	err = repo.UpdateProduct(suite.ctx, dto.ProductForm{
+		Id:          comboId,
+		Name:        "Combo",
+		Description: "Combo",
+		Category:    model.CategoryCombo,
+		Price:       3490,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "KeptImageUrl",
+			},
+			{
+				ImageUrl: "AddedImageUrl",
+			},
+		},
+		ComboProductsIds: &[]uint{productIds[0], productIds[2]},
+	})
+	suite.NoError(err)
+
+	combo, err := repo.GetProductById(suite.ctx, comboId)
+	suite.NoError(err)
+	suite.Equal(float64(3490), combo.Price)
+	suite.Equal([]dto.ProducImage{{ImageUrl: "KeptImageUrl"}, {ImageUrl: "AddedImageUrl"}}, combo.Images)
+	suite.Equal(2, len(*combo.ComboProducts))
+	suite.Equal("Burger", (*combo.ComboProducts)[0].Name)
+	suite.Equal("Fries", (*combo.ComboProducts)[1].Name)
+
+	// the unchanged rows were not written again
+	var images []model.ProductImage
+	err = suite.db.Connection.Unscoped().Where("product_id = ?", comboId).Order("id").Find(&images).Error
+	suite.NoError(err)
+	suite.Equal(2, len(images))
+	suite.Equal(keptImage.ID, images[0].ID)
+	suite.Equal(keptImage.UpdatedAt, images[0].UpdatedAt)
+
+	var comboProducts []model.ComboProduct
+	err = suite.db.Connection.Unscoped().Where("product_id = ?", comboId).Order("id").Find(&comboProducts).Error
+	suite.NoError(err)
+	suite.Equal(2, len(comboProducts))
+	suite.Equal(keptComboProduct.ID, comboProducts[0].ID)
+}
+
+func (suite *RepositoryTestSuite) TestUpdateProductRollbackOnUnknownCategoryError() {
+	repo := repositories.NewProductRepository(suite.db)
+
+	newId, err := repo.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product",
+		Description: "New Description Product",
+		Category:    model.CategorySnack,
+		Price:       2990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	err = repo.UpdateProduct(suite.ctx, dto.ProductForm{
+		Id:          newId,
+		Name:        "Updated Product",
+		Description: "Updated Description Product",
+		Category:    "Bebidas",
+		Price:       3990,
+		Images:      []dto.ProducImage{},
+	})
+
+	var localError *responses.LocalError
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.NOT_FOUND_ERROR, localError.Code)
+
+	product, err := repo.GetProductById(suite.ctx, newId)
+	suite.NoError(err)
+	suite.Equal("New Product", product.Name)
+	suite.Equal(1, len(product.Images))
+}
+
+func (suite *RepositoryTestSuite) TestUpdateProductNotFoundError() {
+	repo := repositories.NewProductRepository(suite.db)
+
+	err := repo.UpdateProduct(suite.ctx, dto.ProductForm{
+		Id:          uint(99),
+		Name:        "Updated Product",
+		Description: "Updated Description Product",
+		Category:    model.CategorySnack,
+		Price:       3990,
+		Images:      []dto.ProducImage{},
+	})
+
+	var localError *responses.LocalError
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.NOT_FOUND_ERROR, localError.Code)
+}
+
+func (suite *RepositoryTestSuite) TestArchiveProductLifecycleSuccess() {
+	repo := repositories.NewProductRepository(suite.db)
+
+	burgerId, err := repo.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "Burger",
+		Description: "Burger",
+		Category:    model.CategorySnack,
+		Price:       990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "BurgerImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	comboId, err := repo.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "Burger Combo",
+		Description: "Burger Combo",
+		Category:    model.CategoryCombo,
+		Price:       1990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "ComboImageUrl",
+			},
+		},
+		ComboProductsIds: &[]uint{burgerId},
+	})
+	suite.NoError(err)
+
+	repoOrder := repositories.NewOrderRespository(suite.db, new(MockCustomerRemoteDataSource))
+
+	orderResponse, err := repoOrder.CreateOrder(suite.ctx, dto.Order{
+		TotalPrice:   990,
+		PaymentID:    "wertr",
+		TicketNumber: 12,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: burgerId,
+			},
+		},
+	}, time.Now().UnixMilli())
+	suite.NoError(err)
+
+	// the combo is still in the menu
+	err = repo.ArchiveProduct(suite.ctx, burgerId)
+
+	var localError *responses.LocalError
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+	suite.Contains(localError.Message, "Burger Combo")
+
+	err = repo.ArchiveProduct(suite.ctx, comboId)
+	suite.NoError(err)
+
+	err = repo.ArchiveProduct(suite.ctx, burgerId)
+	suite.NoError(err)
+
+	snacks, err := repo.GetProductsByCategory(suite.ctx, model.CategorySnack)
+	suite.NoError(err)
+	suite.Empty(snacks)
+
+	burger, err := repo.GetProductById(suite.ctx, burgerId)
+	suite.NoError(err)
+	suite.NotNil(burger.ArchivedAt)
+	suite.Equal(1, len(burger.Images))
+
+	// the order still shows the archived product
+	order, err := repoOrder.GetOrderById(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Equal("Burger", order.OrderProduct[0].ProductName)
+
+	// an archived product can not be put in a combo
+	_, err = repo.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "Other Combo",
+		Description: "Other Combo",
+		Category:    model.CategoryCombo,
+		Price:       1990,
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "ComboImageUrl",
+			},
+		},
+		ComboProductsIds: &[]uint{burgerId},
+	})
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.NOT_FOUND_ERROR, localError.Code)
+
+	// the combo needs its products back first
+	err = repo.RestoreProduct(suite.ctx, comboId)
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+	suite.Contains(localError.Message, "Burger")
+
+	err = repo.RestoreProduct(suite.ctx, burgerId)
+	suite.NoError(err)
+
+	err = repo.RestoreProduct(suite.ctx, comboId)
+	suite.NoError(err)
+
+	combos, err := repo.GetProductsByCategory(suite.ctx, model.CategoryCombo)
+	suite.NoError(err)
+	suite.Equal(1, len(combos))
+	suite.Nil(combos[0].ArchivedAt)
+	suite.Equal(1, len(*combos[0].ComboProducts))
+}
+
+func (suite *RepositoryTestSuite) TestArchiveProductNotFoundError() {
+	repo := repositories.NewProductRepository(suite.db)
+
+	err := repo.ArchiveProduct(suite.ctx, uint(99))
+
+	var localError *responses.LocalError
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.NOT_FOUND_ERROR, localError.Code)
+}
+
 func (suite *RepositoryTestSuite) TestCreateProductWithConflictError() {
 	// ensure that the postgres database is empty
 	var products []model.Product
@@ -179,7 +461,7 @@ func (suite *RepositoryTestSuite) TestCreateProductWithConflictError() {
 	newProduct := dto.ProductForm{
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
-		Category:    "Category",
+		Category:    model.CategorySnack,
 		Price:       2990,
 		Images: []dto.ProducImage{
 			{
@@ -200,16 +482,3 @@ func (suite *RepositoryTestSuite) TestCreateProductWithConflictError() {
 	suite.Equal(true, errors.As(err, &businessError))
 	suite.Equal(responses.DATABASE_CONFLICT_ERROR, businessError.Code)
 }
-
-func (suite *RepositoryTestSuite) TestGetCategoriesWithSuccess() {
-	repo := repositories.NewProductRepository(suite.db)
-
-	categories := repo.GetCategories()
-	suite.Equal(5, len(categories))
-
-	suite.Equal(model.CategoryCombo, categories[0])
-	suite.Equal(model.CategorySnack, categories[1])
-	suite.Equal(model.CategoryBeverage, categories[2])
-	suite.Equal(model.CategoryToppings, categories[3])
-	suite.Equal(model.CategoryDesert, categories[4])
-}
@@ -0,0 +1,24 @@
+package dto
+
+type CategoryForm struct {
+	Id             uint   `json:"id"`
+	Name           string `json:"name" validate:"required"`
+	DisplayOrder   int    `json:"displayOrder" validate:"gte=0"`
+	Active         *bool  `json:"active"`
+	ImageUrl       string `json:"imageUrl"`
+	AllowedInCombo bool   `json:"allowedInCombo"`
+}
+
+type CategoryResponse struct {
+	Id             uint   `json:"id"`
+	Name           string `json:"name"`
+	DisplayOrder   int    `json:"displayOrder"`
+	Active         bool   `json:"active"`
+	ImageUrl       string `json:"imageUrl"`
+	AllowedInCombo bool   `json:"allowedInCombo"`
+	System         bool   `json:"system"`
+}
+
+type CategoryCreationResponse struct {
+	Id uint `json:"id"`
+}
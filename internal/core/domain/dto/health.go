@@ -0,0 +1,6 @@
+package dto
+
+type HealthResponse struct {
+	Status          string            `json:"status"`
+	CircuitBreakers map[string]string `json:"circuitBreakers"`
+}
@@ -4,42 +4,162 @@ import "time"
 
 type Order struct {
 	OrderStatus  string
-	TotalPrice   float64        `json:"totalPrice" validate:"required"`
+	TotalPrice   float64        `json:"totalPrice"`
 	CPF          *string        `json:"cpf"`
 	PaymentID    string         `json:"paymentId" validate:"required"`
-	OrderProduct []OrderProduct `json:"orderProducts" validate:"required"`
+	OrderProduct []OrderProduct `json:"orderProducts" validate:"required,dive"`
 	TicketNumber int
 }
 
+type PayingOrder struct {
+	TotalPrice   float64        `json:"totalPrice"`
+	CPF          *string        `json:"cpf"`
+	OrderProduct []OrderProduct `json:"orderProducts" validate:"required,dive"`
+}
+
+type OrderCancelForm struct {
+	Reason string `json:"reason" validate:"required,oneof=CUSTOMER_REQUEST OUT_OF_STOCK PAYMENT_ISSUE DUPLICATED OTHER"`
+	Note   string `json:"note" validate:"max=255"`
+}
+
+type OrderPriorityForm struct {
+	Priority int `json:"priority" validate:"gte=0"`
+}
+
+type OrderPaymentForm struct {
+	PaymentID string `json:"paymentId" validate:"required"`
+}
+
 type QRCodeOrder struct {
-	OrderStatus  string
-	TotalPrice   float64        `json:"totalPrice" validate:"required"`
-	CustomerID   *uint          `json:"customerId"`
-	OrderProduct []OrderProduct `json:"orderProducts" validate:"required"`
-	TicketNumber int
-	PaymentID    uint
+	TotalPrice   float64        `json:"totalPrice"`
+	CPF          *string        `json:"cpf"`
+	OrderProduct []OrderProduct `json:"orderProducts" validate:"required,dive"`
+}
+
+type QRCodeOrderResponse struct {
+	OrderId     uint      `json:"orderId"`
+	PaymentID   string    `json:"paymentId"`
+	TotalPrice  float64   `json:"totalPrice"`
+	QRCodeData  string    `json:"qrCodeData"`
+	QRCodeImage []byte    `json:"qrCodeImage"`
+	ExpiresAt   time.Time `json:"expiresAt"`
 }
 
 type OrderProduct struct {
+	ProductID    uint                `json:"productId" validate:"required"`
+	ProductPrice float64             `json:"productPrice"`
+	Quantity     int                 `json:"quantity" validate:"min=0,max=99"`
+	Observations string              `json:"observations" validate:"max=255"`
+	AddOns       []OrderProductAddOn `json:"addOns" validate:"dive"`
+	ComboChoices []ComboChoice       `json:"comboChoices" validate:"dive"`
+}
+
+// ComboChoice is the product picked for a combo slot. Fixed slots do not need to be sent
+type ComboChoice struct {
+	SlotID    uint `json:"slotId" validate:"required"`
+	ProductID uint `json:"productId" validate:"required"`
+}
+
+type OrderProductAddOn struct {
 	ProductID    uint    `json:"productId" validate:"required"`
-	ProductPrice float64 `json:"productPrice" validate:"required"`
+	ProductPrice float64 `json:"productPrice"`
+}
+
+type OrderStatusForm struct {
+	Status string `json:"status" validate:"required"`
 }
 
 type OrderResponse struct {
 	OrderId        uint                   `json:"orderId"`
 	OrderDate      time.Time              `json:"orderDate"`
+	UpdatedAt      time.Time              `json:"updatedAt"`
 	PreparingAt    *time.Time             `json:"preparingAt"`
 	DoneAt         *time.Time             `json:"doneAt"`
 	DeliveredAt    *time.Time             `json:"deliveredAt"`
 	NotDeliveredAt *time.Time             `json:"notDeliveredAt"`
+	CanceledAt     *time.Time             `json:"canceledAt"`
+	CancelReason   string                 `json:"cancelReason"`
+	CancelNote     string                 `json:"cancelNote"`
 	TicketNumber   int                    `json:"ticketNumber"`
+	Priority       int                    `json:"priority"`
 	CustomerName   *string                `json:"customerName"`
 	OrderStatus    string                 `json:"orderStatus"`
+	PaymentStatus  string                 `json:"paymentStatus"`
+	TotalPrice     float64                `json:"totalPrice"`
+	ItemsQuantity  int                    `json:"itemsQuantity"`
 	OrderProduct   []OrderProductResponse `json:"orderProducts"`
+	PaymentID      string                 `json:"paymentId,omitempty"`
 }
 
 type OrderProductResponse struct {
+	ProductID    uint                        `json:"id"`
+	ProductName  string                      `json:"name"`
+	Description  string                      `json:"description"`
+	ProductPrice float64                     `json:"price"`
+	Quantity     int                         `json:"quantity"`
+	Observations string                      `json:"observations"`
+	AddOns       []OrderProductAddOnResponse `json:"addOns"`
+	ComboItems   []OrderProductComboItem     `json:"comboItems"`
+}
+
+// OrderProductComboItem is a product of a combo line, the kitchen prepares the combo from them
+type OrderProductComboItem struct {
 	ProductID   uint   `json:"id"`
 	ProductName string `json:"name"`
-	Description string `json:"description"`
+}
+
+type OrderProductAddOnResponse struct {
+	ProductID    uint    `json:"id"`
+	ProductName  string  `json:"name"`
+	ProductPrice float64 `json:"price"`
+}
+
+type OrderStatusEventResponse struct {
+	PreviousStatus string    `json:"previousStatus"`
+	NewStatus      string    `json:"newStatus"`
+	Actor          string    `json:"actor"`
+	RequestID      string    `json:"requestId"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+const (
+	OrderSortOrderDate  = "orderDate"
+	OrderSortUpdatedAt  = "updatedAt"
+	OrderSortTotalPrice = "totalPrice"
+)
+
+// OrderSearch filters the order listing. From is inclusive and To is exclusive.
+// Cursor is the NextCursor of the previous page
+type OrderSearch struct {
+	Statuses     []string
+	From         *time.Time
+	To           *time.Time
+	CPF          *string
+	TicketNumber *int
+	ProductID    *uint
+	SortBy       string
+	Descending   bool
+	Limit        int
+	Cursor       string
+}
+
+// OrderCursor is the position of the last order of a page. It keeps the sort it
+// was created with, so it can not be used to page through a different sort
+type OrderCursor struct {
+	SortBy     string    `json:"sortBy"`
+	Descending bool      `json:"descending"`
+	ID         uint      `json:"id"`
+	OrderDate  time.Time `json:"orderDate"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+	TotalPrice float64   `json:"totalPrice"`
+}
+
+type OrderSearchPage struct {
+	Orders []OrderResponse
+	Next   *OrderCursor
+}
+
+type OrderSearchResponse struct {
+	Orders     []OrderResponse `json:"orders"`
+	NextCursor *string         `json:"nextCursor"`
 }
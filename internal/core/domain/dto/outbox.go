@@ -0,0 +1,8 @@
+package dto
+
+import "github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+
+type OutboxEvent struct {
+	Attempts int
+	Event    events.DomainEvent
+}
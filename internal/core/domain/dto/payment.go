@@ -13,3 +13,8 @@ type PaymentResponse struct {
 	PaymentGatewayId string    `json:"paymentGatewayId"`
 	PaymentDate      time.Time `json:"paymentDate"`
 }
+
+type PaymentNotification struct {
+	PaymentID string `json:"paymentId" validate:"required"`
+	Status    string `json:"status" validate:"required"`
+}
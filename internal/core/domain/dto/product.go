@@ -1,5 +1,9 @@
 package dto
 
+import "time"
+
+// ProductForm creates or updates a product. An update of a combo without comboProductsIds
+// keeps the products, or the slots, the combo already has
 type ProductForm struct {
 	Id               uint          `json:"id"`
 	Name             string        `json:"name" validate:"required"`
@@ -10,13 +14,26 @@ type ProductForm struct {
 	ComboProductsIds *[]uint       `json:"comboProductsIds"`
 }
 
+// ProductPatchForm changes only the fields sent. Images and comboProductsIds replace the whole list
+// when they are sent, an empty comboProductsIds removes every product of the combo
+type ProductPatchForm struct {
+	Name             *string        `json:"name" validate:"omitempty,min=1"`
+	Description      *string        `json:"description" validate:"omitempty,min=1"`
+	Category         *string        `json:"category" validate:"omitempty,min=1"`
+	Price            *float64       `json:"price" validate:"omitempty,gt=0"`
+	Images           *[]ProducImage `json:"images"`
+	ComboProductsIds *[]uint        `json:"comboProductsIds"`
+}
+
 type ProductResponse struct {
 	Id            uint               `json:"id"`
 	Name          string             `json:"name" validate:"required"`
 	Description   string             `json:"description" validate:"required"`
+	CategoryId    uint               `json:"categoryId"`
 	Category      string             `json:"category" validate:"required"`
 	Price         float64            `json:"price" validate:"required"`
 	Images        []ProducImage      `json:"images" validate:"required"`
+	ArchivedAt    *time.Time         `json:"archivedAt"`
 	ComboProducts *[]ProductResponse `json:"comboProducts"`
 }
 
@@ -28,18 +45,42 @@ type ProducImage struct {
 	ImageUrl string `json:"imageUrl" validate:"required"`
 }
 
+// ComboForm creates or updates a combo. A fixed combo costs Price, a discount combo costs
+// the sum of the chosen products with the Discount percentage off
 type ComboForm struct {
-	Id          uint    `json:"id"`
-	Name        string  `json:"name" validate:"required"`
-	Description string  `json:"description" validate:"required"`
-	Price       float64 `json:"price" validate:"required"`
-	Products    []uint  `json:"products" validate:"required"`
+	Id          uint            `json:"id"`
+	Name        string          `json:"name" validate:"required"`
+	Description string          `json:"description" validate:"required"`
+	Images      []ProducImage   `json:"images" validate:"required"`
+	Pricing     string          `json:"pricing" validate:"required,oneof=fixed discount"`
+	Price       float64         `json:"price" validate:"required_if=Pricing fixed,gte=0"`
+	Discount    float64         `json:"discount" validate:"required_if=Pricing discount,gte=0,lt=100"`
+	Slots       []ComboSlotForm `json:"slots" validate:"required,min=1,dive"`
+}
+
+// ComboSlotForm is one item of the combo. The customer picks one of the ProductIds, or any
+// product of the Category. A slot with a single product is fixed
+type ComboSlotForm struct {
+	Name       string `json:"name" validate:"required"`
+	ProductIds []uint `json:"productIds" validate:"required_without=Category,excluded_with=Category"`
+	Category   string `json:"category" validate:"required_without=ProductIds"`
 }
 
 type Combo struct {
 	Id          uint          `json:"id"`
 	Name        string        `json:"name"`
 	Description string        `json:"description"`
+	Pricing     string        `json:"pricing"`
 	Price       float64       `json:"price"`
-	Products    []ProductForm `json:"products"`
+	Discount    float64       `json:"discount"`
+	Images      []ProducImage `json:"images"`
+	ArchivedAt  *time.Time    `json:"archivedAt"`
+	Slots       []ComboSlot   `json:"slots"`
+}
+
+type ComboSlot struct {
+	Id       uint              `json:"id"`
+	Name     string            `json:"name"`
+	Category string            `json:"category"`
+	Options  []ProductResponse `json:"options"`
 }
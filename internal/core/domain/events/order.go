@@ -0,0 +1,148 @@
+package events
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	OrderCreated       = "order.created"
+	OrderStatusChanged = "order.status_changed"
+	OrderCanceled      = "order.canceled"
+
+	subscriberBuffer = 64
+)
+
+// OrderEvent is pushed to the kitchen and customer panels every time an order
+// is created or changes status. ID is given by the bus, unless the event was
+// already numbered by the replica that sent it
+type OrderEvent struct {
+	ID             uint64    `json:"id"`
+	Type           string    `json:"type"`
+	OrderID        uint      `json:"orderId"`
+	PreviousStatus string    `json:"previousStatus"`
+	OrderStatus    string    `json:"orderStatus"`
+	OccurredAt     time.Time `json:"occurredAt"`
+}
+
+type OrderEventPublisher interface {
+	Publish(event OrderEvent)
+}
+
+// OrderEventBus delivers the order events to the subscribers of this process.
+// The last events are kept so clients can resume after reconnecting
+type OrderEventBus struct {
+	mu          sync.Mutex
+	lastID      uint64
+	history     []OrderEvent
+	historySize int
+	subscribers map[*Subscription]struct{}
+}
+
+type Subscription struct {
+	events chan OrderEvent
+}
+
+func NewOrderEventBus(historySize int) *OrderEventBus {
+	return &OrderEventBus{
+		historySize: historySize,
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Events is closed when the subscriber can not keep up with the bus. The client
+// should reconnect with the last event ID it got to get the missing events
+func (subscription *Subscription) Events() <-chan OrderEvent {
+	return subscription.events
+}
+
+func (bus *OrderEventBus) Publish(event OrderEvent) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	if event.ID == 0 {
+		event.ID = bus.lastID + 1
+	}
+
+	bus.lastID = max(bus.lastID, event.ID)
+
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	// the replicas number the events, so one can arrive after a newer one. The history
+	// is kept sorted by ID for the clients resuming the stream
+	position, found := slices.BinarySearchFunc(bus.history, event.ID, func(kept OrderEvent, id uint64) int {
+		return cmp.Compare(kept.ID, id)
+	})
+
+	if found {
+		return
+	}
+
+	bus.history = slices.Insert(bus.history, position, event)
+
+	if len(bus.history) > bus.historySize {
+		bus.history = bus.history[len(bus.history)-bus.historySize:]
+	}
+
+	for subscription := range bus.subscribers {
+		select {
+		case subscription.events <- event:
+		default:
+			delete(bus.subscribers, subscription)
+			close(subscription.events)
+		}
+	}
+}
+
+// Subscribe returns the events published after lastEventID that are still in the history.
+// complete is false when some of them were already dropped from the history, never reached
+// this replica, or the ID comes from before a restart, so the client must reload the orders
+func (bus *OrderEventBus) Subscribe(lastEventID uint64) (subscription *Subscription, missed []OrderEvent, complete bool) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	subscription = &Subscription{
+		events: make(chan OrderEvent, subscriberBuffer),
+	}
+	bus.subscribers[subscription] = struct{}{}
+
+	missed = []OrderEvent{}
+
+	if lastEventID == 0 {
+		return subscription, missed, true
+	}
+
+	if lastEventID > bus.lastID {
+		return subscription, missed, false
+	}
+
+	// the IDs have no gaps, so a missing one is an event the client would never get
+	complete = true
+	next := lastEventID + 1
+
+	for _, event := range bus.history {
+		if event.ID <= lastEventID {
+			continue
+		}
+
+		complete = complete && event.ID == next
+		next = event.ID + 1
+		missed = append(missed, event)
+	}
+
+	return subscription, missed, complete && next == bus.lastID+1
+}
+
+func (bus *OrderEventBus) Unsubscribe(subscription *Subscription) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	if _, ok := bus.subscribers[subscription]; ok {
+		delete(bus.subscribers, subscription)
+		close(subscription.events)
+	}
+}
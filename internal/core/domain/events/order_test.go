@@ -0,0 +1,191 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+)
+
+func TestOrderEventBus(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got events when subscribed before publishing", func(t *testing.T) {
+		t.Parallel()
+
+		bus := events.NewOrderEventBus(10)
+
+		subscription, missed, complete := bus.Subscribe(0)
+
+		assert.Empty(t, missed)
+		assert.True(t, complete)
+
+		bus.Publish(events.OrderEvent{Type: events.OrderCreated, OrderID: 1, OrderStatus: "Criado"})
+		bus.Publish(events.OrderEvent{Type: events.OrderStatusChanged, OrderID: 1, PreviousStatus: "Criado", OrderStatus: "Preparando"})
+
+		event := <-subscription.Events()
+		assert.Equal(t, uint64(1), event.ID)
+		assert.Equal(t, events.OrderCreated, event.Type)
+		assert.False(t, event.OccurredAt.IsZero())
+
+		event = <-subscription.Events()
+		assert.Equal(t, uint64(2), event.ID)
+		assert.Equal(t, "Preparando", event.OrderStatus)
+	})
+
+	t.Run("got the event id kept when the event was numbered by another replica", func(t *testing.T) {
+		t.Parallel()
+
+		bus := events.NewOrderEventBus(10)
+
+		subscription, _, _ := bus.Subscribe(0)
+
+		bus.Publish(events.OrderEvent{ID: 40, Type: events.OrderCreated, OrderID: 1})
+		bus.Publish(events.OrderEvent{Type: events.OrderCreated, OrderID: 2})
+
+		event := <-subscription.Events()
+		assert.Equal(t, uint64(40), event.ID)
+
+		event = <-subscription.Events()
+		assert.Equal(t, uint64(41), event.ID)
+
+		_, missed, complete := bus.Subscribe(40)
+		assert.True(t, complete)
+		assert.Equal(t, 1, len(missed))
+	})
+
+	t.Run("got missed events when resuming from the last event id", func(t *testing.T) {
+		t.Parallel()
+
+		bus := events.NewOrderEventBus(10)
+
+		for i := 1; i <= 5; i++ {
+			bus.Publish(events.OrderEvent{Type: events.OrderCreated, OrderID: uint(i)})
+		}
+
+		_, missed, complete := bus.Subscribe(3)
+
+		assert.True(t, complete)
+		assert.Equal(t, 2, len(missed))
+		assert.Equal(t, uint64(4), missed[0].ID)
+		assert.Equal(t, uint64(5), missed[1].ID)
+
+		_, missed, complete = bus.Subscribe(5)
+
+		assert.True(t, complete)
+		assert.Empty(t, missed)
+	})
+
+	t.Run("got incomplete resume when the events left the history", func(t *testing.T) {
+		t.Parallel()
+
+		bus := events.NewOrderEventBus(2)
+
+		for i := 1; i <= 5; i++ {
+			bus.Publish(events.OrderEvent{Type: events.OrderCreated, OrderID: uint(i)})
+		}
+
+		_, missed, complete := bus.Subscribe(1)
+
+		assert.False(t, complete)
+		assert.Equal(t, 2, len(missed))
+
+		_, missed, complete = bus.Subscribe(3)
+
+		assert.True(t, complete)
+		assert.Equal(t, 2, len(missed))
+	})
+
+	t.Run("got missed events in id order when they arrive out of order", func(t *testing.T) {
+		t.Parallel()
+
+		bus := events.NewOrderEventBus(10)
+
+		bus.Publish(events.OrderEvent{ID: 1, Type: events.OrderCreated, OrderID: 1})
+		bus.Publish(events.OrderEvent{ID: 3, Type: events.OrderCreated, OrderID: 3})
+
+		_, missed, complete := bus.Subscribe(1)
+
+		assert.False(t, complete)
+		assert.Equal(t, 1, len(missed))
+
+		bus.Publish(events.OrderEvent{ID: 2, Type: events.OrderCreated, OrderID: 2})
+		bus.Publish(events.OrderEvent{ID: 2, Type: events.OrderCreated, OrderID: 2})
+
+		_, missed, complete = bus.Subscribe(1)
+
+		assert.True(t, complete)
+		assert.Equal(t, 2, len(missed))
+		assert.Equal(t, uint64(2), missed[0].ID)
+		assert.Equal(t, uint64(3), missed[1].ID)
+	})
+
+	t.Run("got incomplete resume when an event never reached the replica", func(t *testing.T) {
+		t.Parallel()
+
+		bus := events.NewOrderEventBus(10)
+
+		bus.Publish(events.OrderEvent{ID: 10, Type: events.OrderCreated, OrderID: 1})
+		bus.Publish(events.OrderEvent{ID: 11, Type: events.OrderCreated, OrderID: 2})
+		bus.Publish(events.OrderEvent{ID: 13, Type: events.OrderCreated, OrderID: 3})
+
+		_, missed, complete := bus.Subscribe(10)
+
+		assert.False(t, complete)
+		assert.Equal(t, 2, len(missed))
+
+		_, missed, complete = bus.Subscribe(13)
+
+		assert.True(t, complete)
+		assert.Empty(t, missed)
+	})
+
+	t.Run("got incomplete resume when the last event id is from before a restart", func(t *testing.T) {
+		t.Parallel()
+
+		bus := events.NewOrderEventBus(10)
+
+		bus.Publish(events.OrderEvent{Type: events.OrderCreated, OrderID: 1})
+
+		_, missed, complete := bus.Subscribe(300)
+
+		assert.False(t, complete)
+		assert.Empty(t, missed)
+	})
+
+	t.Run("got subscription closed when the subscriber is too slow", func(t *testing.T) {
+		t.Parallel()
+
+		bus := events.NewOrderEventBus(10)
+
+		subscription, _, _ := bus.Subscribe(0)
+
+		for i := 0; i < 100; i++ {
+			bus.Publish(events.OrderEvent{Type: events.OrderCreated, OrderID: uint(i)})
+		}
+
+		received := 0
+
+		for range subscription.Events() {
+			received++
+		}
+
+		assert.Less(t, received, 100)
+	})
+
+	t.Run("got subscription closed when unsubscribing", func(t *testing.T) {
+		t.Parallel()
+
+		bus := events.NewOrderEventBus(10)
+
+		subscription, _, _ := bus.Subscribe(0)
+
+		bus.Unsubscribe(subscription)
+		bus.Unsubscribe(subscription)
+
+		bus.Publish(events.OrderEvent{Type: events.OrderCreated, OrderID: 1})
+
+		_, ok := <-subscription.Events()
+		assert.False(t, ok)
+	})
+}
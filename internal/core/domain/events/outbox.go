@@ -0,0 +1,74 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const OrderDeleted = "order.deleted"
+
+var ErrMemoryPublisherFailure = errors.New("memory event publisher failure")
+
+// DomainEvent is what the downstream systems (payments, customer, notifications)
+// receive from the outbox. ID is the outbox row, consumers use it to drop the
+// duplicates of the at-least-once delivery
+type DomainEvent struct {
+	ID             uint      `json:"id"`
+	Type           string    `json:"type"`
+	OrderID        uint      `json:"orderId"`
+	PreviousStatus string    `json:"previousStatus,omitempty"`
+	OrderStatus    string    `json:"orderStatus,omitempty"`
+	TotalPrice     float64   `json:"totalPrice,omitempty"`
+	PaymentID      string    `json:"paymentId,omitempty"`
+	CPF            *string   `json:"cpf,omitempty"`
+	OccurredAt     time.Time `json:"occurredAt"`
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, event DomainEvent) error
+}
+
+// MemoryEventPublisher keeps the published events. It is used in tests and
+// when no downstream system is configured
+type MemoryEventPublisher struct {
+	mu       sync.Mutex
+	events   []DomainEvent
+	failures int
+}
+
+func NewMemoryEventPublisher() *MemoryEventPublisher {
+	return &MemoryEventPublisher{
+		events: []DomainEvent{},
+	}
+}
+
+func (publisher *MemoryEventPublisher) Publish(ctx context.Context, event DomainEvent) error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	if publisher.failures > 0 {
+		publisher.failures--
+		return ErrMemoryPublisherFailure
+	}
+
+	publisher.events = append(publisher.events, event)
+
+	return nil
+}
+
+// FailNext makes the next `times` publishes fail, to simulate a downstream system down
+func (publisher *MemoryEventPublisher) FailNext(times int) {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	publisher.failures = times
+}
+
+func (publisher *MemoryEventPublisher) Events() []DomainEvent {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	return append([]DomainEvent{}, publisher.events...)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+)
+
+type CategoryRepository interface {
+	CreateCategory(ctx context.Context, category dto.CategoryForm) (uint, error)
+	GetCategories(ctx context.Context, onlyActive bool) ([]dto.CategoryResponse, error)
+	GetCategoryById(ctx context.Context, id uint) (dto.CategoryResponse, error)
+	GetCategoryByName(ctx context.Context, name string) (dto.CategoryResponse, error)
+	UpdateCategory(ctx context.Context, category dto.CategoryForm) error
+	DeleteCategory(ctx context.Context, id uint) error
+}
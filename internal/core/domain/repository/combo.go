@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+)
+
+type ComboRepository interface {
+	CreateCombo(ctx context.Context, combo dto.ComboForm) (uint, error)
+	UpdateCombo(ctx context.Context, combo dto.ComboForm) error
+	GetCombos(ctx context.Context) ([]dto.Combo, error)
+	GetComboById(ctx context.Context, id uint) (dto.Combo, error)
+	GetCombosByIds(ctx context.Context, ids []uint) ([]dto.Combo, error)
+}
@@ -2,22 +2,28 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
 )
 
 type OrderRepository interface {
-	CreateOrder(ctx context.Context, order dto.Order) (dto.OrderResponse, error)
+	CreateOrder(ctx context.Context, order dto.Order, ticketDate int64) (dto.OrderResponse, error)
 	CreatePayingOrder(ctx context.Context, order dto.Order) (dto.OrderResponse, error)
-	FinishOrderWithPayment(ctx context.Context, orderID uint, paymentID string) error
+	FinishOrderWithPayment(ctx context.Context, orderID uint, paymentID string, ticketDate int64) error
 	DeleteOrder(ctx context.Context, orderID uint) error
+	DeleteExpiredPayingOrders(ctx context.Context, expiredBefore time.Time) ([]uint, error)
 	GetOrderById(ctx context.Context, orderID uint) (dto.OrderResponse, error)
+	GetOrderByPaymentId(ctx context.Context, paymentID string) (dto.OrderResponse, error)
+	UpdatePaymentStatus(ctx context.Context, orderID uint, paymentStatus string) error
+	RefundPayment(ctx context.Context, paymentID string) error
+	UpdateOrderPriority(ctx context.Context, orderID uint, priority int) error
 	GetOrdersToPrepare(ctx context.Context) ([]dto.OrderResponse, error)
 	GetOrdersToFollow(ctx context.Context) ([]dto.OrderResponse, error)
 	GetOrdersWaitingPayment(ctx context.Context) ([]dto.OrderResponse, error)
-	UpdateToPreparing(ctx context.Context, orderID uint) error
-	UpdateToDone(ctx context.Context, orderID uint) error
-	UpdateToDelivered(ctx context.Context, orderID uint) error
-	UpdateToNotDelivered(ctx context.Context, orderID uint) error
-	GetNextTicketNumber(ctx context.Context, date int64) int
+	SearchOrders(ctx context.Context, search dto.OrderSearch, after *dto.OrderCursor) (dto.OrderSearchPage, error)
+	UpdateOrderStatus(ctx context.Context, orderID uint, transition statemachine.OrderTransition) error
+	CancelOrder(ctx context.Context, orderID uint, transition statemachine.OrderTransition, cancel dto.OrderCancelForm) error
+	GetOrderHistory(ctx context.Context, orderID uint) ([]dto.OrderStatusEventResponse, error)
 }
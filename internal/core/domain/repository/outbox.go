@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+)
+
+type OutboxRepository interface {
+	ClaimOutboxEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]dto.OutboxEvent, error)
+	MarkOutboxEventDelivered(ctx context.Context, eventID uint) error
+	MarkOutboxEventFailed(ctx context.Context, eventID uint, reason string, nextAttemptAt time.Time) error
+	DeleteDeliveredOutboxEvents(ctx context.Context, deliveredBefore time.Time) (int64, error)
+}
@@ -8,9 +8,11 @@ import (
 
 type ProductRepository interface {
 	CreateProduct(ctx context.Context, product dto.ProductForm) (uint, error)
-	GetCategories() []string
 	GetProductsByCategory(ctx context.Context, category string) ([]dto.ProductResponse, error)
 	GetProductById(ctx context.Context, id uint) (dto.ProductResponse, error)
-	DeleteProduct(ctx context.Context, productId uint) error
+	GetProductsByIds(ctx context.Context, ids []uint) ([]dto.ProductResponse, error)
+	ArchiveProduct(ctx context.Context, productId uint) error
+	RestoreProduct(ctx context.Context, productId uint) error
 	UpdateProduct(ctx context.Context, product dto.ProductForm) error
+	HasComboComponents(ctx context.Context, productId uint) (bool, error)
 }
@@ -0,0 +1,165 @@
+package statemachine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+const (
+	RoleSystem  = "system"
+	RoleKitchen = "kitchen"
+	RoleWaiter  = "waiter"
+	RoleManager = "manager"
+)
+
+// staffRoles are the roles a staff token can carry. RoleSystem is only set by the
+// server for the payment notifications and the background jobs
+var staffRoles = []string{RoleKitchen, RoleWaiter, RoleManager}
+
+type roleContextKey struct{}
+
+// OrderTransition is one row of the order status table: the status an order
+// must be in (From), the status it goes to (To), the timestamp column set
+// when the change happens and the roles allowed to trigger it
+type OrderTransition struct {
+	From            string
+	To              string
+	TimestampColumn string
+	Roles           []string
+}
+
+type OrderStateMachine struct {
+	transitions []OrderTransition
+}
+
+// orderTransitions holds every allowed status change. Adding a new status
+// only needs a new entry here
+var orderTransitions = []OrderTransition{
+	{
+		From:  model.OrderStatusPaying,
+		To:    model.OrderStatusCreated,
+		Roles: []string{RoleSystem, RoleManager},
+	},
+	{
+		From:            model.OrderStatusCreated,
+		To:              model.OrderStatusPreparing,
+		TimestampColumn: "preparing_at",
+		Roles:           []string{RoleKitchen, RoleManager},
+	},
+	{
+		From:            model.OrderStatusPreparing,
+		To:              model.OrderStatusDone,
+		TimestampColumn: "done_at",
+		Roles:           []string{RoleKitchen, RoleManager},
+	},
+	{
+		From:            model.OrderStatusDone,
+		To:              model.OrderStatusDelivered,
+		TimestampColumn: "delivered_at",
+		Roles:           []string{RoleWaiter, RoleManager},
+	},
+	{
+		From:            model.OrderStatusDone,
+		To:              model.OrderStatusNotDelivered,
+		TimestampColumn: "not_delivered_at",
+		Roles:           []string{RoleWaiter, RoleManager},
+	},
+	{
+		From:            model.OrderStatusPaying,
+		To:              model.OrderStatusCanceled,
+		TimestampColumn: "canceled_at",
+		Roles:           []string{RoleSystem, RoleWaiter, RoleManager},
+	},
+	{
+		From:            model.OrderStatusCreated,
+		To:              model.OrderStatusCanceled,
+		TimestampColumn: "canceled_at",
+		Roles:           []string{RoleWaiter, RoleManager},
+	},
+	{
+		From:            model.OrderStatusPreparing,
+		To:              model.OrderStatusCanceled,
+		TimestampColumn: "canceled_at",
+		Roles:           []string{RoleManager},
+	},
+}
+
+func NewOrderStateMachine() *OrderStateMachine {
+	return &OrderStateMachine{
+		transitions: orderTransitions,
+	}
+}
+
+// Transition returns the table entry that moves an order from `from` to `to`.
+// Every transition needs one of its roles, an empty role is never allowed
+func (sm *OrderStateMachine) Transition(from, to, role string) (OrderTransition, error) {
+	sources := sm.Sources(to)
+
+	if len(sources) == 0 {
+		return OrderTransition{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("Unknown order status %v", to),
+		}
+	}
+
+	for _, transition := range sm.transitions {
+		if transition.From != from || transition.To != to {
+			continue
+		}
+
+		if role == "" {
+			return OrderTransition{}, &responses.BusinessResponse{
+				StatusCode: http.StatusForbidden,
+				Message:    fmt.Sprintf("A role is needed to move an order to %v status", to),
+			}
+		}
+
+		if !slices.Contains(transition.Roles, role) {
+			return OrderTransition{}, &responses.BusinessResponse{
+				StatusCode: http.StatusForbidden,
+				Message:    fmt.Sprintf("The role %v can not move an order to %v status", role, to),
+			}
+		}
+
+		return transition, nil
+	}
+
+	return OrderTransition{}, &responses.BusinessResponse{
+		StatusCode: http.StatusPreconditionRequired,
+		Message:    fmt.Sprintf("The order must be in %v status", strings.Join(sources, " or ")),
+	}
+}
+
+// Sources returns every status an order can be in to go to `to`
+func (sm *OrderStateMachine) Sources(to string) []string {
+	sources := []string{}
+
+	for _, transition := range sm.transitions {
+		if transition.To == to && !slices.Contains(sources, transition.From) {
+			sources = append(sources, transition.From)
+		}
+	}
+
+	return sources
+}
+
+// WithRole is only called by the server, with the role of an authenticated
+// staff token or RoleSystem, never with a value sent by the client
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleContextKey{}, role)
+}
+
+func IsStaffRole(role string) bool {
+	return slices.Contains(staffRoles, role)
+}
+
+func RoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(roleContextKey{}).(string)
+	return role
+}
@@ -0,0 +1,210 @@
+package statemachine_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestOrderStateMachine(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when moving order from CRIADO to PREPARANDO", func(t *testing.T) {
+		t.Parallel()
+
+		sut := statemachine.NewOrderStateMachine()
+
+		transition, err := sut.Transition(model.OrderStatusCreated, model.OrderStatusPreparing, statemachine.RoleKitchen)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.OrderStatusPreparing, transition.To)
+		assert.Equal(t, "preparing_at", transition.TimestampColumn)
+	})
+
+	t.Run("got success when moving order from FINALIZADO to ENTREGUE and NAO ENTREGUE", func(t *testing.T) {
+		t.Parallel()
+
+		sut := statemachine.NewOrderStateMachine()
+
+		transition, err := sut.Transition(model.OrderStatusDone, model.OrderStatusDelivered, statemachine.RoleWaiter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "delivered_at", transition.TimestampColumn)
+
+		transition, err = sut.Transition(model.OrderStatusDone, model.OrderStatusNotDelivered, statemachine.RoleWaiter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "not_delivered_at", transition.TimestampColumn)
+	})
+
+	t.Run("got error when moving order without role", func(t *testing.T) {
+		t.Parallel()
+
+		sut := statemachine.NewOrderStateMachine()
+
+		_, err := sut.Transition(model.OrderStatusPreparing, model.OrderStatusDone, "")
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusForbidden, businessError.StatusCode)
+	})
+
+	t.Run("got error when confirming payment without system or manager role", func(t *testing.T) {
+		t.Parallel()
+
+		sut := statemachine.NewOrderStateMachine()
+
+		for _, role := range []string{"", statemachine.RoleKitchen, statemachine.RoleWaiter} {
+			_, err := sut.Transition(model.OrderStatusPaying, model.OrderStatusCreated, role)
+
+			assert.Error(t, err)
+
+			var businessError *responses.BusinessResponse
+			assert.Equal(t, true, errors.As(err, &businessError))
+			assert.Equal(t, http.StatusForbidden, businessError.StatusCode)
+		}
+
+		for _, role := range []string{statemachine.RoleSystem, statemachine.RoleManager} {
+			transition, err := sut.Transition(model.OrderStatusPaying, model.OrderStatusCreated, role)
+
+			assert.NoError(t, err)
+			assert.Equal(t, model.OrderStatusCreated, transition.To)
+		}
+	})
+
+	t.Run("got error when moving order from a status not allowed", func(t *testing.T) {
+		t.Parallel()
+
+		sut := statemachine.NewOrderStateMachine()
+
+		_, err := sut.Transition(model.OrderStatusCreated, model.OrderStatusDone, "")
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusPreconditionRequired, businessError.StatusCode)
+		assert.Equal(t, "The order must be in Preparando status", businessError.Message)
+	})
+
+	t.Run("got error when moving order with a role not allowed", func(t *testing.T) {
+		t.Parallel()
+
+		sut := statemachine.NewOrderStateMachine()
+
+		_, err := sut.Transition(model.OrderStatusDone, model.OrderStatusDelivered, statemachine.RoleKitchen)
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusForbidden, businessError.StatusCode)
+	})
+
+	t.Run("got error when moving order to an unknown status", func(t *testing.T) {
+		t.Parallel()
+
+		sut := statemachine.NewOrderStateMachine()
+
+		_, err := sut.Transition(model.OrderStatusCreated, "Unknown", "")
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got success when cancelling order in EM PAGAMENTO and CRIADO", func(t *testing.T) {
+		t.Parallel()
+
+		sut := statemachine.NewOrderStateMachine()
+
+		transition, err := sut.Transition(model.OrderStatusPaying, model.OrderStatusCanceled, statemachine.RoleSystem)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "canceled_at", transition.TimestampColumn)
+
+		transition, err = sut.Transition(model.OrderStatusCreated, model.OrderStatusCanceled, statemachine.RoleWaiter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.OrderStatusCreated, transition.From)
+	})
+
+	t.Run("got success when manager cancels order in PREPARANDO", func(t *testing.T) {
+		t.Parallel()
+
+		sut := statemachine.NewOrderStateMachine()
+
+		transition, err := sut.Transition(model.OrderStatusPreparing, model.OrderStatusCanceled, statemachine.RoleManager)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.OrderStatusCanceled, transition.To)
+	})
+
+	t.Run("got error when cancelling order in PREPARANDO without manager role", func(t *testing.T) {
+		t.Parallel()
+
+		sut := statemachine.NewOrderStateMachine()
+
+		for _, role := range []string{"", statemachine.RoleKitchen, statemachine.RoleWaiter} {
+			_, err := sut.Transition(model.OrderStatusPreparing, model.OrderStatusCanceled, role)
+
+			assert.Error(t, err)
+
+			var businessError *responses.BusinessResponse
+			assert.Equal(t, true, errors.As(err, &businessError))
+			assert.Equal(t, http.StatusForbidden, businessError.StatusCode)
+		}
+	})
+
+	t.Run("got error when cancelling order already FINALIZADO", func(t *testing.T) {
+		t.Parallel()
+
+		sut := statemachine.NewOrderStateMachine()
+
+		_, err := sut.Transition(model.OrderStatusDone, model.OrderStatusCanceled, statemachine.RoleManager)
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusPreconditionRequired, businessError.StatusCode)
+		assert.Equal(t, "The order must be in Em pagamento or Criado or Preparando status", businessError.Message)
+	})
+
+	t.Run("got sources when getting the statuses that lead to a status", func(t *testing.T) {
+		t.Parallel()
+
+		sut := statemachine.NewOrderStateMachine()
+
+		assert.Equal(t, []string{model.OrderStatusDone}, sut.Sources(model.OrderStatusDelivered))
+		assert.Empty(t, sut.Sources(model.OrderStatusPaying))
+	})
+
+	t.Run("got role when putting it in the context", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
+
+		assert.Equal(t, statemachine.RoleManager, statemachine.RoleFromContext(ctx))
+		assert.Equal(t, "", statemachine.RoleFromContext(context.TODO()))
+	})
+
+	t.Run("got only kitchen, waiter and manager as staff roles", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, true, statemachine.IsStaffRole(statemachine.RoleKitchen))
+		assert.Equal(t, true, statemachine.IsStaffRole(statemachine.RoleManager))
+		assert.Equal(t, false, statemachine.IsStaffRole(statemachine.RoleSystem))
+		assert.Equal(t, false, statemachine.IsStaffRole(""))
+	})
+}
@@ -0,0 +1,153 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+type CreateCategoryUseCase interface {
+	Execute(ctx context.Context, category dto.CategoryForm) (uint, error)
+}
+
+type CreateCategoryUseCaseImpl struct {
+	repository repository.CategoryRepository
+}
+
+type GetCategoriesUseCase interface {
+	Execute(ctx context.Context, onlyActive bool) ([]dto.CategoryResponse, error)
+}
+
+type GetCategoriesUseCaseImpl struct {
+	repository repository.CategoryRepository
+}
+
+type GetCategoryByIdUseCase interface {
+	Execute(ctx context.Context, id uint) (dto.CategoryResponse, error)
+}
+
+type GetCategoryByIdUseCaseImpl struct {
+	repository repository.CategoryRepository
+}
+
+type UpdateCategoryUseCase interface {
+	Execute(ctx context.Context, category dto.CategoryForm) error
+}
+
+type UpdateCategoryUseCaseImpl struct {
+	repository repository.CategoryRepository
+}
+
+type DeleteCategoryUseCase interface {
+	Execute(ctx context.Context, id uint) error
+}
+
+type DeleteCategoryUseCaseImpl struct {
+	repository repository.CategoryRepository
+}
+
+func NewCreateCategoryUseCase(repository repository.CategoryRepository) CreateCategoryUseCase {
+	return &CreateCategoryUseCaseImpl{
+		repository: repository,
+	}
+}
+
+func NewGetCategoriesUseCase(repository repository.CategoryRepository) GetCategoriesUseCase {
+	return &GetCategoriesUseCaseImpl{
+		repository: repository,
+	}
+}
+
+func NewGetCategoryByIdUseCase(repository repository.CategoryRepository) GetCategoryByIdUseCase {
+	return &GetCategoryByIdUseCaseImpl{
+		repository: repository,
+	}
+}
+
+func NewUpdateCategoryUseCase(repository repository.CategoryRepository) UpdateCategoryUseCase {
+	return &UpdateCategoryUseCaseImpl{
+		repository: repository,
+	}
+}
+
+func NewDeleteCategoryUseCase(repository repository.CategoryRepository) DeleteCategoryUseCase {
+	return &DeleteCategoryUseCaseImpl{
+		repository: repository,
+	}
+}
+
+func (service *CreateCategoryUseCaseImpl) Execute(ctx context.Context, category dto.CategoryForm) (uint, error) {
+	categoryId, err := service.repository.CreateCategory(ctx, category)
+
+	if err != nil {
+		return 0, responses.GetResponseError(err, "CategoryService")
+	}
+
+	return categoryId, nil
+}
+
+func (service *GetCategoriesUseCaseImpl) Execute(ctx context.Context, onlyActive bool) ([]dto.CategoryResponse, error) {
+	categories, err := service.repository.GetCategories(ctx, onlyActive)
+
+	if err != nil {
+		return []dto.CategoryResponse{}, responses.GetResponseError(err, "CategoryService")
+	}
+
+	return categories, nil
+}
+
+func (service *GetCategoryByIdUseCaseImpl) Execute(ctx context.Context, id uint) (dto.CategoryResponse, error) {
+	category, err := service.repository.GetCategoryById(ctx, id)
+
+	if err != nil {
+		return dto.CategoryResponse{}, responses.GetResponseError(err, "CategoryService")
+	}
+
+	return category, nil
+}
+
+func (service *UpdateCategoryUseCaseImpl) Execute(ctx context.Context, category dto.CategoryForm) error {
+	err := service.repository.UpdateCategory(ctx, category)
+
+	if err != nil {
+		return responses.GetResponseError(err, "CategoryService")
+	}
+
+	return nil
+}
+
+func (service *DeleteCategoryUseCaseImpl) Execute(ctx context.Context, id uint) error {
+	err := service.repository.DeleteCategory(ctx, id)
+
+	if err != nil {
+		return responses.GetResponseError(err, "CategoryService")
+	}
+
+	return nil
+}
+
+// validateCategoryExists answers an unknown category as a bad request, the admin sent a name
+// that is not in the categories list
+func validateCategoryExists(ctx context.Context, categoryRepository repository.CategoryRepository, name string) error {
+	_, err := categoryRepository.GetCategoryByName(ctx, name)
+
+	var localError *responses.LocalError
+
+	if errors.As(err, &localError) && localError.Code == responses.NOT_FOUND_ERROR {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("Category %v does not exist", name),
+		}
+	}
+
+	if err != nil {
+		return responses.GetResponseError(err, "ProductService")
+	}
+
+	return nil
+}
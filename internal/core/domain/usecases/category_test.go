@@ -0,0 +1,190 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestCategoryUseCase(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when creating category in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCategoryRepository)
+		sut := NewCreateCategoryUseCase(mockRepo)
+
+		ctx := context.TODO()
+		category := dto.CategoryForm{Name: "Saladas", DisplayOrder: 6}
+
+		mockRepo.On("CreateCategory", ctx, category).Return(uint(6), nil)
+
+		response, err := sut.Execute(ctx, category)
+
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(6), response)
+	})
+
+	t.Run("got conflict when creating category with a name in use in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCategoryRepository)
+		sut := NewCreateCategoryUseCase(mockRepo)
+
+		ctx := context.TODO()
+		category := dto.CategoryForm{Name: "Bebida"}
+
+		mockRepo.On("CreateCategory", ctx, category).Return(uint(0), &responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: "duplicate key value violates unique constraint",
+		})
+
+		response, err := sut.Execute(ctx, category)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
+	})
+
+	t.Run("got success when getting the menu categories in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCategoryRepository)
+		sut := NewGetCategoriesUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetCategories", ctx, true).Return([]dto.CategoryResponse{
+			{Id: 1, Name: "Combo", DisplayOrder: 1, Active: true},
+			{Id: 2, Name: "Lanche", DisplayOrder: 2, Active: true},
+		}, nil)
+
+		response, err := sut.Execute(ctx, true)
+
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(response))
+		assert.Equal(t, "Combo", response[0].Name)
+	})
+
+	t.Run("got error when getting categories in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCategoryRepository)
+		sut := NewGetCategoriesUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetCategories", ctx, false).Return(nil, &responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "service unavailable",
+		})
+
+		response, err := sut.Execute(ctx, false)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+	})
+
+	t.Run("got success when getting category by id in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCategoryRepository)
+		sut := NewGetCategoryByIdUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetCategoryById", ctx, uint(2)).Return(dto.CategoryResponse{Id: 2, Name: "Lanche"}, nil)
+
+		response, err := sut.Execute(ctx, uint(2))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Lanche", response.Name)
+	})
+
+	t.Run("got not found when getting unknown category by id in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCategoryRepository)
+		sut := NewGetCategoryByIdUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetCategoryById", ctx, uint(99)).Return(dto.CategoryResponse{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "record not found",
+		})
+
+		_, err := sut.Execute(ctx, uint(99))
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
+	t.Run("got success when updating category in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCategoryRepository)
+		sut := NewUpdateCategoryUseCase(mockRepo)
+
+		ctx := context.TODO()
+		active := false
+		category := dto.CategoryForm{Id: 2, Name: "Lanche", Active: &active}
+
+		mockRepo.On("UpdateCategory", ctx, category).Return(nil)
+
+		err := sut.Execute(ctx, category)
+
+		mockRepo.AssertExpectations(t)
+		assert.NoError(t, err)
+	})
+
+	t.Run("got success when deleting category in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCategoryRepository)
+		sut := NewDeleteCategoryUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("DeleteCategory", ctx, uint(7)).Return(nil)
+
+		err := sut.Execute(ctx, uint(7))
+
+		mockRepo.AssertExpectations(t)
+		assert.NoError(t, err)
+	})
+
+	t.Run("got conflict when deleting category with products in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCategoryRepository)
+		sut := NewDeleteCategoryUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("DeleteCategory", ctx, uint(2)).Return(&responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: "Category has 3 products",
+		})
+
+		err := sut.Execute(ctx, uint(2))
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
+	})
+}
@@ -0,0 +1,243 @@
+package usecases
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+type CreateComboUseCase interface {
+	Execute(ctx context.Context, combo dto.ComboForm) (uint, error)
+}
+
+type CreateComboUseCaseImpl struct {
+	repository         repository.ComboRepository
+	productRepository  repository.ProductRepository
+	categoryRepository repository.CategoryRepository
+}
+
+type UpdateComboUseCase interface {
+	Execute(ctx context.Context, combo dto.ComboForm) error
+}
+
+type UpdateComboUseCaseImpl struct {
+	repository         repository.ComboRepository
+	productRepository  repository.ProductRepository
+	categoryRepository repository.CategoryRepository
+}
+
+type GetCombosUseCase interface {
+	Execute(ctx context.Context) ([]dto.Combo, error)
+}
+
+type GetCombosUseCaseImpl struct {
+	repository repository.ComboRepository
+}
+
+type GetComboByIdUseCase interface {
+	Execute(ctx context.Context, id uint) (dto.Combo, error)
+}
+
+type GetComboByIdUseCaseImpl struct {
+	repository repository.ComboRepository
+}
+
+func NewCreateComboUseCase(
+	repository repository.ComboRepository,
+	productRepository repository.ProductRepository,
+	categoryRepository repository.CategoryRepository,
+) CreateComboUseCase {
+	return &CreateComboUseCaseImpl{
+		repository:         repository,
+		productRepository:  productRepository,
+		categoryRepository: categoryRepository,
+	}
+}
+
+func NewUpdateComboUseCase(
+	repository repository.ComboRepository,
+	productRepository repository.ProductRepository,
+	categoryRepository repository.CategoryRepository,
+) UpdateComboUseCase {
+	return &UpdateComboUseCaseImpl{
+		repository:         repository,
+		productRepository:  productRepository,
+		categoryRepository: categoryRepository,
+	}
+}
+
+func NewGetCombosUseCase(repository repository.ComboRepository) GetCombosUseCase {
+	return &GetCombosUseCaseImpl{
+		repository: repository,
+	}
+}
+
+func NewGetComboByIdUseCase(repository repository.ComboRepository) GetComboByIdUseCase {
+	return &GetComboByIdUseCaseImpl{
+		repository: repository,
+	}
+}
+
+func (service *CreateComboUseCaseImpl) Execute(ctx context.Context, combo dto.ComboForm) (uint, error) {
+	price, err := comboMenuPrice(ctx, service.productRepository, service.categoryRepository, combo)
+
+	if err != nil {
+		return 0, err
+	}
+
+	combo.Price = price
+	comboId, err := service.repository.CreateCombo(ctx, combo)
+
+	if err != nil {
+		return 0, responses.GetResponseError(err, "ComboService")
+	}
+
+	return comboId, nil
+}
+
+func (service *UpdateComboUseCaseImpl) Execute(ctx context.Context, combo dto.ComboForm) error {
+	price, err := comboMenuPrice(ctx, service.productRepository, service.categoryRepository, combo)
+
+	if err != nil {
+		return err
+	}
+
+	combo.Price = price
+	err = service.repository.UpdateCombo(ctx, combo)
+
+	if err != nil {
+		return responses.GetResponseError(err, "ComboService")
+	}
+
+	return nil
+}
+
+func (service *GetCombosUseCaseImpl) Execute(ctx context.Context) ([]dto.Combo, error) {
+	combos, err := service.repository.GetCombos(ctx)
+
+	if err != nil {
+		return []dto.Combo{}, responses.GetResponseError(err, "ComboService")
+	}
+
+	return combos, nil
+}
+
+func (service *GetComboByIdUseCaseImpl) Execute(ctx context.Context, id uint) (dto.Combo, error) {
+	combo, err := service.repository.GetComboById(ctx, id)
+
+	if err != nil {
+		return dto.Combo{}, responses.GetResponseError(err, "ComboService")
+	}
+
+	return combo, nil
+}
+
+// comboMenuPrice checks that every slot has products of categories allowed in combo to choose and
+// answers the price shown in the menu. A discount combo shows the price with the cheapest
+// option of each slot when it is saved, the order pays the options chosen
+func comboMenuPrice(
+	ctx context.Context,
+	productRepository repository.ProductRepository,
+	categoryRepository repository.CategoryRepository,
+	combo dto.ComboForm,
+) (float64, error) {
+	categories, err := categoryRepository.GetCategories(ctx, false)
+
+	if err != nil {
+		return 0, responses.GetResponseError(err, "ComboService")
+	}
+
+	allowedInCombo := map[string]bool{}
+
+	for _, category := range categories {
+		allowedInCombo[category.Name] = category.AllowedInCombo
+	}
+
+	ids := []uint{}
+
+	for _, slot := range combo.Slots {
+		ids = append(ids, slot.ProductIds...)
+	}
+
+	productsById := map[uint]dto.ProductResponse{}
+
+	if len(ids) > 0 {
+		products, err := productRepository.GetProductsByIds(ctx, ids)
+
+		if err != nil {
+			return 0, responses.GetResponseError(err, "ComboService")
+		}
+
+		for _, product := range products {
+			productsById[product.Id] = product
+		}
+	}
+
+	total := 0.0
+
+	for _, slot := range combo.Slots {
+		options := []dto.ProductResponse{}
+
+		if slot.Category != "" {
+			if !allowedInCombo[slot.Category] {
+				return 0, comboBadRequestError(fmt.Sprintf("Category %v can not be in a combo", slot.Category))
+			}
+
+			products, err := productRepository.GetProductsByCategory(ctx, slot.Category)
+
+			if err != nil {
+				return 0, responses.GetResponseError(err, "ComboService")
+			}
+
+			options = append(options, products...)
+		}
+
+		for _, id := range slot.ProductIds {
+			product, ok := productsById[id]
+
+			if !ok {
+				return 0, comboBadRequestError(fmt.Sprintf("Product %v does not exist", id))
+			}
+
+			if product.ArchivedAt != nil {
+				return 0, comboBadRequestError(fmt.Sprintf("Product %v is archived", id))
+			}
+
+			if !allowedInCombo[product.Category] {
+				return 0, comboBadRequestError(fmt.Sprintf("Product %v is in %v category and can not be in a combo", id, product.Category))
+			}
+
+			options = append(options, product)
+		}
+
+		if len(options) == 0 {
+			return 0, comboBadRequestError(fmt.Sprintf("%v has no products to choose", slot.Name))
+		}
+
+		cheapest := slices.MinFunc(options, func(a, b dto.ProductResponse) int {
+			return cmp.Compare(a.Price, b.Price)
+		})
+
+		total += cheapest.Price
+	}
+
+	if combo.Pricing == model.ComboPricingDiscount {
+		return roundPrice(total * (100 - combo.Discount) / 100), nil
+	}
+
+	return combo.Price, nil
+}
+
+func comboBadRequestError(message string) error {
+	return &responses.BusinessResponse{
+		StatusCode: http.StatusBadRequest,
+		Message:    message,
+	}
+}
@@ -0,0 +1,435 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestComboUseCase(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when creating discount combo with the cheapest options price in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		productRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewCreateComboUseCase(mockRepo, productRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategories", ctx, false).Return(comboCategories, nil)
+
+		savedCombo := comboForm
+		savedCombo.Price = 23.4
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1}).Return(comboComponents, nil)
+		productRepo.On("GetProductsByCategory", ctx, "Bebida").Return(comboBeverages, nil)
+		mockRepo.On("CreateCombo", ctx, savedCombo).Return(uint(9), nil)
+
+		response, err := sut.Execute(ctx, comboForm)
+
+		mockRepo.AssertExpectations(t)
+		productRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(9), response)
+	})
+
+	t.Run("got success when creating fixed combo keeping its price in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		productRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewCreateComboUseCase(mockRepo, productRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategories", ctx, false).Return(comboCategories, nil)
+
+		combo := comboForm
+		combo.Pricing = "fixed"
+		combo.Price = 22
+		combo.Discount = 0
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1}).Return(comboComponents, nil)
+		productRepo.On("GetProductsByCategory", ctx, "Bebida").Return(comboBeverages, nil)
+		mockRepo.On("CreateCombo", ctx, combo).Return(uint(9), nil)
+
+		response, err := sut.Execute(ctx, combo)
+
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(9), response)
+	})
+
+	t.Run("got bad request when creating combo with a combo as component in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		productRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewCreateComboUseCase(mockRepo, productRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategories", ctx, false).Return(comboCategories, nil)
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1}).Return([]dto.ProductResponse{
+			{Id: 1, Name: "Combo 1", Category: "Combo", Price: 30},
+		}, nil)
+
+		response, err := sut.Execute(ctx, comboForm)
+
+		mockRepo.AssertNotCalled(t, "CreateCombo")
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got bad request when creating combo with an archived component in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		productRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewCreateComboUseCase(mockRepo, productRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategories", ctx, false).Return(comboCategories, nil)
+		archivedAt := time.Now()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1}).Return([]dto.ProductResponse{
+			{Id: 1, Name: "Burger", Category: "Lanche", Price: 20, ArchivedAt: &archivedAt},
+		}, nil)
+
+		response, err := sut.Execute(ctx, comboForm)
+
+		mockRepo.AssertNotCalled(t, "CreateCombo")
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got bad request when creating combo with an unknown component in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		productRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewCreateComboUseCase(mockRepo, productRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategories", ctx, false).Return(comboCategories, nil)
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1}).Return([]dto.ProductResponse{}, nil)
+
+		response, err := sut.Execute(ctx, comboForm)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got bad request when creating combo with a combo category slot in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		productRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewCreateComboUseCase(mockRepo, productRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategories", ctx, false).Return(comboCategories, nil)
+
+		combo := comboForm
+		combo.Slots = []dto.ComboSlotForm{
+			{Name: "Combo", Category: "Combo"},
+		}
+
+		response, err := sut.Execute(ctx, combo)
+
+		productRepo.AssertNotCalled(t, "GetProductsByCategory")
+		mockRepo.AssertNotCalled(t, "CreateCombo")
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got bad request when creating combo with a category not allowed in combo in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		productRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewCreateComboUseCase(mockRepo, productRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategories", ctx, false).Return([]dto.CategoryResponse{
+			{Id: 2, Name: "Lanche", AllowedInCombo: false},
+			{Id: 3, Name: "Bebida", AllowedInCombo: true},
+		}, nil)
+		productRepo.On("GetProductsByIds", ctx, []uint{1}).Return(comboComponents, nil)
+
+		response, err := sut.Execute(ctx, comboForm)
+
+		mockRepo.AssertNotCalled(t, "CreateCombo")
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got error when creating combo with categories not loaded in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		productRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewCreateComboUseCase(mockRepo, productRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategories", ctx, false).Return([]dto.CategoryResponse{}, &responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "DB Error",
+		})
+
+		response, err := sut.Execute(ctx, comboForm)
+
+		productRepo.AssertNotCalled(t, "GetProductsByIds")
+		mockRepo.AssertNotCalled(t, "CreateCombo")
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+	})
+
+	t.Run("got bad request when creating combo with a category slot without products in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		productRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewCreateComboUseCase(mockRepo, productRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategories", ctx, false).Return(comboCategories, nil)
+
+		combo := comboForm
+		combo.Slots = []dto.ComboSlotForm{
+			{Name: "Sobremesa", Category: "Sobremesa"},
+		}
+
+		productRepo.On("GetProductsByCategory", ctx, "Sobremesa").Return([]dto.ProductResponse{}, nil)
+
+		response, err := sut.Execute(ctx, combo)
+
+		mockRepo.AssertNotCalled(t, "CreateCombo")
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got error when creating combo with products not found in repository in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		productRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewCreateComboUseCase(mockRepo, productRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategories", ctx, false).Return(comboCategories, nil)
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1}).Return(comboComponents, nil)
+		productRepo.On("GetProductsByCategory", ctx, "Bebida").Return(comboBeverages, nil)
+		mockRepo.On("CreateCombo", ctx, mock.Anything).Return(uint(0), &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Combo products [1] not found or archived",
+		})
+
+		response, err := sut.Execute(ctx, comboForm)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
+	t.Run("got success when updating combo in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		productRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewUpdateComboUseCase(mockRepo, productRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategories", ctx, false).Return(comboCategories, nil)
+
+		combo := comboForm
+		combo.Id = 9
+
+		savedCombo := combo
+		savedCombo.Price = 23.4
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1}).Return(comboComponents, nil)
+		productRepo.On("GetProductsByCategory", ctx, "Bebida").Return(comboBeverages, nil)
+		mockRepo.On("UpdateCombo", ctx, savedCombo).Return(nil)
+
+		err := sut.Execute(ctx, combo)
+
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got not found when updating unknown combo in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		productRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewUpdateComboUseCase(mockRepo, productRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategories", ctx, false).Return(comboCategories, nil)
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1}).Return(comboComponents, nil)
+		productRepo.On("GetProductsByCategory", ctx, "Bebida").Return(comboBeverages, nil)
+		mockRepo.On("UpdateCombo", ctx, mock.Anything).Return(&responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Combo not found",
+		})
+
+		err := sut.Execute(ctx, comboForm)
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
+	t.Run("got success when getting combos in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		sut := NewGetCombosUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetCombos", ctx).Return([]dto.Combo{discountCombo}, nil)
+
+		response, err := sut.Execute(ctx)
+
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(response))
+		assert.Equal(t, 2, len(response[0].Slots))
+	})
+
+	t.Run("got error when getting combos in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		sut := NewGetCombosUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetCombos", ctx).Return([]dto.Combo{}, &responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "database error",
+		})
+
+		response, err := sut.Execute(ctx)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+	})
+
+	t.Run("got success when getting combo by id in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		sut := NewGetComboByIdUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetComboById", ctx, uint(9)).Return(discountCombo, nil)
+
+		response, err := sut.Execute(ctx, uint(9))
+
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "discount", response.Pricing)
+		assert.Equal(t, 23.4, response.Price)
+	})
+
+	t.Run("got not found when getting unknown combo by id in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockComboRepository)
+		sut := NewGetComboByIdUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetComboById", ctx, uint(9)).Return(dto.Combo{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Combo not found",
+		})
+
+		response, err := sut.Execute(ctx, uint(9))
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+}
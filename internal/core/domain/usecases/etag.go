@@ -0,0 +1,52 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+type ifMatchContextKey struct{}
+
+// OrderETag is the version of the order, taken from the last time it was updated
+func OrderETag(order dto.OrderResponse) string {
+	return fmt.Sprintf("\"%d\"", order.UpdatedAt.UnixMicro())
+}
+
+// WithIfMatch keeps the If-Match header sent by the client so the status use cases
+// only move the order if it was not changed since the client read it
+func WithIfMatch(ctx context.Context, ifMatch string) context.Context {
+	return context.WithValue(ctx, ifMatchContextKey{}, ifMatch)
+}
+
+func IfMatchFromContext(ctx context.Context) string {
+	ifMatch, _ := ctx.Value(ifMatchContextKey{}).(string)
+	return ifMatch
+}
+
+func checkIfMatch(ctx context.Context, order dto.OrderResponse) error {
+	ifMatch := IfMatchFromContext(ctx)
+
+	if ifMatch == "" {
+		return nil
+	}
+
+	etag := OrderETag(order)
+
+	for _, value := range strings.Split(ifMatch, ",") {
+		value = strings.TrimSpace(value)
+
+		if value == "*" || value == etag {
+			return nil
+		}
+	}
+
+	return &responses.BusinessResponse{
+		StatusCode: http.StatusPreconditionFailed,
+		Message:    fmt.Sprintf("The order was changed. Current version is %v", etag),
+	}
+}
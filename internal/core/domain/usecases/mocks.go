@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/mock"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
 )
 
 var (
@@ -22,6 +23,121 @@ var (
 			},
 		},
 	}
+	payingOrderCreation = dto.PayingOrder{
+		TotalPrice: 12345,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: 1,
+			},
+			{
+				ProductID: 2,
+			},
+		},
+	}
+
+	orderProducts = []dto.ProductResponse{
+		{
+			Id:    uint(1),
+			Name:  "ProductName 1",
+			Price: 12000,
+		},
+		{
+			Id:    uint(2),
+			Name:  "ProductName 2",
+			Price: 345,
+		},
+	}
+
+	addOnProducts = []dto.ProductResponse{
+		{
+			Id:       uint(5),
+			Name:     "Bacon",
+			Category: "Acompanhamento",
+			Price:    4.5,
+		},
+		{
+			Id:       uint(6),
+			Name:     "Soda",
+			Category: "Bebida",
+			Price:    6,
+		},
+	}
+
+	comboComponents = []dto.ProductResponse{
+		{
+			Id:       uint(1),
+			Name:     "Burger",
+			Category: "Lanche",
+			Price:    20,
+		},
+	}
+
+	comboBeverages = []dto.ProductResponse{
+		{
+			Id:       uint(6),
+			Name:     "Soda",
+			Category: "Bebida",
+			Price:    6,
+		},
+		{
+			Id:       uint(7),
+			Name:     "Juice",
+			Category: "Bebida",
+			Price:    8,
+		},
+	}
+
+	comboCategories = []dto.CategoryResponse{
+		{Id: 1, Name: "Combo", AllowedInCombo: false},
+		{Id: 2, Name: "Lanche", AllowedInCombo: true},
+		{Id: 3, Name: "Bebida", AllowedInCombo: true},
+		{Id: 4, Name: "Acompanhamento", AllowedInCombo: true},
+		{Id: 5, Name: "Sobremesa", AllowedInCombo: true},
+	}
+
+	comboForm = dto.ComboForm{
+		Name:        "Combo",
+		Description: "Combo",
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "imageUrl",
+			},
+		},
+		Pricing:  "discount",
+		Discount: 10,
+		Slots: []dto.ComboSlotForm{
+			{
+				Name:       "Lanche",
+				ProductIds: []uint{1},
+			},
+			{
+				Name:     "Bebida",
+				Category: "Bebida",
+			},
+		},
+	}
+
+	discountCombo = dto.Combo{
+		Id:       uint(9),
+		Name:     "Combo",
+		Pricing:  "discount",
+		Price:    23.4,
+		Discount: 10,
+		Slots: []dto.ComboSlot{
+			{
+				Id:      uint(1),
+				Name:    "Lanche",
+				Options: comboComponents,
+			},
+			{
+				Id:       uint(2),
+				Name:     "Bebida",
+				Category: "Bebida",
+				Options:  comboBeverages,
+			},
+		},
+	}
+
 	cpf = "12345678910"
 
 	orderCreationWithCustomer = dto.Order{
@@ -172,6 +288,32 @@ var (
 	}
 )
 
+// pricedOrder returns the order as PriceOrderUseCase fills it using orderProducts prices
+func pricedOrder(order dto.Order) dto.Order {
+	priced := order
+	priced.OrderProduct = []dto.OrderProduct{}
+
+	for _, value := range order.OrderProduct {
+		for _, product := range orderProducts {
+			if product.Id == value.ProductID {
+				value.ProductPrice = product.Price
+			}
+		}
+
+		if value.Quantity == 0 {
+			value.Quantity = 1
+		}
+
+		if value.AddOns == nil {
+			value.AddOns = []dto.OrderProductAddOn{}
+		}
+
+		priced.OrderProduct = append(priced.OrderProduct, value)
+	}
+
+	return priced
+}
+
 type MockOrderRepository struct {
 	mock.Mock
 }
@@ -180,6 +322,14 @@ type MockProductRepository struct {
 	mock.Mock
 }
 
+type MockCategoryRepository struct {
+	mock.Mock
+}
+
+type MockComboRepository struct {
+	mock.Mock
+}
+
 type MockUserAdminRepository struct {
 	mock.Mock
 }
@@ -192,6 +342,10 @@ type MockCustomerRepository struct {
 	mock.Mock
 }
 
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
 func (mock *MockCustomerRepository) GetCustomerByCPF(ctx context.Context, cpf string) (dto.Customer, error) {
 	args := mock.Called(ctx, cpf)
 	err := args.Error(1)
@@ -203,8 +357,8 @@ func (mock *MockCustomerRepository) GetCustomerByCPF(ctx context.Context, cpf st
 	return args.Get(0).(dto.Customer), nil
 }
 
-func (mock *MockOrderRepository) CreateOrder(ctx context.Context, order dto.Order) (dto.OrderResponse, error) {
-	args := mock.Called(ctx, order)
+func (mock *MockOrderRepository) CreateOrder(ctx context.Context, order dto.Order, ticketDate int64) (dto.OrderResponse, error) {
+	args := mock.Called(ctx, order, ticketDate)
 	err := args.Error(1)
 
 	if err != nil {
@@ -236,8 +390,19 @@ func (mock *MockOrderRepository) DeleteOrder(ctx context.Context, orderID uint)
 	return nil
 }
 
-func (mock *MockOrderRepository) FinishOrderWithPayment(ctx context.Context, orderID uint, paymentID string) error {
-	args := mock.Called(ctx, orderID, paymentID)
+func (mock *MockOrderRepository) DeleteExpiredPayingOrders(ctx context.Context, expiredBefore time.Time) ([]uint, error) {
+	args := mock.Called(ctx, expiredBefore)
+	err := args.Error(1)
+
+	if err != nil {
+		return []uint{}, err
+	}
+
+	return args.Get(0).([]uint), nil
+}
+
+func (mock *MockOrderRepository) FinishOrderWithPayment(ctx context.Context, orderID uint, paymentID string, ticketDate int64) error {
+	args := mock.Called(ctx, orderID, paymentID, ticketDate)
 	err := args.Error(0)
 
 	if err != nil {
@@ -247,6 +412,17 @@ func (mock *MockOrderRepository) FinishOrderWithPayment(ctx context.Context, ord
 	return nil
 }
 
+func (mock *MockOrderRepository) GetOrderHistory(ctx context.Context, orderId uint) ([]dto.OrderStatusEventResponse, error) {
+	args := mock.Called(ctx, orderId)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.OrderStatusEventResponse{}, err
+	}
+
+	return args.Get(0).([]dto.OrderStatusEventResponse), nil
+}
+
 func (mock *MockOrderRepository) GetOrderById(ctx context.Context, orderId uint) (dto.OrderResponse, error) {
 	args := mock.Called(ctx, orderId)
 	err := args.Error(1)
@@ -291,19 +467,23 @@ func (mock *MockOrderRepository) GetOrdersWaitingPayment(ctx context.Context) ([
 	return args.Get(0).([]dto.OrderResponse), nil
 }
 
-func (mock *MockOrderRepository) UpdateToPreparing(ctx context.Context, orderId uint) error {
-	args := mock.Called(ctx, orderId)
-	err := args.Error(0)
+func (mock *MockOrderRepository) SearchOrders(
+	ctx context.Context,
+	search dto.OrderSearch,
+	after *dto.OrderCursor,
+) (dto.OrderSearchPage, error) {
+	args := mock.Called(ctx, search, after)
+	err := args.Error(1)
 
 	if err != nil {
-		return err
+		return dto.OrderSearchPage{}, err
 	}
 
-	return nil
+	return args.Get(0).(dto.OrderSearchPage), nil
 }
 
-func (mock *MockOrderRepository) UpdateToDone(ctx context.Context, orderId uint) error {
-	args := mock.Called(ctx, orderId)
+func (mock *MockOrderRepository) UpdateOrderStatus(ctx context.Context, orderId uint, transition statemachine.OrderTransition) error {
+	args := mock.Called(ctx, orderId, transition)
 	err := args.Error(0)
 
 	if err != nil {
@@ -313,8 +493,52 @@ func (mock *MockOrderRepository) UpdateToDone(ctx context.Context, orderId uint)
 	return nil
 }
 
-func (mock *MockOrderRepository) UpdateToDelivered(ctx context.Context, orderId uint) error {
-	args := mock.Called(ctx, orderId)
+func (mock *MockProductRepository) CreateProduct(ctx context.Context, product dto.ProductForm) (uint, error) {
+	args := mock.Called(ctx, product)
+	err := args.Error(1)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return args.Get(0).(uint), nil
+}
+
+func (mock *MockProductRepository) GetProductsByCategory(ctx context.Context, category string) ([]dto.ProductResponse, error) {
+	args := mock.Called(ctx, category)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.ProductResponse{}, err
+	}
+
+	return args.Get(0).([]dto.ProductResponse), nil
+}
+
+func (mock *MockProductRepository) GetProductById(ctx context.Context, id uint) (dto.ProductResponse, error) {
+	args := mock.Called(ctx, id)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.ProductResponse{}, err
+	}
+
+	return args.Get(0).(dto.ProductResponse), nil
+}
+
+func (mock *MockProductRepository) GetProductsByIds(ctx context.Context, ids []uint) ([]dto.ProductResponse, error) {
+	args := mock.Called(ctx, ids)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.ProductResponse{}, err
+	}
+
+	return args.Get(0).([]dto.ProductResponse), nil
+}
+
+func (mock *MockProductRepository) ArchiveProduct(ctx context.Context, productId uint) error {
+	args := mock.Called(ctx, productId)
 	err := args.Error(0)
 
 	if err != nil {
@@ -324,8 +548,8 @@ func (mock *MockOrderRepository) UpdateToDelivered(ctx context.Context, orderId
 	return nil
 }
 
-func (mock *MockOrderRepository) UpdateToNotDelivered(ctx context.Context, orderId uint) error {
-	args := mock.Called(ctx, orderId)
+func (mock *MockProductRepository) RestoreProduct(ctx context.Context, productId uint) error {
+	args := mock.Called(ctx, productId)
 	err := args.Error(0)
 
 	if err != nil {
@@ -335,13 +559,30 @@ func (mock *MockOrderRepository) UpdateToNotDelivered(ctx context.Context, order
 	return nil
 }
 
-func (mock *MockOrderRepository) GetNextTicketNumber(ctx context.Context, date int64) int {
-	args := mock.Called(ctx, date)
-	return args.Get(0).(int)
+func (mock *MockProductRepository) HasComboComponents(ctx context.Context, productId uint) (bool, error) {
+	args := mock.Called(ctx, productId)
+	err := args.Error(1)
+
+	if err != nil {
+		return false, err
+	}
+
+	return args.Bool(0), nil
 }
 
-func (mock *MockProductRepository) CreateProduct(ctx context.Context, product dto.ProductForm) (uint, error) {
+func (mock *MockProductRepository) UpdateProduct(ctx context.Context, product dto.ProductForm) error {
 	args := mock.Called(ctx, product)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockCategoryRepository) CreateCategory(ctx context.Context, category dto.CategoryForm) (uint, error) {
+	args := mock.Called(ctx, category)
 	err := args.Error(1)
 
 	if err != nil {
@@ -351,30 +592,62 @@ func (mock *MockProductRepository) CreateProduct(ctx context.Context, product dt
 	return args.Get(0).(uint), nil
 }
 
-func (mock *MockProductRepository) GetProductsByCategory(ctx context.Context, category string) ([]dto.ProductResponse, error) {
-	args := mock.Called(ctx, category)
+func (mock *MockCategoryRepository) GetCategories(ctx context.Context, onlyActive bool) ([]dto.CategoryResponse, error) {
+	args := mock.Called(ctx, onlyActive)
 	err := args.Error(1)
 
 	if err != nil {
-		return []dto.ProductResponse{}, err
+		return []dto.CategoryResponse{}, err
 	}
 
-	return args.Get(0).([]dto.ProductResponse), nil
+	return args.Get(0).([]dto.CategoryResponse), nil
 }
 
-func (mock *MockProductRepository) GetProductById(ctx context.Context, id uint) (dto.ProductResponse, error) {
+func (mock *MockCategoryRepository) GetCategoryById(ctx context.Context, id uint) (dto.CategoryResponse, error) {
 	args := mock.Called(ctx, id)
 	err := args.Error(1)
 
 	if err != nil {
-		return dto.ProductResponse{}, err
+		return dto.CategoryResponse{}, err
 	}
 
-	return args.Get(0).(dto.ProductResponse), nil
+	return args.Get(0).(dto.CategoryResponse), nil
 }
 
-func (mock *MockProductRepository) DeleteProduct(ctx context.Context, productId uint) error {
-	args := mock.Called(ctx, productId)
+func (mock *MockCategoryRepository) GetCategoryByName(ctx context.Context, name string) (dto.CategoryResponse, error) {
+	args := mock.Called(ctx, name)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.CategoryResponse{}, err
+	}
+
+	return args.Get(0).(dto.CategoryResponse), nil
+}
+
+func (mock *MockCategoryRepository) UpdateCategory(ctx context.Context, category dto.CategoryForm) error {
+	args := mock.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (mock *MockCategoryRepository) DeleteCategory(ctx context.Context, id uint) error {
+	args := mock.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (mock *MockOrderRepository) GetOrderByPaymentId(ctx context.Context, paymentID string) (dto.OrderResponse, error) {
+	args := mock.Called(ctx, paymentID)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.OrderResponse{}, err
+	}
+
+	return args.Get(0).(dto.OrderResponse), nil
+}
+
+func (mock *MockOrderRepository) UpdatePaymentStatus(ctx context.Context, orderID uint, paymentStatus string) error {
+	args := mock.Called(ctx, orderID, paymentStatus)
 	err := args.Error(0)
 
 	if err != nil {
@@ -384,8 +657,8 @@ func (mock *MockProductRepository) DeleteProduct(ctx context.Context, productId
 	return nil
 }
 
-func (mock *MockProductRepository) UpdateProduct(ctx context.Context, product dto.ProductForm) error {
-	args := mock.Called(ctx, product)
+func (mock *MockOrderRepository) RefundPayment(ctx context.Context, paymentID string) error {
+	args := mock.Called(ctx, paymentID)
 	err := args.Error(0)
 
 	if err != nil {
@@ -395,7 +668,138 @@ func (mock *MockProductRepository) UpdateProduct(ctx context.Context, product dt
 	return nil
 }
 
-func (mock *MockProductRepository) GetCategories() []string {
-	args := mock.Called()
-	return args.Get(0).([]string)
+func (mock *MockOrderRepository) UpdateOrderPriority(ctx context.Context, orderID uint, priority int) error {
+	args := mock.Called(ctx, orderID, priority)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockOrderRepository) CancelOrder(
+	ctx context.Context,
+	orderID uint,
+	transition statemachine.OrderTransition,
+	cancel dto.OrderCancelForm,
+) error {
+	args := mock.Called(ctx, orderID, transition, cancel)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockOutboxRepository) ClaimOutboxEvents(
+	ctx context.Context,
+	now time.Time,
+	lease time.Duration,
+	limit int,
+) ([]dto.OutboxEvent, error) {
+	args := mock.Called(ctx, now, lease, limit)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.OutboxEvent{}, err
+	}
+
+	return args.Get(0).([]dto.OutboxEvent), nil
+}
+
+func (mock *MockOutboxRepository) MarkOutboxEventDelivered(ctx context.Context, eventID uint) error {
+	args := mock.Called(ctx, eventID)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockOutboxRepository) MarkOutboxEventFailed(
+	ctx context.Context,
+	eventID uint,
+	reason string,
+	nextAttemptAt time.Time,
+) error {
+	args := mock.Called(ctx, eventID, reason, nextAttemptAt)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockOutboxRepository) DeleteDeliveredOutboxEvents(ctx context.Context, deliveredBefore time.Time) (int64, error) {
+	args := mock.Called(ctx, deliveredBefore)
+	err := args.Error(1)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return args.Get(0).(int64), nil
+}
+
+func (mock *MockComboRepository) CreateCombo(ctx context.Context, combo dto.ComboForm) (uint, error) {
+	args := mock.Called(ctx, combo)
+	err := args.Error(1)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return args.Get(0).(uint), nil
+}
+
+func (mock *MockComboRepository) UpdateCombo(ctx context.Context, combo dto.ComboForm) error {
+	args := mock.Called(ctx, combo)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockComboRepository) GetCombos(ctx context.Context) ([]dto.Combo, error) {
+	args := mock.Called(ctx)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.Combo{}, err
+	}
+
+	return args.Get(0).([]dto.Combo), nil
+}
+
+func (mock *MockComboRepository) GetComboById(ctx context.Context, id uint) (dto.Combo, error) {
+	args := mock.Called(ctx, id)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.Combo{}, err
+	}
+
+	return args.Get(0).(dto.Combo), nil
+}
+
+func (mock *MockComboRepository) GetCombosByIds(ctx context.Context, ids []uint) ([]dto.Combo, error) {
+	args := mock.Called(ctx, ids)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.Combo{}, err
+	}
+
+	return args.Get(0).([]dto.Combo), nil
 }
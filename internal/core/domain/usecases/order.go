@@ -2,25 +2,54 @@ package usecases
 
 import (
 	"context"
-	"sync"
+	"net/http"
 
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
 	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
 )
 
 type CreateOrderUseCase interface {
-	Execute(ctx context.Context, order dto.Order, date int64, wg *sync.WaitGroup, ch chan bool) (dto.OrderResponse, error)
-	GenerateTicket(ctx context.Context, date int64) int
+	Execute(ctx context.Context, order dto.Order, date int64) (dto.OrderResponse, error)
 }
 
 type CreateOrderUseCaseImpl struct {
-	orderRepo                repository.OrderRepository
-	customerRepo             repository.CustomerRepository
-	validateToPrepare        *ValidateOrderToPrepareUseCase
-	validateToDone           *ValidateOrderToDoneUseCase
-	validateToDeliveredOrNot *ValidateOrderToDeliveredOrNotUseCase
-	sortOrderUseCase         *SortOrdersUseCase
+	orderRepo         repository.OrderRepository
+	customerRepo      repository.CustomerRepository
+	priceOrderUseCase *PriceOrderUseCase
+	sortOrderUseCase  *SortOrdersUseCase
+	publisher         events.OrderEventPublisher
+}
+
+type UpdateOrderStatusUseCase interface {
+	Execute(ctx context.Context, orderId uint, status string) error
+}
+
+type UpdateOrderStatusUseCaseImpl struct {
+	orderRepo    repository.OrderRepository
+	stateMachine *statemachine.OrderStateMachine
+	publisher    events.OrderEventPublisher
+}
+
+type CancelOrderUseCase interface {
+	Execute(ctx context.Context, orderId uint, cancel dto.OrderCancelForm) error
+}
+
+type CancelOrderUseCaseImpl struct {
+	orderRepo    repository.OrderRepository
+	stateMachine *statemachine.OrderStateMachine
+	publisher    events.OrderEventPublisher
+}
+
+type UpdateOrderPriorityUseCase interface {
+	Execute(ctx context.Context, orderId uint, form dto.OrderPriorityForm) error
+}
+
+type UpdateOrderPriorityUseCaseImpl struct {
+	orderRepo repository.OrderRepository
 }
 
 type UpdateToPreparingUseCase interface {
@@ -28,8 +57,7 @@ type UpdateToPreparingUseCase interface {
 }
 
 type UpdateToPreparingUseCaseImpl struct {
-	orderRepo         repository.OrderRepository
-	validateToPrepare *ValidateOrderToPrepareUseCase
+	updateOrderStatus UpdateOrderStatusUseCase
 }
 
 type UpdateToDoneUseCase interface {
@@ -37,8 +65,7 @@ type UpdateToDoneUseCase interface {
 }
 
 type UpdateToDoneUseCaseImpl struct {
-	orderRepo      repository.OrderRepository
-	validateToDone *ValidateOrderToDoneUseCase
+	updateOrderStatus UpdateOrderStatusUseCase
 }
 
 type UpdateToDeliveredUseCase interface {
@@ -46,8 +73,7 @@ type UpdateToDeliveredUseCase interface {
 }
 
 type UpdateToDeliveredUseCaseImpl struct {
-	orderRepo                repository.OrderRepository
-	validateToDeliveredOrNot *ValidateOrderToDeliveredOrNotUseCase
+	updateOrderStatus UpdateOrderStatusUseCase
 }
 
 type UpdateToNotDeliveredUseCase interface {
@@ -55,8 +81,7 @@ type UpdateToNotDeliveredUseCase interface {
 }
 
 type UpdateToNotDeliveredUseCaseImpl struct {
-	orderRepo                repository.OrderRepository
-	validateToDeliveredOrNot *ValidateOrderToDeliveredOrNotUseCase
+	updateOrderStatus UpdateOrderStatusUseCase
 }
 
 type GetOrderByIdUseCase interface {
@@ -67,6 +92,14 @@ type GetOrderByIdUseCaseImpl struct {
 	orderRepo repository.OrderRepository
 }
 
+type GetOrderHistoryUseCase interface {
+	Execute(ctx context.Context, orderId uint) ([]dto.OrderStatusEventResponse, error)
+}
+
+type GetOrderHistoryUseCaseImpl struct {
+	orderRepo repository.OrderRepository
+}
+
 type GetOrdersToPrepareUseCase interface {
 	Execute(ctx context.Context) ([]dto.OrderResponse, error)
 }
@@ -97,18 +130,16 @@ type GetOrdersWaitingPaymentUseCaseImpl struct {
 func NewCreateOrderUseCase(
 	orderRepo repository.OrderRepository,
 	customerRepo repository.CustomerRepository,
-	validateToPrepate *ValidateOrderToPrepareUseCase,
-	validateToDone *ValidateOrderToDoneUseCase,
-	validateToDeliveredOrNot *ValidateOrderToDeliveredOrNotUseCase,
+	priceOrderUseCase *PriceOrderUseCase,
 	sortOrderUseCase *SortOrdersUseCase,
+	publisher events.OrderEventPublisher,
 ) CreateOrderUseCase {
 	return &CreateOrderUseCaseImpl{
-		orderRepo:                orderRepo,
-		customerRepo:             customerRepo,
-		validateToPrepare:        validateToPrepate,
-		validateToDone:           validateToDone,
-		validateToDeliveredOrNot: validateToDeliveredOrNot,
-		sortOrderUseCase:         sortOrderUseCase,
+		orderRepo:         orderRepo,
+		customerRepo:      customerRepo,
+		priceOrderUseCase: priceOrderUseCase,
+		sortOrderUseCase:  sortOrderUseCase,
+		publisher:         publisher,
 	}
 }
 
@@ -120,6 +151,14 @@ func NewGetOrderByIdUseCase(
 	}
 }
 
+func NewGetOrderHistoryUseCase(
+	orderRepo repository.OrderRepository,
+) GetOrderHistoryUseCase {
+	return &GetOrderHistoryUseCaseImpl{
+		orderRepo: orderRepo,
+	}
+}
+
 func NewGetOrdersToPrepareUseCase(
 	orderRepo repository.OrderRepository,
 	sortOrderUseCase *SortOrdersUseCase,
@@ -150,43 +189,65 @@ func NewGetOrdersWaitingPaymentUseCase(
 	}
 }
 
-func NewUpdateToPreparingUseCase(
+func NewUpdateOrderStatusUseCase(
+	orderRepo repository.OrderRepository,
+	stateMachine *statemachine.OrderStateMachine,
+	publisher events.OrderEventPublisher,
+) UpdateOrderStatusUseCase {
+	return &UpdateOrderStatusUseCaseImpl{
+		orderRepo:    orderRepo,
+		stateMachine: stateMachine,
+		publisher:    publisher,
+	}
+}
+
+func NewCancelOrderUseCase(
 	orderRepo repository.OrderRepository,
-	validateToPrepare *ValidateOrderToPrepareUseCase,
+	stateMachine *statemachine.OrderStateMachine,
+	publisher events.OrderEventPublisher,
+) CancelOrderUseCase {
+	return &CancelOrderUseCaseImpl{
+		orderRepo:    orderRepo,
+		stateMachine: stateMachine,
+		publisher:    publisher,
+	}
+}
+
+func NewUpdateOrderPriorityUseCase(orderRepo repository.OrderRepository) UpdateOrderPriorityUseCase {
+	return &UpdateOrderPriorityUseCaseImpl{
+		orderRepo: orderRepo,
+	}
+}
+
+func NewUpdateToPreparingUseCase(
+	updateOrderStatus UpdateOrderStatusUseCase,
 ) UpdateToPreparingUseCase {
 	return &UpdateToPreparingUseCaseImpl{
-		orderRepo:         orderRepo,
-		validateToPrepare: validateToPrepare,
+		updateOrderStatus: updateOrderStatus,
 	}
 }
 
 func NewUpdateToDoneUseCase(
-	orderRepo repository.OrderRepository,
-	validateToDone *ValidateOrderToDoneUseCase,
+	updateOrderStatus UpdateOrderStatusUseCase,
 ) UpdateToDoneUseCase {
 	return &UpdateToDoneUseCaseImpl{
-		orderRepo:      orderRepo,
-		validateToDone: validateToDone,
+		updateOrderStatus: updateOrderStatus,
 	}
 }
 
 func NewUpdateToDeliveredUseCase(
-	orderRepo repository.OrderRepository,
-	validateToDeliveredOrNot *ValidateOrderToDeliveredOrNotUseCase,
+	updateOrderStatus UpdateOrderStatusUseCase,
 ) UpdateToDeliveredUseCase {
 	return &UpdateToDeliveredUseCaseImpl{
-		orderRepo:                orderRepo,
-		validateToDeliveredOrNot: validateToDeliveredOrNot,
+		updateOrderStatus: updateOrderStatus,
 	}
 }
 
 func NewUpdateToNotDeliveredUseCase(
-	orderRepo repository.OrderRepository,
-	validateToDeliveredOrNot *ValidateOrderToDeliveredOrNotUseCase,
+	updateOrderStatus UpdateOrderStatusUseCase,
 ) UpdateToNotDeliveredUseCase {
 	return &UpdateToNotDeliveredUseCaseImpl{
-		orderRepo:                orderRepo,
-		validateToDeliveredOrNot: validateToDeliveredOrNot,
+		updateOrderStatus: updateOrderStatus,
 	}
 }
 
@@ -194,20 +255,25 @@ func (usecase *CreateOrderUseCaseImpl) Execute(
 	ctx context.Context,
 	order dto.Order,
 	date int64,
-	wg *sync.WaitGroup,
-	ch chan bool,
 ) (dto.OrderResponse, error) {
-	//Block this code below until this Channel be empty (by reading with <-ch)
-	ch <- true
+	order, err := usecase.priceOrderUseCase.Execute(ctx, order)
 
-	order.TicketNumber = usecase.GenerateTicket(ctx, date)
+	if err != nil {
+		return dto.OrderResponse{}, responses.GetResponseError(err, "OrderService -> CreateOrder")
+	}
 
-	response, err := usecase.orderRepo.CreateOrder(ctx, order)
+	response, err := usecase.orderRepo.CreateOrder(ctx, order, date)
 
 	if err != nil {
 		return dto.OrderResponse{}, responses.GetResponseError(err, "OrderService -> CreateOrder")
 	}
 
+	usecase.publisher.Publish(events.OrderEvent{
+		Type:        events.OrderCreated,
+		OrderID:     response.OrderId,
+		OrderStatus: model.OrderStatusCreated,
+	})
+
 	if order.CPF != nil {
 		customer, err := usecase.customerRepo.GetCustomerByCPF(ctx, *order.CPF)
 		if err == nil {
@@ -215,17 +281,9 @@ func (usecase *CreateOrderUseCaseImpl) Execute(
 		}
 	}
 
-	// Release the channel to others process be able to start a new order creation
-	<-ch
-	wg.Done()
-
 	return response, nil
 }
 
-func (usecase *CreateOrderUseCaseImpl) GenerateTicket(ctx context.Context, date int64) int {
-	return usecase.orderRepo.GetNextTicketNumber(ctx, date)
-}
-
 func (usecase *GetOrderByIdUseCaseImpl) Execute(ctx context.Context, orderId uint) (dto.OrderResponse, error) {
 	response, err := usecase.orderRepo.GetOrderById(ctx, orderId)
 
@@ -236,6 +294,16 @@ func (usecase *GetOrderByIdUseCaseImpl) Execute(ctx context.Context, orderId uin
 	return response, nil
 }
 
+func (usecase *GetOrderHistoryUseCaseImpl) Execute(ctx context.Context, orderId uint) ([]dto.OrderStatusEventResponse, error) {
+	response, err := usecase.orderRepo.GetOrderHistory(ctx, orderId)
+
+	if err != nil {
+		return []dto.OrderStatusEventResponse{}, responses.GetResponseError(err, "OrderService -> GetOrderHistory")
+	}
+
+	return response, nil
+}
+
 func (usecase *GetOrdersToPrepareUseCaseImpl) Execute(ctx context.Context) ([]dto.OrderResponse, error) {
 	response, err := usecase.orderRepo.GetOrdersToPrepare(ctx)
 
@@ -272,62 +340,143 @@ func (usecase *GetOrdersWaitingPaymentUseCaseImpl) Execute(ctx context.Context)
 	return response, nil
 }
 
-func (usecase *UpdateToPreparingUseCaseImpl) Execute(ctx context.Context, orderId uint) error {
-	err := usecase.validateToPrepare.Execute(ctx, orderId)
+func (usecase *UpdateOrderStatusUseCaseImpl) Execute(ctx context.Context, orderId uint, status string) error {
+	if status == model.OrderStatusCreated {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    "Orders are only created by the payment confirmation",
+		}
+	}
+
+	if status == model.OrderStatusCanceled {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    "Orders must be cancelled with a reason in the cancel route",
+		}
+	}
+
+	order, err := usecase.orderRepo.GetOrderById(ctx, orderId)
 
 	if err != nil {
-		return responses.GetResponseError(err, "OrderService -> UpdateToPreparing")
+		return responses.GetResponseError(err, "OrderService -> UpdateOrderStatus")
 	}
 
-	err = usecase.orderRepo.UpdateToPreparing(ctx, orderId)
+	err = checkIfMatch(ctx, order)
 
 	if err != nil {
-		return responses.GetResponseError(err, "OrderService -> UpdateToPreparing")
+		return responses.GetResponseError(err, "OrderService -> UpdateOrderStatus")
 	}
 
+	transition, err := usecase.stateMachine.Transition(order.OrderStatus, status, statemachine.RoleFromContext(ctx))
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> UpdateOrderStatus")
+	}
+
+	err = usecase.orderRepo.UpdateOrderStatus(ctx, orderId, transition)
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> UpdateOrderStatus")
+	}
+
+	usecase.publisher.Publish(events.OrderEvent{
+		Type:           events.OrderStatusChanged,
+		OrderID:        orderId,
+		PreviousStatus: transition.From,
+		OrderStatus:    transition.To,
+	})
+
 	return nil
 }
 
-func (usecase *UpdateToDoneUseCaseImpl) Execute(ctx context.Context, orderId uint) error {
-	err := usecase.validateToDone.Execute(ctx, orderId)
+// Execute cancels the order if its current status allows it. Orders being prepared
+// can only be cancelled by a manager
+func (usecase *CancelOrderUseCaseImpl) Execute(ctx context.Context, orderId uint, cancel dto.OrderCancelForm) error {
+	order, err := usecase.orderRepo.GetOrderById(ctx, orderId)
 
 	if err != nil {
-		return responses.GetResponseError(err, "OrderService -> UpdateToDone")
+		return responses.GetResponseError(err, "OrderService -> CancelOrder")
 	}
 
-	err = usecase.orderRepo.UpdateToDone(ctx, orderId)
+	err = checkIfMatch(ctx, order)
 
 	if err != nil {
-		return responses.GetResponseError(err, "OrderService -> UpdateToDone")
+		return responses.GetResponseError(err, "OrderService -> CancelOrder")
+	}
+
+	transition, err := usecase.stateMachine.Transition(order.OrderStatus, model.OrderStatusCanceled, statemachine.RoleFromContext(ctx))
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> CancelOrder")
 	}
 
+	err = usecase.orderRepo.CancelOrder(ctx, orderId, transition, cancel)
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> CancelOrder")
+	}
+
+	usecase.publisher.Publish(events.OrderEvent{
+		Type:           events.OrderCanceled,
+		OrderID:        orderId,
+		PreviousStatus: transition.From,
+		OrderStatus:    transition.To,
+	})
+
 	return nil
 }
 
-func (usecase *UpdateToDeliveredUseCaseImpl) Execute(ctx context.Context, orderId uint) error {
-	err := usecase.validateToDeliveredOrNot.Execute(ctx, orderId)
+// Execute lets only a manager move an order ahead of the queue, since the priority is
+// never taken from the customer order body.
+func (usecase *UpdateOrderPriorityUseCaseImpl) Execute(ctx context.Context, orderId uint, form dto.OrderPriorityForm) error {
+	if statemachine.RoleFromContext(ctx) != statemachine.RoleManager {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusForbidden,
+			Message:    "Only a manager can change the order priority",
+		}
+	}
+
+	err := usecase.orderRepo.UpdateOrderPriority(ctx, orderId, form.Priority)
 
 	if err != nil {
-		return responses.GetResponseError(err, "OrderService -> UpdateToDelivered")
+		return responses.GetResponseError(err, "OrderService -> UpdateOrderPriority")
 	}
 
-	err = usecase.orderRepo.UpdateToDelivered(ctx, orderId)
+	return nil
+}
+
+func (usecase *UpdateToPreparingUseCaseImpl) Execute(ctx context.Context, orderId uint) error {
+	err := usecase.updateOrderStatus.Execute(ctx, orderId, model.OrderStatusPreparing)
 
 	if err != nil {
-		return responses.GetResponseError(err, "OrderService -> UpdateToDelivered")
+		return responses.GetResponseError(err, "OrderService -> UpdateToPreparing")
 	}
 
 	return nil
 }
 
-func (usecase *UpdateToNotDeliveredUseCaseImpl) Execute(ctx context.Context, orderId uint) error {
-	err := usecase.validateToDeliveredOrNot.Execute(ctx, orderId)
+func (usecase *UpdateToDoneUseCaseImpl) Execute(ctx context.Context, orderId uint) error {
+	err := usecase.updateOrderStatus.Execute(ctx, orderId, model.OrderStatusDone)
 
 	if err != nil {
-		return responses.GetResponseError(err, "OrderService -> UpdateToNotDelivered")
+		return responses.GetResponseError(err, "OrderService -> UpdateToDone")
+	}
+
+	return nil
+}
+
+func (usecase *UpdateToDeliveredUseCaseImpl) Execute(ctx context.Context, orderId uint) error {
+	err := usecase.updateOrderStatus.Execute(ctx, orderId, model.OrderStatusDelivered)
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> UpdateToDelivered")
 	}
 
-	err = usecase.orderRepo.UpdateToNotDelivered(ctx, orderId)
+	return nil
+}
+
+func (usecase *UpdateToNotDeliveredUseCaseImpl) Execute(ctx context.Context, orderId uint) error {
+	err := usecase.updateOrderStatus.Execute(ctx, orderId, model.OrderStatusNotDelivered)
 
 	if err != nil {
 		return responses.GetResponseError(err, "OrderService -> UpdateToNotDelivered")
@@ -0,0 +1,296 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+// Prices sent by the client are accepted only if they differ from the menu by less than one cent
+const priceTolerance = 0.009
+
+type PriceOrderUseCase struct {
+	productRepo repository.ProductRepository
+	comboRepo   repository.ComboRepository
+}
+
+func NewPriceOrderUseCase(productRepo repository.ProductRepository, comboRepo repository.ComboRepository) *PriceOrderUseCase {
+	return &PriceOrderUseCase{
+		productRepo: productRepo,
+		comboRepo:   comboRepo,
+	}
+}
+
+// Execute fills every line and add-on price and the order total with the current menu prices.
+// Prices sent by the client are only used to detect a stale menu on the totem
+func (usecase *PriceOrderUseCase) Execute(ctx context.Context, order dto.Order) (dto.Order, error) {
+	if len(order.OrderProduct) == 0 {
+		return dto.Order{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    "The order must have at least one product",
+		}
+	}
+
+	ids := []uint{}
+
+	for _, value := range order.OrderProduct {
+		ids = appendProductId(ids, value.ProductID)
+
+		for _, addOn := range value.AddOns {
+			ids = appendProductId(ids, addOn.ProductID)
+		}
+	}
+
+	products, err := usecase.productRepo.GetProductsByIds(ctx, ids)
+
+	if err != nil {
+		return dto.Order{}, responses.GetResponseError(err, "PriceOrderUseCase -> GetProductsByIds")
+	}
+
+	productsById := map[uint]dto.ProductResponse{}
+
+	for _, product := range products {
+		productsById[product.Id] = product
+	}
+
+	combosById, err := usecase.getCombos(ctx, order, productsById)
+
+	if err != nil {
+		return dto.Order{}, err
+	}
+
+	pricedProducts := []dto.OrderProduct{}
+	total := 0.0
+
+	for _, value := range order.OrderProduct {
+		product, ok := productsById[value.ProductID]
+
+		if !ok {
+			return dto.Order{}, productNotFoundError(value.ProductID)
+		}
+
+		if product.ArchivedAt != nil {
+			return dto.Order{}, productArchivedError(product.Id)
+		}
+
+		if combo, ok := combosById[product.Id]; ok {
+			choices, comboPrice, err := priceCombo(combo, value.ComboChoices)
+
+			if err != nil {
+				return dto.Order{}, err
+			}
+
+			value.ComboChoices = choices
+			product.Price = comboPrice
+		} else if len(value.ComboChoices) > 0 {
+			return dto.Order{}, &responses.BusinessResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Message:    fmt.Sprintf("Product %v is not a combo and has no choices", product.Id),
+			}
+		}
+
+		if value.ProductPrice != 0 && !samePrice(value.ProductPrice, product.Price) {
+			return dto.Order{}, priceMismatchError(product, value.ProductPrice)
+		}
+
+		if value.Quantity == 0 {
+			value.Quantity = 1
+		}
+
+		value.ProductPrice = product.Price
+		unitPrice := product.Price
+		pricedAddOns := []dto.OrderProductAddOn{}
+
+		for _, addOn := range value.AddOns {
+			addOnProduct, ok := productsById[addOn.ProductID]
+
+			if !ok {
+				return dto.Order{}, productNotFoundError(addOn.ProductID)
+			}
+
+			if addOnProduct.ArchivedAt != nil {
+				return dto.Order{}, productArchivedError(addOnProduct.Id)
+			}
+
+			if addOnProduct.Category != model.CategoryToppings {
+				return dto.Order{}, &responses.BusinessResponse{
+					StatusCode: http.StatusUnprocessableEntity,
+					Message:    fmt.Sprintf("Product %v is not in %v category and can not be an add-on", addOnProduct.Id, model.CategoryToppings),
+				}
+			}
+
+			if addOn.ProductPrice != 0 && !samePrice(addOn.ProductPrice, addOnProduct.Price) {
+				return dto.Order{}, priceMismatchError(addOnProduct, addOn.ProductPrice)
+			}
+
+			addOn.ProductPrice = addOnProduct.Price
+			unitPrice += addOnProduct.Price
+			pricedAddOns = append(pricedAddOns, addOn)
+		}
+
+		value.AddOns = pricedAddOns
+		pricedProducts = append(pricedProducts, value)
+		total += unitPrice * float64(value.Quantity)
+	}
+
+	total = roundPrice(total)
+
+	if order.TotalPrice != 0 && !samePrice(order.TotalPrice, total) {
+		return dto.Order{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("Order total price is %v, not %v", total, order.TotalPrice),
+		}
+	}
+
+	order.OrderProduct = pricedProducts
+	order.TotalPrice = total
+
+	return order, nil
+}
+
+// getCombos loads the combos of the order lines, the add-ons are never combos
+func (usecase *PriceOrderUseCase) getCombos(
+	ctx context.Context,
+	order dto.Order,
+	productsById map[uint]dto.ProductResponse,
+) (map[uint]dto.Combo, error) {
+	combosById := map[uint]dto.Combo{}
+	ids := []uint{}
+
+	for _, value := range order.OrderProduct {
+		if productsById[value.ProductID].Category == model.CategoryCombo {
+			ids = appendProductId(ids, value.ProductID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return combosById, nil
+	}
+
+	combos, err := usecase.comboRepo.GetCombosByIds(ctx, ids)
+
+	if err != nil {
+		return combosById, responses.GetResponseError(err, "PriceOrderUseCase -> GetCombosByIds")
+	}
+
+	for _, combo := range combos {
+		combosById[combo.Id] = combo
+	}
+
+	return combosById, nil
+}
+
+// priceCombo answers the product of every slot of the combo and the combo price. Slots with a single
+// option take it, the others need a choice. A discount combo costs the chosen products with the discount
+func priceCombo(combo dto.Combo, choices []dto.ComboChoice) ([]dto.ComboChoice, float64, error) {
+	chosen := map[uint]uint{}
+
+	for _, choice := range choices {
+		if _, ok := chosen[choice.SlotID]; ok {
+			return nil, 0, &responses.BusinessResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Message:    fmt.Sprintf("Combo %v slot %v has more than one choice", combo.Id, choice.SlotID),
+			}
+		}
+
+		chosen[choice.SlotID] = choice.ProductID
+	}
+
+	resolved := []dto.ComboChoice{}
+	total := 0.0
+
+	for _, slot := range combo.Slots {
+		productId, ok := chosen[slot.Id]
+
+		if ok {
+			delete(chosen, slot.Id)
+		} else if len(slot.Options) == 1 {
+			productId = slot.Options[0].Id
+		} else {
+			return nil, 0, &responses.BusinessResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Message:    fmt.Sprintf("Combo %v needs a choice for %v", combo.Id, slot.Name),
+			}
+		}
+
+		optionIndex := slices.IndexFunc(slot.Options, func(option dto.ProductResponse) bool {
+			return option.Id == productId
+		})
+
+		if optionIndex < 0 {
+			return nil, 0, &responses.BusinessResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Message:    fmt.Sprintf("Product %v is not an option for %v of combo %v", productId, slot.Name, combo.Id),
+			}
+		}
+
+		option := slot.Options[optionIndex]
+
+		if option.ArchivedAt != nil {
+			return nil, 0, productArchivedError(option.Id)
+		}
+
+		resolved = append(resolved, dto.ComboChoice{
+			SlotID:    slot.Id,
+			ProductID: option.Id,
+		})
+		total += option.Price
+	}
+
+	for slotId := range chosen {
+		return nil, 0, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("Combo %v has no slot %v", combo.Id, slotId),
+		}
+	}
+
+	if combo.Pricing == model.ComboPricingDiscount {
+		return resolved, roundPrice(total * (100 - combo.Discount) / 100), nil
+	}
+
+	return resolved, combo.Price, nil
+}
+
+func appendProductId(ids []uint, id uint) []uint {
+	if slices.Contains(ids, id) {
+		return ids
+	}
+
+	return append(ids, id)
+}
+
+func productNotFoundError(productId uint) error {
+	return &responses.BusinessResponse{
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    fmt.Sprintf("Product %v not found", productId),
+	}
+}
+
+func productArchivedError(productId uint) error {
+	return &responses.BusinessResponse{
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    fmt.Sprintf("Product %v is no longer in the menu", productId),
+	}
+}
+
+func priceMismatchError(product dto.ProductResponse, price float64) error {
+	return &responses.BusinessResponse{
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    fmt.Sprintf("Product %v price is %v, not %v", product.Id, product.Price, price),
+	}
+}
+
+func samePrice(price, other float64) bool {
+	return math.Abs(price-other) < priceTolerance
+}
+
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
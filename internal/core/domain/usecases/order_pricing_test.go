@@ -0,0 +1,489 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestPriceOrderUseCase(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when pricing order with repeated products use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		sut := NewPriceOrderUseCase(productRepo, new(MockComboRepository))
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1, 2}).Return(orderProducts, nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			OrderProduct: []dto.OrderProduct{
+				{ProductID: 1},
+				{ProductID: 2},
+				{ProductID: 1},
+			},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(24345), response.TotalPrice)
+		assert.Equal(t, float64(12000), response.OrderProduct[0].ProductPrice)
+		assert.Equal(t, float64(345), response.OrderProduct[1].ProductPrice)
+		assert.Equal(t, float64(12000), response.OrderProduct[2].ProductPrice)
+	})
+
+	t.Run("got success when pricing order with matching client prices use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		sut := NewPriceOrderUseCase(productRepo, new(MockComboRepository))
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1, 2}).Return(orderProducts, nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			TotalPrice: 12345,
+			OrderProduct: []dto.OrderProduct{
+				{ProductID: 1, ProductPrice: 12000},
+				{ProductID: 2, ProductPrice: 345},
+			},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(12345), response.TotalPrice)
+	})
+
+	t.Run("got success when pricing order with quantities and add-ons use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		sut := NewPriceOrderUseCase(productRepo, new(MockComboRepository))
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{2, 5}).Return([]dto.ProductResponse{
+			orderProducts[1],
+			addOnProducts[0],
+		}, nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID:    2,
+					Quantity:     3,
+					Observations: "No onions",
+					AddOns: []dto.OrderProductAddOn{
+						{ProductID: 5},
+					},
+				},
+			},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1048.5, response.TotalPrice)
+		assert.Equal(t, 3, response.OrderProduct[0].Quantity)
+		assert.Equal(t, "No onions", response.OrderProduct[0].Observations)
+		assert.Equal(t, 4.5, response.OrderProduct[0].AddOns[0].ProductPrice)
+	})
+
+	t.Run("got error when pricing order with add-on outside toppings category use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		sut := NewPriceOrderUseCase(productRepo, new(MockComboRepository))
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{2, 6}).Return([]dto.ProductResponse{
+			orderProducts[1],
+			addOnProducts[1],
+		}, nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID: 2,
+					AddOns: []dto.OrderProductAddOn{
+						{ProductID: 6},
+					},
+				},
+			},
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when pricing order with unknown add-on use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		sut := NewPriceOrderUseCase(productRepo, new(MockComboRepository))
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{2, 7}).Return(orderProducts[1:], nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID: 2,
+					AddOns: []dto.OrderProductAddOn{
+						{ProductID: 7},
+					},
+				},
+			},
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+	})
+
+	t.Run("got error when pricing order with mismatched product price use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		sut := NewPriceOrderUseCase(productRepo, new(MockComboRepository))
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1}).Return(orderProducts[:1], nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			OrderProduct: []dto.OrderProduct{
+				{ProductID: 1, ProductPrice: 1},
+			},
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when pricing order with mismatched total price use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		sut := NewPriceOrderUseCase(productRepo, new(MockComboRepository))
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1, 2}).Return(orderProducts, nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			TotalPrice: 999,
+			OrderProduct: []dto.OrderProduct{
+				{ProductID: 1},
+				{ProductID: 2},
+			},
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when pricing order with unknown product use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		sut := NewPriceOrderUseCase(productRepo, new(MockComboRepository))
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{3}).Return([]dto.ProductResponse{}, nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			OrderProduct: []dto.OrderProduct{
+				{ProductID: 3},
+			},
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when pricing order with archived product use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		sut := NewPriceOrderUseCase(productRepo, new(MockComboRepository))
+
+		ctx := context.TODO()
+
+		archivedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+
+		productRepo.On("GetProductsByIds", ctx, []uint{3}).Return([]dto.ProductResponse{
+			{Id: 3, Price: 990, ArchivedAt: &archivedAt},
+		}, nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			OrderProduct: []dto.OrderProduct{
+				{ProductID: 3},
+			},
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when pricing order without products use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		sut := NewPriceOrderUseCase(productRepo, new(MockComboRepository))
+
+		response, err := sut.Execute(context.TODO(), dto.Order{})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+	})
+
+	t.Run("got error on GetProductsByIds repository when pricing order use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		sut := NewPriceOrderUseCase(productRepo, new(MockComboRepository))
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1}).Return([]dto.ProductResponse{}, &responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "service unavailable",
+		})
+
+		response, err := sut.Execute(ctx, dto.Order{
+			OrderProduct: []dto.OrderProduct{
+				{ProductID: 1},
+			},
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusServiceUnavailable, businessError.StatusCode)
+	})
+
+	t.Run("got success when pricing discount combo with the chosen products use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		comboRepo := new(MockComboRepository)
+		sut := NewPriceOrderUseCase(productRepo, comboRepo)
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{9}).Return([]dto.ProductResponse{
+			{Id: 9, Name: "Combo", Category: "Combo", Price: 23.4},
+		}, nil)
+		comboRepo.On("GetCombosByIds", ctx, []uint{9}).Return([]dto.Combo{discountCombo}, nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID: 9,
+					ComboChoices: []dto.ComboChoice{
+						{SlotID: 2, ProductID: 7},
+					},
+				},
+			},
+		})
+
+		comboRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 25.2, response.TotalPrice)
+		assert.Equal(t, 25.2, response.OrderProduct[0].ProductPrice)
+		assert.Equal(t, []dto.ComboChoice{
+			{SlotID: 1, ProductID: 1},
+			{SlotID: 2, ProductID: 7},
+		}, response.OrderProduct[0].ComboChoices)
+	})
+
+	t.Run("got success when pricing fixed combo use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		comboRepo := new(MockComboRepository)
+		sut := NewPriceOrderUseCase(productRepo, comboRepo)
+
+		ctx := context.TODO()
+
+		fixedCombo := discountCombo
+		fixedCombo.Pricing = "fixed"
+		fixedCombo.Price = 22
+
+		productRepo.On("GetProductsByIds", ctx, []uint{9}).Return([]dto.ProductResponse{
+			{Id: 9, Name: "Combo", Category: "Combo", Price: 22},
+		}, nil)
+		comboRepo.On("GetCombosByIds", ctx, []uint{9}).Return([]dto.Combo{fixedCombo}, nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID: 9,
+					Quantity:  2,
+					ComboChoices: []dto.ComboChoice{
+						{SlotID: 2, ProductID: 6},
+					},
+				},
+			},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(44), response.TotalPrice)
+		assert.Equal(t, float64(22), response.OrderProduct[0].ProductPrice)
+	})
+
+	t.Run("got error when pricing combo without a slot choice use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		comboRepo := new(MockComboRepository)
+		sut := NewPriceOrderUseCase(productRepo, comboRepo)
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{9}).Return([]dto.ProductResponse{
+			{Id: 9, Name: "Combo", Category: "Combo", Price: 23.4},
+		}, nil)
+		comboRepo.On("GetCombosByIds", ctx, []uint{9}).Return([]dto.Combo{discountCombo}, nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			OrderProduct: []dto.OrderProduct{
+				{ProductID: 9},
+			},
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when pricing combo with a choice outside the slot options use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		comboRepo := new(MockComboRepository)
+		sut := NewPriceOrderUseCase(productRepo, comboRepo)
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{9}).Return([]dto.ProductResponse{
+			{Id: 9, Name: "Combo", Category: "Combo", Price: 23.4},
+		}, nil)
+		comboRepo.On("GetCombosByIds", ctx, []uint{9}).Return([]dto.Combo{discountCombo}, nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID: 9,
+					ComboChoices: []dto.ComboChoice{
+						{SlotID: 2, ProductID: 1},
+					},
+				},
+			},
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when pricing combo with a choice for an unknown slot use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		comboRepo := new(MockComboRepository)
+		sut := NewPriceOrderUseCase(productRepo, comboRepo)
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{9}).Return([]dto.ProductResponse{
+			{Id: 9, Name: "Combo", Category: "Combo", Price: 23.4},
+		}, nil)
+		comboRepo.On("GetCombosByIds", ctx, []uint{9}).Return([]dto.Combo{discountCombo}, nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID: 9,
+					ComboChoices: []dto.ComboChoice{
+						{SlotID: 2, ProductID: 6},
+						{SlotID: 3, ProductID: 6},
+					},
+				},
+			},
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+	})
+
+	t.Run("got error when pricing product that is not a combo with choices use case", func(t *testing.T) {
+		t.Parallel()
+
+		productRepo := new(MockProductRepository)
+		comboRepo := new(MockComboRepository)
+		sut := NewPriceOrderUseCase(productRepo, comboRepo)
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1}).Return(comboComponents, nil)
+
+		response, err := sut.Execute(ctx, dto.Order{
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID: 1,
+					ComboChoices: []dto.ComboChoice{
+						{SlotID: 2, ProductID: 6},
+					},
+				},
+			},
+		})
+
+		comboRepo.AssertNotCalled(t, "GetCombosByIds")
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+}
@@ -0,0 +1,155 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+const (
+	DefaultOrderSearchLimit = 20
+	MaxOrderSearchLimit     = 100
+)
+
+type SearchOrdersUseCase interface {
+	Execute(ctx context.Context, search dto.OrderSearch) (dto.OrderSearchResponse, error)
+}
+
+type SearchOrdersUseCaseImpl struct {
+	orderRepo repository.OrderRepository
+}
+
+func NewSearchOrdersUseCase(orderRepo repository.OrderRepository) SearchOrdersUseCase {
+	return &SearchOrdersUseCaseImpl{
+		orderRepo: orderRepo,
+	}
+}
+
+// Execute returns one page of the orders matching the search. NextCursor is nil on the last page.
+// The search shows the CPF of every customer, so it needs a staff token
+func (usecase *SearchOrdersUseCaseImpl) Execute(ctx context.Context, search dto.OrderSearch) (dto.OrderSearchResponse, error) {
+	if statemachine.RoleFromContext(ctx) == "" {
+		return dto.OrderSearchResponse{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnauthorized,
+			Message:    "A staff token is required to search the orders",
+		}
+	}
+
+	if search.SortBy == "" {
+		search.SortBy = dto.OrderSortOrderDate
+	}
+
+	if search.Limit == 0 {
+		search.Limit = DefaultOrderSearchLimit
+	}
+
+	err := validateOrderSearch(search)
+
+	if err != nil {
+		return dto.OrderSearchResponse{}, err
+	}
+
+	var after *dto.OrderCursor
+
+	if search.Cursor != "" {
+		cursor, err := decodeOrderCursor(search.Cursor)
+
+		if err != nil || cursor.SortBy != search.SortBy || cursor.Descending != search.Descending {
+			return dto.OrderSearchResponse{}, &responses.BusinessResponse{
+				StatusCode: http.StatusBadRequest,
+				Message:    "The cursor is invalid or was created with another sort",
+			}
+		}
+
+		after = &cursor
+	}
+
+	page, err := usecase.orderRepo.SearchOrders(ctx, search, after)
+
+	if err != nil {
+		return dto.OrderSearchResponse{}, responses.GetResponseError(err, "OrderService -> SearchOrders")
+	}
+
+	response := dto.OrderSearchResponse{
+		Orders: page.Orders,
+	}
+
+	if page.Next != nil {
+		nextCursor, err := encodeOrderCursor(*page.Next)
+
+		if err != nil {
+			return dto.OrderSearchResponse{}, responses.GetResponseError(err, "OrderService -> SearchOrders")
+		}
+
+		response.NextCursor = &nextCursor
+	}
+
+	return response, nil
+}
+
+func validateOrderSearch(search dto.OrderSearch) error {
+	switch search.SortBy {
+	case dto.OrderSortOrderDate, dto.OrderSortUpdatedAt, dto.OrderSortTotalPrice:
+	default:
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message: fmt.Sprintf(
+				"Orders can only be sorted by %v, %v or %v",
+				dto.OrderSortOrderDate,
+				dto.OrderSortUpdatedAt,
+				dto.OrderSortTotalPrice,
+			),
+		}
+	}
+
+	if search.Limit < 1 || search.Limit > MaxOrderSearchLimit {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("The limit must be between 1 and %v", MaxOrderSearchLimit),
+		}
+	}
+
+	if search.From != nil && search.To != nil && !search.From.Before(*search.To) {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    "The from date must be before the to date",
+		}
+	}
+
+	return nil
+}
+
+// encodeOrderCursor makes the cursor opaque to the clients, they only send it back
+func encodeOrderCursor(cursor dto.OrderCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeOrderCursor(value string) (dto.OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+
+	if err != nil {
+		return dto.OrderCursor{}, err
+	}
+
+	var cursor dto.OrderCursor
+	err = json.Unmarshal(data, &cursor)
+
+	if err != nil {
+		return dto.OrderCursor{}, err
+	}
+
+	return cursor, nil
+}
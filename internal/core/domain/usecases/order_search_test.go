@@ -0,0 +1,176 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestSearchOrdersServices(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when searching orders with defaults in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewSearchOrdersUseCase(mockRepo)
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleWaiter)
+
+		expectedSearch := dto.OrderSearch{
+			Statuses: []string{"Entregue"},
+			SortBy:   dto.OrderSortOrderDate,
+			Limit:    DefaultOrderSearchLimit,
+		}
+
+		mockRepo.On("SearchOrders", ctx, expectedSearch, (*dto.OrderCursor)(nil)).Return(dto.OrderSearchPage{
+			Orders: ordersList,
+		}, nil)
+
+		response, err := sut.Execute(ctx, dto.OrderSearch{
+			Statuses: []string{"Entregue"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(response.Orders))
+		assert.Nil(t, response.NextCursor)
+	})
+
+	t.Run("got next page with the returned cursor when searching orders in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewSearchOrdersUseCase(mockRepo)
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleWaiter)
+
+		next := &dto.OrderCursor{
+			SortBy:     dto.OrderSortTotalPrice,
+			Descending: true,
+			ID:         42,
+			TotalPrice: 29.9,
+		}
+
+		mockRepo.On("SearchOrders", ctx, mock.Anything, (*dto.OrderCursor)(nil)).Return(dto.OrderSearchPage{
+			Orders: ordersList,
+			Next:   next,
+		}, nil).Once()
+
+		search := dto.OrderSearch{
+			SortBy:     dto.OrderSortTotalPrice,
+			Descending: true,
+			Limit:      1,
+		}
+
+		response, err := sut.Execute(ctx, search)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, response.NextCursor)
+
+		mockRepo.On("SearchOrders", ctx, mock.Anything, next).Return(dto.OrderSearchPage{
+			Orders: []dto.OrderResponse{},
+		}, nil).Once()
+
+		search.Cursor = *response.NextCursor
+
+		response, err = sut.Execute(ctx, search)
+
+		assert.NoError(t, err)
+		assert.Empty(t, response.Orders)
+		assert.Nil(t, response.NextCursor)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("got error when the cursor is from another sort in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewSearchOrdersUseCase(mockRepo)
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleWaiter)
+
+		cursor, err := encodeOrderCursor(dto.OrderCursor{SortBy: dto.OrderSortUpdatedAt, ID: 1})
+		assert.NoError(t, err)
+
+		for _, value := range []string{cursor, "not-a-cursor"} {
+			_, err = sut.Execute(ctx, dto.OrderSearch{Cursor: value})
+
+			var businessError *responses.BusinessResponse
+			assert.Equal(t, true, errors.As(err, &businessError))
+			assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+		}
+
+		mockRepo.AssertNotCalled(t, "SearchOrders")
+	})
+
+	t.Run("got error when the search is invalid in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewSearchOrdersUseCase(mockRepo)
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleWaiter)
+
+		now := time.Now()
+		before := now.Add(-time.Hour)
+
+		for _, search := range []dto.OrderSearch{
+			{SortBy: "ticketNumber"},
+			{Limit: MaxOrderSearchLimit + 1},
+			{Limit: -1},
+			{From: &now, To: &before},
+		} {
+			_, err := sut.Execute(ctx, search)
+
+			var businessError *responses.BusinessResponse
+			assert.Equal(t, true, errors.As(err, &businessError))
+			assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+		}
+
+		mockRepo.AssertNotCalled(t, "SearchOrders")
+	})
+
+	t.Run("got error when searching orders in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewSearchOrdersUseCase(mockRepo)
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleWaiter)
+
+		mockRepo.On("SearchOrders", ctx, mock.Anything, (*dto.OrderCursor)(nil)).Return(dto.OrderSearchPage{}, &responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "DATABASE_ERROR",
+		})
+
+		_, err := sut.Execute(ctx, dto.OrderSearch{})
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusServiceUnavailable, businessError.StatusCode)
+	})
+
+	t.Run("got unauthorized error when searching orders without a staff token in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewSearchOrdersUseCase(mockRepo)
+
+		_, err := sut.Execute(context.TODO(), dto.OrderSearch{})
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnauthorized, businessError.StatusCode)
+
+		mockRepo.AssertNotCalled(t, "SearchOrders")
+	})
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"net/http"
-	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
 	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
 )
 
@@ -23,150 +26,134 @@ func mockCustomer() dto.Customer {
 func TestOrderServices(t *testing.T) {
 	t.Parallel()
 
-	t.Run("got success when generating ticket number in services", func(t *testing.T) {
+	t.Run("got success when creating order in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
 		customerRepo := new(MockCustomerRepository)
-		validateToPrepare := NewValidateOrderToPrepareUseCase(mockRepo)
-		validateToDone := NewValidateOrderToDoneUseCase(mockRepo)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
-		sortOrdersUseCase := NewSortOrdersUseCase()
+		productRepo := new(MockProductRepository)
+		priceOrderUseCase := NewPriceOrderUseCase(productRepo, new(MockComboRepository))
+		sortOrdersUseCase := NewSortOrdersUseCase(PickupOrderSortView)
 
 		sut := NewCreateOrderUseCase(
 			mockRepo,
 			customerRepo,
-			validateToPrepare,
-			validateToDone,
-			validateToDeliveredOrNot,
+			priceOrderUseCase,
 			sortOrdersUseCase,
+			events.NewOrderEventBus(10),
 		)
 
 		ctx := context.TODO()
 
 		date := time.Now().UnixMilli()
 
-		mockRepo.On("GetNextTicketNumber", ctx, date).Return(1, nil)
+		productRepo.On("GetProductsByIds", ctx, []uint{1, 2}).Return(orderProducts, nil)
+		mockRepo.On("CreateOrder", ctx, pricedOrder(orderCreation), date).Return(orderCreationResponse, nil)
 
-		response := sut.GenerateTicket(ctx, date)
+		response, err := sut.Execute(ctx, orderCreation, date)
 
-		assert.Equal(t, 1, response)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response)
 	})
 
-	t.Run("got success when creating order in services", func(t *testing.T) {
+	t.Run("got success when creating order with customer in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
 		customerRepo := new(MockCustomerRepository)
-		validateToPrepare := NewValidateOrderToPrepareUseCase(mockRepo)
-		validateToDone := NewValidateOrderToDoneUseCase(mockRepo)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
-		sortOrdersUseCase := NewSortOrdersUseCase()
+		productRepo := new(MockProductRepository)
+		priceOrderUseCase := NewPriceOrderUseCase(productRepo, new(MockComboRepository))
+		sortOrdersUseCase := NewSortOrdersUseCase(PickupOrderSortView)
 
 		sut := NewCreateOrderUseCase(
 			mockRepo,
 			customerRepo,
-			validateToPrepare,
-			validateToDone,
-			validateToDeliveredOrNot,
+			priceOrderUseCase,
 			sortOrdersUseCase,
+			events.NewOrderEventBus(10),
 		)
 
 		ctx := context.TODO()
 
 		date := time.Now().UnixMilli()
 
-		mockRepo.On("CreateOrder", ctx, orderCreation).Return(orderCreationResponse, nil)
-		mockRepo.On("GetNextTicketNumber", ctx, date).Return(1, nil)
-
-		wg := &sync.WaitGroup{}
-		ch := make(chan bool, 1)
+		customerRepo.On("GetCustomerByCPF", ctx, *orderCreationWithCustomer.CPF).Return(mockCustomer(), nil)
+		productRepo.On("GetProductsByIds", ctx, []uint{1, 2}).Return(orderProducts, nil)
+		mockRepo.On("CreateOrder", ctx, pricedOrder(orderCreationWithCustomer), date).Return(orderWithCustomerCreationResponse, nil)
 
-		wg.Add(1)
-		response, err := sut.Execute(ctx, orderCreation, date, wg, ch)
+		response, err := sut.Execute(ctx, orderCreationWithCustomer, date)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, response)
 	})
 
-	t.Run("got success when creating order with customer in services", func(t *testing.T) {
+	t.Run("got error when creating order in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
 		customerRepo := new(MockCustomerRepository)
-		validateToPrepare := NewValidateOrderToPrepareUseCase(mockRepo)
-		validateToDone := NewValidateOrderToDoneUseCase(mockRepo)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
-		sortOrdersUseCase := NewSortOrdersUseCase()
+		productRepo := new(MockProductRepository)
+		priceOrderUseCase := NewPriceOrderUseCase(productRepo, new(MockComboRepository))
+		sortOrdersUseCase := NewSortOrdersUseCase(PickupOrderSortView)
 
 		sut := NewCreateOrderUseCase(
 			mockRepo,
 			customerRepo,
-			validateToPrepare,
-			validateToDone,
-			validateToDeliveredOrNot,
+			priceOrderUseCase,
 			sortOrdersUseCase,
+			events.NewOrderEventBus(10),
 		)
 
 		ctx := context.TODO()
 
 		date := time.Now().UnixMilli()
 
-		customerRepo.On("GetCustomerByCPF", ctx, *orderCreationWithCustomer.CPF).Return(mockCustomer(), nil)
-		mockRepo.On("CreateOrder", ctx, orderCreationWithCustomer).Return(orderWithCustomerCreationResponse, nil)
-		mockRepo.On("GetNextTicketNumber", ctx, date).Return(1, nil)
+		productRepo.On("GetProductsByIds", ctx, []uint{1, 2}).Return(orderProducts, nil)
+		mockRepo.On("CreateOrder", ctx, pricedOrder(orderCreationWithCustomer), date).Return(dto.OrderResponse{}, &responses.NetworkError{
+			Code:    409,
+			Message: "Conflict",
+		})
 
-		wg := &sync.WaitGroup{}
-		ch := make(chan bool, 1)
+		response, err := sut.Execute(ctx, orderCreationWithCustomer, date)
 
-		wg.Add(1)
-		response, err := sut.Execute(ctx, orderCreationWithCustomer, date, wg, ch)
+		assert.Error(t, err)
+		assert.Empty(t, response)
 
-		assert.NoError(t, err)
-		assert.NotEmpty(t, response)
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
 	})
 
-	t.Run("got error when creating order in services", func(t *testing.T) {
+	t.Run("got error when creating order with unknown product in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
 		customerRepo := new(MockCustomerRepository)
-		validateToPrepare := NewValidateOrderToPrepareUseCase(mockRepo)
-		validateToDone := NewValidateOrderToDoneUseCase(mockRepo)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
-		sortOrdersUseCase := NewSortOrdersUseCase()
+		productRepo := new(MockProductRepository)
 
 		sut := NewCreateOrderUseCase(
 			mockRepo,
 			customerRepo,
-			validateToPrepare,
-			validateToDone,
-			validateToDeliveredOrNot,
-			sortOrdersUseCase,
+			NewPriceOrderUseCase(productRepo, new(MockComboRepository)),
+			NewSortOrdersUseCase(PickupOrderSortView),
+			events.NewOrderEventBus(10),
 		)
 
 		ctx := context.TODO()
 
 		date := time.Now().UnixMilli()
 
-		mockRepo.On("GetNextTicketNumber", ctx, date).Return(1, nil)
-		mockRepo.On("CreateOrder", ctx, orderCreationWithCustomer).Return(dto.OrderResponse{}, &responses.NetworkError{
-			Code:    409,
-			Message: "Conflict",
-		})
+		productRepo.On("GetProductsByIds", ctx, []uint{1, 2}).Return(orderProducts[:1], nil)
 
-		wg := &sync.WaitGroup{}
-		ch := make(chan bool, 1)
-
-		wg.Add(1)
-		response, err := sut.Execute(ctx, orderCreationWithCustomer, date, wg, ch)
+		response, err := sut.Execute(ctx, orderCreation, date)
 
 		assert.Error(t, err)
 		assert.Empty(t, response)
 
 		var businessError *responses.BusinessResponse
 		assert.Equal(t, true, errors.As(err, &businessError))
-		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+		mockRepo.AssertNotCalled(t, "CreateOrder", ctx, mock.Anything, date)
 	})
 
 	t.Run("got success when getting order by id in services", func(t *testing.T) {
@@ -210,11 +197,67 @@ func TestOrderServices(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
 	})
 
+	t.Run("got success when getting order history in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewGetOrderHistoryUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderHistory", ctx, uint(1)).Return([]dto.OrderStatusEventResponse{
+			{
+				PreviousStatus: "",
+				NewStatus:      "Criado",
+				Actor:          "waiter",
+				RequestID:      "host/abc-000001",
+			},
+			{
+				PreviousStatus: "Criado",
+				NewStatus:      "Preparando",
+				Actor:          "kitchen",
+				RequestID:      "host/abc-000002",
+			},
+		}, nil)
+
+		response, err := sut.Execute(ctx, uint(1))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(response))
+		assert.Equal(t, "Preparando", response[1].NewStatus)
+		assert.Equal(t, "kitchen", response[1].Actor)
+	})
+
+	t.Run("got error when getting order history in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewGetOrderHistoryUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderHistory", ctx, uint(1)).Return([]dto.OrderStatusEventResponse{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Not Found",
+		})
+
+		response, err := sut.Execute(ctx, uint(1))
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
 	t.Run("got success when getting orders to prepare in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		sortOrdersUseCase := NewSortOrdersUseCase()
+		sortOrdersUseCase := NewSortOrdersUseCase(KitchenOrderSortView)
 
 		sut := NewGetOrdersToPrepareUseCase(mockRepo, sortOrdersUseCase)
 
@@ -237,7 +280,7 @@ func TestOrderServices(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		sortOrdersUseCase := NewSortOrdersUseCase()
+		sortOrdersUseCase := NewSortOrdersUseCase(KitchenOrderSortView)
 
 		sut := NewGetOrdersToPrepareUseCase(mockRepo, sortOrdersUseCase)
 
@@ -265,7 +308,7 @@ func TestOrderServices(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		sortOrdersUseCase := NewSortOrdersUseCase()
+		sortOrdersUseCase := NewSortOrdersUseCase(PickupOrderSortView)
 
 		sut := NewGetOrdersToFollowUseCase(mockRepo, sortOrdersUseCase)
 
@@ -285,7 +328,7 @@ func TestOrderServices(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		sortOrdersUseCase := NewSortOrdersUseCase()
+		sortOrdersUseCase := NewSortOrdersUseCase(PickupOrderSortView)
 
 		sut := NewGetOrdersToFollowUseCase(mockRepo, sortOrdersUseCase)
 
@@ -310,16 +353,18 @@ func TestOrderServices(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
+		updateOrderStatus := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
 
-		sut := NewUpdateToDeliveredUseCase(mockRepo, validateToDeliveredOrNot)
+		sut := NewUpdateToDeliveredUseCase(updateOrderStatus)
 
-		ctx := context.TODO()
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
 
 		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
 			OrderStatus: "Finalizado",
 		}, nil)
-		mockRepo.On("UpdateToDelivered", ctx, uint(1)).Return(nil)
+		mockRepo.On("UpdateOrderStatus", ctx, uint(1), mock.MatchedBy(func(transition statemachine.OrderTransition) bool {
+			return transition.From == model.OrderStatusDone && transition.To == model.OrderStatusDelivered && transition.TimestampColumn == "delivered_at"
+		})).Return(nil)
 
 		err := sut.Execute(ctx, uint(1))
 
@@ -330,16 +375,16 @@ func TestOrderServices(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
+		updateOrderStatus := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
 
-		sut := NewUpdateToDeliveredUseCase(mockRepo, validateToDeliveredOrNot)
+		sut := NewUpdateToDeliveredUseCase(updateOrderStatus)
 
-		ctx := context.TODO()
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
 
 		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
 			OrderStatus: "Finalizado",
 		}, nil)
-		mockRepo.On("UpdateToDelivered", ctx, uint(1)).Return(&responses.NetworkError{
+		mockRepo.On("UpdateOrderStatus", ctx, uint(1), mock.Anything).Return(&responses.NetworkError{
 			Code:    404,
 			Message: "Not Found",
 		})
@@ -357,16 +402,18 @@ func TestOrderServices(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToDone := NewValidateOrderToDoneUseCase(mockRepo)
+		updateOrderStatus := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
 
-		sut := NewUpdateToDoneUseCase(mockRepo, validateToDone)
+		sut := NewUpdateToDoneUseCase(updateOrderStatus)
 
-		ctx := context.TODO()
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
 
 		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
 			OrderStatus: "Preparando",
 		}, nil)
-		mockRepo.On("UpdateToDone", ctx, uint(1)).Return(nil)
+		mockRepo.On("UpdateOrderStatus", ctx, uint(1), mock.MatchedBy(func(transition statemachine.OrderTransition) bool {
+			return transition.From == model.OrderStatusPreparing && transition.To == model.OrderStatusDone && transition.TimestampColumn == "done_at"
+		})).Return(nil)
 
 		err := sut.Execute(ctx, uint(1))
 
@@ -377,16 +424,16 @@ func TestOrderServices(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToDone := NewValidateOrderToDoneUseCase(mockRepo)
+		updateOrderStatus := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
 
-		sut := NewUpdateToDoneUseCase(mockRepo, validateToDone)
+		sut := NewUpdateToDoneUseCase(updateOrderStatus)
 
-		ctx := context.TODO()
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
 
 		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
 			OrderStatus: "Preparando",
 		}, nil)
-		mockRepo.On("UpdateToDone", ctx, uint(1)).Return(&responses.NetworkError{
+		mockRepo.On("UpdateOrderStatus", ctx, uint(1), mock.Anything).Return(&responses.NetworkError{
 			Code:    404,
 			Message: "Not Found",
 		})
@@ -404,16 +451,18 @@ func TestOrderServices(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
+		updateOrderStatus := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
 
-		sut := NewUpdateToNotDeliveredUseCase(mockRepo, validateToDeliveredOrNot)
+		sut := NewUpdateToNotDeliveredUseCase(updateOrderStatus)
 
-		ctx := context.TODO()
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
 
 		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
 			OrderStatus: "Finalizado",
 		}, nil)
-		mockRepo.On("UpdateToNotDelivered", ctx, uint(1)).Return(nil)
+		mockRepo.On("UpdateOrderStatus", ctx, uint(1), mock.MatchedBy(func(transition statemachine.OrderTransition) bool {
+			return transition.From == model.OrderStatusDone && transition.To == model.OrderStatusNotDelivered && transition.TimestampColumn == "not_delivered_at"
+		})).Return(nil)
 
 		err := sut.Execute(ctx, uint(1))
 
@@ -424,16 +473,16 @@ func TestOrderServices(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
+		updateOrderStatus := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
 
-		sut := NewUpdateToNotDeliveredUseCase(mockRepo, validateToDeliveredOrNot)
+		sut := NewUpdateToNotDeliveredUseCase(updateOrderStatus)
 
-		ctx := context.TODO()
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
 
 		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
 			OrderStatus: "Finalizado",
 		}, nil)
-		mockRepo.On("UpdateToNotDelivered", ctx, uint(1)).Return(&responses.NetworkError{
+		mockRepo.On("UpdateOrderStatus", ctx, uint(1), mock.Anything).Return(&responses.NetworkError{
 			Code:    404,
 			Message: "Not Found",
 		})
@@ -451,16 +500,18 @@ func TestOrderServices(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToPrepare := NewValidateOrderToPrepareUseCase(mockRepo)
+		updateOrderStatus := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
 
-		sut := NewUpdateToPreparingUseCase(mockRepo, validateToPrepare)
+		sut := NewUpdateToPreparingUseCase(updateOrderStatus)
 
-		ctx := context.TODO()
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
 
 		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
 			OrderStatus: "Criado",
 		}, nil)
-		mockRepo.On("UpdateToPreparing", ctx, uint(1)).Return(nil)
+		mockRepo.On("UpdateOrderStatus", ctx, uint(1), mock.MatchedBy(func(transition statemachine.OrderTransition) bool {
+			return transition.From == model.OrderStatusCreated && transition.To == model.OrderStatusPreparing && transition.TimestampColumn == "preparing_at"
+		})).Return(nil)
 
 		err := sut.Execute(ctx, uint(1))
 
@@ -471,34 +522,359 @@ func TestOrderServices(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToPrepare := NewValidateOrderToPrepareUseCase(mockRepo)
+		updateOrderStatus := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
 
-		sut := NewUpdateToPreparingUseCase(mockRepo, validateToPrepare)
+		sut := NewUpdateToPreparingUseCase(updateOrderStatus)
 
-		ctx := context.TODO()
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
 
-		mockRepo.On("UpdateToPreparing", ctx, uint(1)).Return(&responses.NetworkError{
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+		}, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, uint(1), mock.Anything).Return(&responses.NetworkError{
 			Code:    404,
 			Message: "Not Found",
 		})
 
+		err := sut.Execute(ctx, uint(1))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
+	t.Run("got status changed event when updating order status in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		bus := events.NewOrderEventBus(10)
+		subscription, _, _ := bus.Subscribe(0)
+
+		sut := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), bus)
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+		}, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, uint(1), mock.Anything).Return(nil)
+
+		err := sut.Execute(ctx, uint(1), "Preparando")
+
+		assert.NoError(t, err)
+
+		event := <-subscription.Events()
+		assert.Equal(t, events.OrderStatusChanged, event.Type)
+		assert.Equal(t, uint(1), event.OrderID)
+		assert.Equal(t, "Criado", event.PreviousStatus)
+		assert.Equal(t, "Preparando", event.OrderStatus)
+	})
+
+	t.Run("got no event when updating order status fails in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		bus := events.NewOrderEventBus(10)
+		subscription, _, _ := bus.Subscribe(0)
+
+		sut := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), bus)
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
+
 		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
 			OrderStatus: "Criado",
 		}, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, uint(1), mock.Anything).Return(&responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: "The order is no longer in Criado status",
+		})
+
+		err := sut.Execute(ctx, uint(1), "Preparando")
+
+		assert.Error(t, err)
+
+		// the bus delivers synchronously, so an event would already be buffered
+		assert.Empty(t, subscription.Events())
+	})
+
+	t.Run("got error when updating order to a status not allowed from the current one in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		updateOrderStatus := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		sut := NewUpdateToDoneUseCase(updateOrderStatus)
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+		}, nil)
+
 		err := sut.Execute(ctx, uint(1))
 
 		assert.Error(t, err)
 
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusPreconditionRequired, businessError.StatusCode)
+		mockRepo.AssertNotCalled(t, "UpdateOrderStatus", ctx, uint(1), mock.Anything)
+	})
+
+	t.Run("got error when updating order status with a role not allowed in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleWaiter)
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+		}, nil)
+
+		err := sut.Execute(ctx, uint(1), "Preparando")
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusForbidden, businessError.StatusCode)
+	})
+
+	t.Run("got error when another request changed the order status first in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+		}, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, uint(1), mock.Anything).Return(&responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: "The order is no longer in Criado status",
+		})
+
+		err := sut.Execute(ctx, uint(1), "Preparando")
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
+	})
+
+	t.Run("got success when updating order status with a matching If-Match in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		order := dto.OrderResponse{
+			OrderStatus: "Criado",
+			UpdatedAt:   time.Date(2024, 7, 10, 12, 30, 0, 123456000, time.UTC),
+		}
+
+		ctx := WithIfMatch(statemachine.WithRole(context.TODO(), statemachine.RoleManager), "\"1\", "+OrderETag(order))
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(order, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, uint(1), mock.Anything).Return(nil)
+
+		err := sut.Execute(ctx, uint(1), "Preparando")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got error when updating order status with an outdated If-Match in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		order := dto.OrderResponse{
+			OrderStatus: "Criado",
+			UpdatedAt:   time.Date(2024, 7, 10, 12, 30, 0, 123456000, time.UTC),
+		}
+
+		ctx := WithIfMatch(statemachine.WithRole(context.TODO(), statemachine.RoleManager), "\"1720614600000000\"")
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(order, nil)
+
+		err := sut.Execute(ctx, uint(1), "Preparando")
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusPreconditionFailed, businessError.StatusCode)
+		mockRepo.AssertNotCalled(t, "UpdateOrderStatus", ctx, uint(1), mock.Anything)
+	})
+
+	t.Run("got error when getting order on update order status in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Order not found",
+		})
+
+		err := sut.Execute(ctx, uint(1), "Preparando")
+
+		assert.Error(t, err)
+
 		var businessError *responses.BusinessResponse
 		assert.Equal(t, true, errors.As(err, &businessError))
 		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
 	})
 
+	t.Run("got success when cancelling order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewCancelOrderUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleWaiter)
+
+		cancel := dto.OrderCancelForm{
+			Reason: model.CancelReasonCustomerRequest,
+		}
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+		}, nil)
+		mockRepo.On("CancelOrder", ctx, uint(1), mock.MatchedBy(func(transition statemachine.OrderTransition) bool {
+			return transition.From == model.OrderStatusCreated && transition.To == model.OrderStatusCanceled
+		}), cancel).Return(nil)
+
+		err := sut.Execute(ctx, uint(1), cancel)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got error when cancelling order being prepared without manager in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewCancelOrderUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleKitchen)
+
+		cancel := dto.OrderCancelForm{
+			Reason: model.CancelReasonOutOfStock,
+		}
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Preparando",
+		}, nil)
+
+		err := sut.Execute(ctx, uint(1), cancel)
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusForbidden, businessError.StatusCode)
+		mockRepo.AssertNotCalled(t, "CancelOrder", ctx, uint(1), mock.Anything, cancel)
+	})
+
+	t.Run("got error when cancelling order already delivered in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewCancelOrderUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
+
+		cancel := dto.OrderCancelForm{
+			Reason: model.CancelReasonOther,
+		}
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Entregue",
+		}, nil)
+
+		err := sut.Execute(ctx, uint(1), cancel)
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusPreconditionRequired, businessError.StatusCode)
+	})
+
+	t.Run("got error when cancelling order through update order status in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
+
+		err := sut.Execute(ctx, uint(1), "Cancelado")
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+		mockRepo.AssertNotCalled(t, "GetOrderById", ctx, uint(1))
+	})
+
+	t.Run("got error when confirming payment through update order status in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleSystem)
+
+		err := sut.Execute(ctx, uint(1), "Criado")
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+		mockRepo.AssertNotCalled(t, "GetOrderById", ctx, uint(1))
+	})
+
+	t.Run("got forbidden when updating order status without role in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewUpdateOrderStatusUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+		}, nil)
+
+		err := sut.Execute(ctx, uint(1), "Preparando")
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusForbidden, businessError.StatusCode)
+		mockRepo.AssertNotCalled(t, "UpdateOrderStatus", ctx, uint(1), mock.Anything)
+	})
+
 	t.Run("got success when get orders waiting payment use case", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		sut := NewGetOrdersWaitingPaymentUseCase(mockRepo, NewSortOrdersUseCase())
+		sut := NewGetOrdersWaitingPaymentUseCase(mockRepo, NewSortOrdersUseCase(PickupOrderSortView))
 
 		ctx := context.TODO()
 
@@ -523,7 +899,7 @@ func TestOrderServices(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		sut := NewGetOrdersWaitingPaymentUseCase(mockRepo, NewSortOrdersUseCase())
+		sut := NewGetOrdersWaitingPaymentUseCase(mockRepo, NewSortOrdersUseCase(PickupOrderSortView))
 
 		ctx := context.TODO()
 
@@ -536,4 +912,78 @@ func TestOrderServices(t *testing.T) {
 		assert.Error(t, err)
 		assert.Empty(t, response)
 	})
+
+	t.Run("got success when updating order priority in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewUpdateOrderPriorityUseCase(mockRepo)
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
+
+		mockRepo.On("UpdateOrderPriority", ctx, uint(1), 2).Return(nil)
+
+		err := sut.Execute(ctx, uint(1), dto.OrderPriorityForm{Priority: 2})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("got error when updating order priority without manager in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewUpdateOrderPriorityUseCase(mockRepo)
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleWaiter)
+
+		err := sut.Execute(ctx, uint(1), dto.OrderPriorityForm{Priority: 2})
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusForbidden, businessError.StatusCode)
+		mockRepo.AssertNotCalled(t, "UpdateOrderPriority", ctx, uint(1), 2)
+	})
+
+	t.Run("got error when updating order priority without role in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewUpdateOrderPriorityUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		err := sut.Execute(ctx, uint(1), dto.OrderPriorityForm{Priority: 2})
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusForbidden, businessError.StatusCode)
+		mockRepo.AssertNotCalled(t, "UpdateOrderPriority", ctx, uint(1), 2)
+	})
+
+	t.Run("got error when updating priority of unknown order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewUpdateOrderPriorityUseCase(mockRepo)
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
+
+		mockRepo.On("UpdateOrderPriority", ctx, uint(1), 2).Return(&responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Order not found",
+		})
+
+		err := sut.Execute(ctx, uint(1), dto.OrderPriorityForm{Priority: 2})
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
 }
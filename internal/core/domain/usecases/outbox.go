@@ -0,0 +1,148 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+// OutboxRelaySettings controls how many rows are delivered per run, how long they are
+// leased to this relay, how long a failed row waits before the next attempt and
+// how long a delivered row is kept
+type OutboxRelaySettings struct {
+	BatchSize  int
+	Lease      time.Duration
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	Retention  time.Duration
+}
+
+type RelayOutboxEventsUseCase interface {
+	Execute(ctx context.Context, now time.Time) error
+}
+
+type RelayOutboxEventsUseCaseImpl struct {
+	outboxRepo repository.OutboxRepository
+	publisher  events.EventPublisher
+	settings   OutboxRelaySettings
+}
+
+type PurgeOutboxEventsUseCase interface {
+	Execute(ctx context.Context, now time.Time) error
+}
+
+type PurgeOutboxEventsUseCaseImpl struct {
+	outboxRepo repository.OutboxRepository
+	settings   OutboxRelaySettings
+}
+
+func NewRelayOutboxEventsUseCase(
+	outboxRepo repository.OutboxRepository,
+	publisher events.EventPublisher,
+	settings OutboxRelaySettings,
+) RelayOutboxEventsUseCase {
+	return &RelayOutboxEventsUseCaseImpl{
+		outboxRepo: outboxRepo,
+		publisher:  publisher,
+		settings:   settings,
+	}
+}
+
+func NewPurgeOutboxEventsUseCase(
+	outboxRepo repository.OutboxRepository,
+	settings OutboxRelaySettings,
+) PurgeOutboxEventsUseCase {
+	return &PurgeOutboxEventsUseCaseImpl{
+		outboxRepo: outboxRepo,
+		settings:   settings,
+	}
+}
+
+// Execute publishes the pending outbox rows oldest first. A row is only marked as delivered
+// after the publisher accepted it, so a crash in between delivers it again (at-least-once).
+// When a row fails, the next rows of the same order wait for it, keeping the order events in sequence
+func (usecase *RelayOutboxEventsUseCaseImpl) Execute(ctx context.Context, now time.Time) error {
+	outboxEvents, err := usecase.outboxRepo.ClaimOutboxEvents(ctx, now, usecase.settings.Lease, usecase.settings.BatchSize)
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> RelayOutboxEvents")
+	}
+
+	blockedOrders := map[uint]bool{}
+
+	for _, outboxEvent := range outboxEvents {
+		event := outboxEvent.Event
+
+		if blockedOrders[event.OrderID] {
+			// the lease expires and the claim skips the row until the failed one is delivered
+			continue
+		}
+
+		err = usecase.publisher.Publish(ctx, event)
+
+		if err != nil {
+			blockedOrders[event.OrderID] = true
+
+			log.Print("relay outbox event", map[string]interface{}{
+				"eventId":  event.ID,
+				"orderId":  event.OrderID,
+				"attempts": outboxEvent.Attempts + 1,
+				"error":    err.Error(),
+			})
+
+			err = usecase.outboxRepo.MarkOutboxEventFailed(
+				ctx,
+				event.ID,
+				err.Error(),
+				now.Add(usecase.backoff(outboxEvent.Attempts)),
+			)
+
+			if err != nil {
+				return responses.GetResponseError(err, "OrderService -> RelayOutboxEvents")
+			}
+
+			continue
+		}
+
+		err = usecase.outboxRepo.MarkOutboxEventDelivered(ctx, event.ID)
+
+		if err != nil {
+			return responses.GetResponseError(err, "OrderService -> RelayOutboxEvents")
+		}
+	}
+
+	return nil
+}
+
+// backoff doubles the wait on every failed attempt, up to MaxBackoff
+func (usecase *RelayOutboxEventsUseCaseImpl) backoff(attempts int) time.Duration {
+	wait := usecase.settings.MinBackoff
+
+	for i := 0; i < attempts && wait < usecase.settings.MaxBackoff; i++ {
+		wait *= 2
+	}
+
+	return min(wait, usecase.settings.MaxBackoff)
+}
+
+// Execute deletes the rows delivered more than Retention ago, so the outbox table
+// only grows with the pending and dead rows
+func (usecase *PurgeOutboxEventsUseCaseImpl) Execute(ctx context.Context, now time.Time) error {
+	deleted, err := usecase.outboxRepo.DeleteDeliveredOutboxEvents(ctx, now.Add(-usecase.settings.Retention))
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> PurgeOutboxEvents")
+	}
+
+	if deleted > 0 {
+		log.Print("purge outbox events", map[string]interface{}{
+			"deleted": deleted,
+		})
+	}
+
+	return nil
+}
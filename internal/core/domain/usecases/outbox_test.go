@@ -0,0 +1,219 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+var outboxRelaySettings = OutboxRelaySettings{
+	BatchSize:  100,
+	Lease:      time.Minute,
+	MinBackoff: time.Second,
+	MaxBackoff: 10 * time.Second,
+	Retention:  24 * time.Hour,
+}
+
+func TestRelayOutboxEventsServices(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	t.Run("got success when relaying outbox events in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOutboxRepository)
+		publisher := events.NewMemoryEventPublisher()
+		sut := NewRelayOutboxEventsUseCase(mockRepo, publisher, outboxRelaySettings)
+
+		ctx := context.TODO()
+
+		mockRepo.On("ClaimOutboxEvents", ctx, now, time.Minute, 100).Return([]dto.OutboxEvent{
+			{Event: events.DomainEvent{ID: 1, Type: events.OrderCreated, OrderID: 10}},
+			{Event: events.DomainEvent{ID: 2, Type: events.OrderStatusChanged, OrderID: 10}},
+			{Event: events.DomainEvent{ID: 3, Type: events.OrderCreated, OrderID: 11}},
+		}, nil)
+		mockRepo.On("MarkOutboxEventDelivered", ctx, uint(1)).Return(nil)
+		mockRepo.On("MarkOutboxEventDelivered", ctx, uint(2)).Return(nil)
+		mockRepo.On("MarkOutboxEventDelivered", ctx, uint(3)).Return(nil)
+
+		err := sut.Execute(ctx, now)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+
+		published := publisher.Events()
+		assert.Equal(t, 3, len(published))
+		assert.Equal(t, uint(1), published[0].ID)
+		assert.Equal(t, uint(2), published[1].ID)
+		assert.Equal(t, uint(3), published[2].ID)
+	})
+
+	t.Run("got later events of the order held back when one fails in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOutboxRepository)
+		publisher := events.NewMemoryEventPublisher()
+		sut := NewRelayOutboxEventsUseCase(mockRepo, publisher, outboxRelaySettings)
+
+		ctx := context.TODO()
+
+		mockRepo.On("ClaimOutboxEvents", ctx, now, time.Minute, 100).Return([]dto.OutboxEvent{
+			{Event: events.DomainEvent{ID: 1, Type: events.OrderCreated, OrderID: 10}},
+			{Event: events.DomainEvent{ID: 2, Type: events.OrderStatusChanged, OrderID: 10}},
+			{Event: events.DomainEvent{ID: 3, Type: events.OrderCreated, OrderID: 11}},
+		}, nil)
+		mockRepo.On("MarkOutboxEventFailed", ctx, uint(1), events.ErrMemoryPublisherFailure.Error(), now.Add(time.Second)).Return(nil)
+		mockRepo.On("MarkOutboxEventDelivered", ctx, uint(3)).Return(nil)
+
+		publisher.FailNext(1)
+
+		err := sut.Execute(ctx, now)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "MarkOutboxEventDelivered", ctx, uint(2))
+
+		published := publisher.Events()
+		assert.Equal(t, 1, len(published))
+		assert.Equal(t, uint(11), published[0].OrderID)
+	})
+
+	t.Run("got backoff doubled and capped when the event keeps failing in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOutboxRepository)
+		publisher := events.NewMemoryEventPublisher()
+		sut := NewRelayOutboxEventsUseCase(mockRepo, publisher, outboxRelaySettings)
+
+		ctx := context.TODO()
+
+		mockRepo.On("ClaimOutboxEvents", ctx, now, time.Minute, 100).Return([]dto.OutboxEvent{
+			{Attempts: 2, Event: events.DomainEvent{ID: 1, OrderID: 10}},
+			{Attempts: 8, Event: events.DomainEvent{ID: 2, OrderID: 11}},
+		}, nil)
+		mockRepo.On("MarkOutboxEventFailed", ctx, uint(1), mock.Anything, now.Add(4*time.Second)).Return(nil)
+		mockRepo.On("MarkOutboxEventFailed", ctx, uint(2), mock.Anything, now.Add(10*time.Second)).Return(nil)
+
+		publisher.FailNext(2)
+
+		err := sut.Execute(ctx, now)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		assert.Empty(t, publisher.Events())
+	})
+
+	t.Run("got delivered after retrying in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOutboxRepository)
+		publisher := events.NewMemoryEventPublisher()
+		sut := NewRelayOutboxEventsUseCase(mockRepo, publisher, outboxRelaySettings)
+
+		ctx := context.TODO()
+		retryAt := now.Add(time.Second)
+
+		mockRepo.On("ClaimOutboxEvents", ctx, now, time.Minute, 100).Return([]dto.OutboxEvent{
+			{Event: events.DomainEvent{ID: 1, OrderID: 10}},
+		}, nil)
+		mockRepo.On("ClaimOutboxEvents", ctx, retryAt, time.Minute, 100).Return([]dto.OutboxEvent{
+			{Attempts: 1, Event: events.DomainEvent{ID: 1, OrderID: 10}},
+		}, nil)
+		mockRepo.On("MarkOutboxEventFailed", ctx, uint(1), mock.Anything, retryAt).Return(nil)
+		mockRepo.On("MarkOutboxEventDelivered", ctx, uint(1)).Return(nil)
+
+		publisher.FailNext(1)
+
+		assert.NoError(t, sut.Execute(ctx, now))
+		assert.Empty(t, publisher.Events())
+
+		assert.NoError(t, sut.Execute(ctx, retryAt))
+		assert.Equal(t, 1, len(publisher.Events()))
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("got error when claiming outbox events in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOutboxRepository)
+		sut := NewRelayOutboxEventsUseCase(mockRepo, events.NewMemoryEventPublisher(), outboxRelaySettings)
+
+		ctx := context.TODO()
+
+		mockRepo.On("ClaimOutboxEvents", ctx, now, time.Minute, 100).Return(nil, &responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "DATABASE_ERROR",
+		})
+
+		err := sut.Execute(ctx, now)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("got error when marking outbox event as delivered in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOutboxRepository)
+		sut := NewRelayOutboxEventsUseCase(mockRepo, events.NewMemoryEventPublisher(), outboxRelaySettings)
+
+		ctx := context.TODO()
+
+		mockRepo.On("ClaimOutboxEvents", ctx, now, time.Minute, 100).Return([]dto.OutboxEvent{
+			{Event: events.DomainEvent{ID: 1, OrderID: 10}},
+		}, nil)
+		mockRepo.On("MarkOutboxEventDelivered", ctx, uint(1)).Return(errors.New("connection refused"))
+
+		err := sut.Execute(ctx, now)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestPurgeOutboxEventsServices(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	t.Run("got success when purging delivered outbox events in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOutboxRepository)
+		sut := NewPurgeOutboxEventsUseCase(mockRepo, outboxRelaySettings)
+
+		ctx := context.TODO()
+
+		mockRepo.On("DeleteDeliveredOutboxEvents", ctx, now.Add(-24*time.Hour)).Return(int64(3), nil)
+
+		err := sut.Execute(ctx, now)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("got error when purging delivered outbox events in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOutboxRepository)
+		sut := NewPurgeOutboxEventsUseCase(mockRepo, outboxRelaySettings)
+
+		ctx := context.TODO()
+
+		mockRepo.On("DeleteDeliveredOutboxEvents", ctx, now.Add(-24*time.Hour)).Return(int64(0), &responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "DATABASE_ERROR",
+		})
+
+		err := sut.Execute(ctx, now)
+
+		assert.Error(t, err)
+	})
+}
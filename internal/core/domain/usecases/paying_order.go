@@ -0,0 +1,192 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+type CreatePayingOrderUseCase interface {
+	Execute(ctx context.Context, order dto.PayingOrder) (dto.OrderResponse, error)
+}
+
+type CreatePayingOrderUseCaseImpl struct {
+	orderRepo         repository.OrderRepository
+	customerRepo      repository.CustomerRepository
+	priceOrderUseCase *PriceOrderUseCase
+	publisher         events.OrderEventPublisher
+}
+
+type FinishOrderWithPaymentUseCase interface {
+	Execute(ctx context.Context, orderId uint, paymentId string, date int64) error
+}
+
+type FinishOrderWithPaymentUseCaseImpl struct {
+	orderRepo    repository.OrderRepository
+	stateMachine *statemachine.OrderStateMachine
+	publisher    events.OrderEventPublisher
+}
+
+type DeletePayingOrderUseCase interface {
+	Execute(ctx context.Context, orderId uint) error
+}
+
+type DeletePayingOrderUseCaseImpl struct {
+	orderRepo repository.OrderRepository
+}
+
+func NewCreatePayingOrderUseCase(
+	orderRepo repository.OrderRepository,
+	customerRepo repository.CustomerRepository,
+	priceOrderUseCase *PriceOrderUseCase,
+	publisher events.OrderEventPublisher,
+) CreatePayingOrderUseCase {
+	return &CreatePayingOrderUseCaseImpl{
+		orderRepo:         orderRepo,
+		customerRepo:      customerRepo,
+		priceOrderUseCase: priceOrderUseCase,
+		publisher:         publisher,
+	}
+}
+
+func NewFinishOrderWithPaymentUseCase(
+	orderRepo repository.OrderRepository,
+	stateMachine *statemachine.OrderStateMachine,
+	publisher events.OrderEventPublisher,
+) FinishOrderWithPaymentUseCase {
+	return &FinishOrderWithPaymentUseCaseImpl{
+		orderRepo:    orderRepo,
+		stateMachine: stateMachine,
+		publisher:    publisher,
+	}
+}
+
+func NewDeletePayingOrderUseCase(orderRepo repository.OrderRepository) DeletePayingOrderUseCase {
+	return &DeletePayingOrderUseCaseImpl{
+		orderRepo: orderRepo,
+	}
+}
+
+// Execute saves the order in Em pagamento status. It gets no ticket number
+// until the payment is confirmed by FinishOrderWithPaymentUseCase. The PaymentID
+// is generated here, so a client can not point a payment to another order
+func (usecase *CreatePayingOrderUseCaseImpl) Execute(ctx context.Context, payingOrder dto.PayingOrder) (dto.OrderResponse, error) {
+	paymentId, err := newPaymentId()
+
+	if err != nil {
+		return dto.OrderResponse{}, responses.GetResponseError(err, "OrderService -> CreatePayingOrder")
+	}
+
+	order, err := usecase.priceOrderUseCase.Execute(ctx, dto.Order{
+		TotalPrice:   payingOrder.TotalPrice,
+		CPF:          payingOrder.CPF,
+		PaymentID:    paymentId,
+		OrderProduct: payingOrder.OrderProduct,
+	})
+
+	if err != nil {
+		return dto.OrderResponse{}, responses.GetResponseError(err, "OrderService -> CreatePayingOrder")
+	}
+
+	response, err := usecase.orderRepo.CreatePayingOrder(ctx, order)
+
+	if err != nil {
+		return dto.OrderResponse{}, responses.GetResponseError(err, "OrderService -> CreatePayingOrder")
+	}
+
+	usecase.publisher.Publish(events.OrderEvent{
+		Type:        events.OrderCreated,
+		OrderID:     response.OrderId,
+		OrderStatus: model.OrderStatusPaying,
+	})
+
+	response.OrderStatus = model.OrderStatusPaying
+	response.PaymentStatus = model.PaymentStatusPending
+	response.TotalPrice = order.TotalPrice
+	response.PaymentID = paymentId
+
+	if order.CPF != nil {
+		customer, err := usecase.customerRepo.GetCustomerByCPF(ctx, *order.CPF)
+		if err == nil {
+			response.CustomerName = &customer.Name
+		}
+	}
+
+	return response, nil
+}
+
+func (usecase *FinishOrderWithPaymentUseCaseImpl) Execute(ctx context.Context, orderId uint, paymentId string, date int64) error {
+	order, err := usecase.orderRepo.GetOrderById(ctx, orderId)
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> FinishOrderWithPayment")
+	}
+
+	_, err = usecase.stateMachine.Transition(order.OrderStatus, model.OrderStatusCreated, statemachine.RoleFromContext(ctx))
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> FinishOrderWithPayment")
+	}
+
+	err = usecase.orderRepo.FinishOrderWithPayment(ctx, orderId, paymentId, date)
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> FinishOrderWithPayment")
+	}
+
+	usecase.publisher.Publish(events.OrderEvent{
+		Type:           events.OrderStatusChanged,
+		OrderID:        orderId,
+		PreviousStatus: model.OrderStatusPaying,
+		OrderStatus:    model.OrderStatusCreated,
+	})
+
+	return nil
+}
+
+// Execute removes an order whose payment was abandoned or expired. Orders that
+// already left Em pagamento are kept, since they may be in the kitchen
+func (usecase *DeletePayingOrderUseCaseImpl) Execute(ctx context.Context, orderId uint) error {
+	order, err := usecase.orderRepo.GetOrderById(ctx, orderId)
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> DeletePayingOrder")
+	}
+
+	if order.OrderStatus != model.OrderStatusPaying {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusPreconditionRequired,
+			Message:    fmt.Sprintf("The order must be in %v status", model.OrderStatusPaying),
+		}
+	}
+
+	err = usecase.orderRepo.DeleteOrder(ctx, orderId)
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> DeletePayingOrder")
+	}
+
+	return nil
+}
+
+// newPaymentId returns a random PIX txid. The EMV spec allows up to 25 alphanumeric characters
+func newPaymentId() (string, error) {
+	bytes := make([]byte, 12)
+
+	_, err := rand.Read(bytes)
+
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
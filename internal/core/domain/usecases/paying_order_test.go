@@ -0,0 +1,323 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestPayingOrderServices(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when creating paying order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		customerRepo := new(MockCustomerRepository)
+		productRepo := new(MockProductRepository)
+
+		sut := NewCreatePayingOrderUseCase(mockRepo, customerRepo, NewPriceOrderUseCase(productRepo, new(MockComboRepository)), events.NewOrderEventBus(10))
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1, 2}).Return(orderProducts, nil)
+		mockRepo.On("CreatePayingOrder", ctx, mock.MatchedBy(func(order dto.Order) bool {
+			expected := pricedOrder(dto.Order{
+				TotalPrice:   payingOrderCreation.TotalPrice,
+				PaymentID:    order.PaymentID,
+				OrderProduct: payingOrderCreation.OrderProduct,
+			})
+
+			return len(order.PaymentID) == 24 && assert.ObjectsAreEqual(expected, order)
+		})).Return(dto.OrderResponse{
+			OrderId: 1,
+		}, nil)
+
+		response, err := sut.Execute(ctx, payingOrderCreation)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), response.OrderId)
+		assert.Len(t, response.PaymentID, 24)
+		assert.Equal(t, "Em pagamento", response.OrderStatus)
+		assert.Equal(t, 0, response.TicketNumber)
+		assert.Equal(t, float64(12345), response.TotalPrice)
+	})
+
+	t.Run("got error when creating paying order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		customerRepo := new(MockCustomerRepository)
+		productRepo := new(MockProductRepository)
+
+		sut := NewCreatePayingOrderUseCase(mockRepo, customerRepo, NewPriceOrderUseCase(productRepo, new(MockComboRepository)), events.NewOrderEventBus(10))
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1, 2}).Return(orderProducts, nil)
+		mockRepo.On("CreatePayingOrder", ctx, mock.Anything).Return(dto.OrderResponse{}, &responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "DB Error",
+		})
+
+		response, err := sut.Execute(ctx, payingOrderCreation)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusServiceUnavailable, businessError.StatusCode)
+	})
+
+	t.Run("got success when finishing order with payment in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleSystem)
+
+		date := time.Now().UnixMilli()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Em pagamento",
+		}, nil)
+		mockRepo.On("FinishOrderWithPayment", ctx, uint(1), "12345", date).Return(nil)
+
+		err := sut.Execute(ctx, uint(1), "12345", date)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got success when a manager finishes order with payment in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleManager)
+
+		date := time.Now().UnixMilli()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Em pagamento",
+		}, nil)
+		mockRepo.On("FinishOrderWithPayment", ctx, uint(1), "12345", date).Return(nil)
+
+		err := sut.Execute(ctx, uint(1), "12345", date)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got forbidden when a waiter finishes order with payment in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleWaiter)
+
+		date := time.Now().UnixMilli()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Em pagamento",
+		}, nil)
+
+		err := sut.Execute(ctx, uint(1), "12345", date)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusForbidden, businessError.StatusCode)
+		mockRepo.AssertNotCalled(t, "FinishOrderWithPayment", ctx, uint(1), "12345", date)
+	})
+
+	t.Run("got error when finishing order already created in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleSystem)
+
+		date := time.Now().UnixMilli()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+		}, nil)
+
+		err := sut.Execute(ctx, uint(1), "12345", date)
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusPreconditionRequired, businessError.StatusCode)
+		mockRepo.AssertNotCalled(t, "FinishOrderWithPayment", ctx, uint(1), "12345", date)
+	})
+
+	t.Run("got error on FinishOrderWithPayment Repository when finishing order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleSystem)
+
+		date := time.Now().UnixMilli()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Em pagamento",
+		}, nil)
+		mockRepo.On("FinishOrderWithPayment", ctx, uint(1), "12345", date).Return(&responses.LocalError{
+			Code:    responses.TICKET_ALLOCATION_ERROR,
+			Message: "could not allocate a ticket number",
+		})
+
+		err := sut.Execute(ctx, uint(1), "12345", date)
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusServiceUnavailable, businessError.StatusCode)
+	})
+
+	t.Run("got conflict and no event when another confirmation finished the order first in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		bus := events.NewOrderEventBus(10)
+		subscription, _, _ := bus.Subscribe(0)
+
+		sut := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), bus)
+
+		ctx := statemachine.WithRole(context.TODO(), statemachine.RoleSystem)
+
+		date := time.Now().UnixMilli()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Em pagamento",
+		}, nil)
+		mockRepo.On("FinishOrderWithPayment", ctx, uint(1), "12345", date).Return(&responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: "The order is no longer in Em pagamento status",
+		})
+
+		err := sut.Execute(ctx, uint(1), "12345", date)
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
+
+		// the bus delivers synchronously, so an event would already be buffered
+		assert.Empty(t, subscription.Events())
+	})
+
+	t.Run("got success when deleting paying order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewDeletePayingOrderUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Em pagamento",
+		}, nil)
+		mockRepo.On("DeleteOrder", ctx, uint(1)).Return(nil)
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got conflict when deleting order paid meanwhile in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewDeletePayingOrderUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Em pagamento",
+		}, nil)
+		mockRepo.On("DeleteOrder", ctx, uint(1)).Return(&responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: "The order is no longer in Em pagamento status",
+		})
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
+	})
+
+	t.Run("got error when deleting order already paid in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewDeletePayingOrderUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Preparando",
+		}, nil)
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusPreconditionRequired, businessError.StatusCode)
+		mockRepo.AssertNotCalled(t, "DeleteOrder", ctx, uint(1))
+	})
+
+	t.Run("got error when deleting unknown paying order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewDeletePayingOrderUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Order not found",
+		})
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+}
@@ -0,0 +1,187 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+// Payment status sent by the payment gateway notifications
+const (
+	PaymentNotificationApproved = "approved"
+	PaymentNotificationRejected = "rejected"
+	PaymentNotificationCanceled = "canceled"
+)
+
+type ProcessPaymentNotificationUseCase interface {
+	Execute(ctx context.Context, notification dto.PaymentNotification, date int64) error
+}
+
+type ProcessPaymentNotificationUseCaseImpl struct {
+	orderRepo              repository.OrderRepository
+	finishOrderWithPayment FinishOrderWithPaymentUseCase
+}
+
+func NewProcessPaymentNotificationUseCase(
+	orderRepo repository.OrderRepository,
+	finishOrderWithPayment FinishOrderWithPaymentUseCase,
+) ProcessPaymentNotificationUseCase {
+	return &ProcessPaymentNotificationUseCaseImpl{
+		orderRepo:              orderRepo,
+		finishOrderWithPayment: finishOrderWithPayment,
+	}
+}
+
+// Execute applies a payment gateway notification to the order with the same PaymentID.
+// The gateway retries notifications until it gets a success response, so a replayed
+// notification for an order already paid with this PaymentID is accepted without changes.
+// A payment approved for an order that is no longer waiting for it, e.g. canceled while
+// the customer was paying or expired before the PIX was paid, gets a pending refund instead
+func (usecase *ProcessPaymentNotificationUseCaseImpl) Execute(
+	ctx context.Context,
+	notification dto.PaymentNotification,
+	date int64,
+) error {
+	order, err := usecase.orderRepo.GetOrderByPaymentId(ctx, notification.PaymentID)
+
+	var localError *responses.LocalError
+
+	if errors.As(err, &localError) && localError.Code == responses.NOT_FOUND_ERROR {
+		return usecase.settleExpiredPayment(ctx, notification)
+	}
+
+	if err != nil {
+		return responses.GetResponseError(err, "PaymentService -> ProcessPaymentNotification")
+	}
+
+	if order.OrderStatus != model.OrderStatusPaying {
+		return usecase.settlePayment(ctx, order, notification)
+	}
+
+	switch notification.Status {
+	case PaymentNotificationApproved:
+		err = usecase.finishOrderWithPayment.Execute(
+			statemachine.WithRole(ctx, statemachine.RoleSystem),
+			order.OrderId,
+			notification.PaymentID,
+			date,
+		)
+
+		// the order left Em pagamento after it was read, either confirmed by another
+		// notification for the same payment or canceled
+		var businessError *responses.BusinessResponse
+
+		if errors.As(err, &businessError) && businessError.StatusCode == http.StatusConflict {
+			order, err = usecase.orderRepo.GetOrderByPaymentId(ctx, notification.PaymentID)
+
+			if err != nil {
+				return responses.GetResponseError(err, "PaymentService -> ProcessPaymentNotification")
+			}
+
+			return usecase.settlePayment(ctx, order, notification)
+		}
+	case PaymentNotificationRejected, PaymentNotificationCanceled:
+		if order.PaymentStatus == model.PaymentStatusFailed {
+			return nil
+		}
+
+		err = usecase.orderRepo.UpdatePaymentStatus(ctx, order.OrderId, model.PaymentStatusFailed)
+	default:
+		return unknownPaymentStatusError(notification.Status)
+	}
+
+	if err != nil {
+		return responses.GetResponseError(err, "PaymentService -> ProcessPaymentNotification")
+	}
+
+	return nil
+}
+
+// settlePayment handles a notification for an order that already left Em pagamento.
+// It is a replay when the order was paid with this PaymentID, otherwise an approved
+// payment is refunded, as CancelOrder does for paid orders
+func (usecase *ProcessPaymentNotificationUseCaseImpl) settlePayment(
+	ctx context.Context,
+	order dto.OrderResponse,
+	notification dto.PaymentNotification,
+) error {
+	switch notification.Status {
+	case PaymentNotificationApproved:
+		if order.PaymentStatus == model.PaymentStatusApproved {
+			log.Print("payment notification replayed", map[string]interface{}{
+				"paymentId": notification.PaymentID,
+				"status":    notification.Status,
+			})
+			return nil
+		}
+
+		err := usecase.orderRepo.RefundPayment(ctx, notification.PaymentID)
+
+		if err != nil {
+			return responses.GetResponseError(err, "PaymentService -> ProcessPaymentNotification")
+		}
+
+		log.Print("payment refunded for an order not waiting payment", map[string]interface{}{
+			"orderId":     order.OrderId,
+			"orderStatus": order.OrderStatus,
+			"paymentId":   notification.PaymentID,
+		})
+
+		return nil
+	case PaymentNotificationRejected, PaymentNotificationCanceled:
+		log.Print("payment notification replayed", map[string]interface{}{
+			"paymentId": notification.PaymentID,
+			"status":    notification.Status,
+		})
+		return nil
+	default:
+		return unknownPaymentStatusError(notification.Status)
+	}
+}
+
+// settleExpiredPayment handles a notification for an order not found by its PaymentID.
+// Paying orders are deleted when the QR Code expires, but the PIX payload can still be
+// paid, so an approved payment is refunded and the gateway stops sending it.
+// RefundPayment also looks for the deleted orders and fails when the PaymentID is unknown
+func (usecase *ProcessPaymentNotificationUseCaseImpl) settleExpiredPayment(
+	ctx context.Context,
+	notification dto.PaymentNotification,
+) error {
+	switch notification.Status {
+	case PaymentNotificationApproved:
+		err := usecase.orderRepo.RefundPayment(ctx, notification.PaymentID)
+
+		if err != nil {
+			return responses.GetResponseError(err, "PaymentService -> ProcessPaymentNotification")
+		}
+
+		log.Print("payment refunded for an expired order", map[string]interface{}{
+			"paymentId": notification.PaymentID,
+		})
+
+		return nil
+	case PaymentNotificationRejected, PaymentNotificationCanceled:
+		log.Print("payment notification of expired order ignored", map[string]interface{}{
+			"paymentId": notification.PaymentID,
+			"status":    notification.Status,
+		})
+		return nil
+	default:
+		return unknownPaymentStatusError(notification.Status)
+	}
+}
+
+func unknownPaymentStatusError(status string) error {
+	return &responses.BusinessResponse{
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    fmt.Sprintf("Unknown payment status %v", status),
+	}
+}
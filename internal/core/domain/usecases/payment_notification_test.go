@@ -0,0 +1,405 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestProcessPaymentNotificationServices(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when processing approved payment notification in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		finishOrderWithPayment := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		sut := NewProcessPaymentNotificationUseCase(mockRepo, finishOrderWithPayment)
+
+		ctx := context.TODO()
+
+		date := time.Now().UnixMilli()
+
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{
+			OrderId:     uint(1),
+			OrderStatus: "Em pagamento",
+		}, nil)
+		mockRepo.On("GetOrderById", mock.Anything, uint(1)).Return(dto.OrderResponse{
+			OrderId:     uint(1),
+			OrderStatus: "Em pagamento",
+		}, nil)
+		mockRepo.On("FinishOrderWithPayment", mock.Anything, uint(1), "12345", date).Return(nil)
+
+		err := sut.Execute(ctx, dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    PaymentNotificationApproved,
+		}, date)
+
+		assert.NoError(t, err)
+		mockRepo.AssertCalled(t, "FinishOrderWithPayment", mock.Anything, uint(1), "12345", date)
+	})
+
+	t.Run("got success when processing replayed approved payment notification in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		finishOrderWithPayment := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		sut := NewProcessPaymentNotificationUseCase(mockRepo, finishOrderWithPayment)
+
+		ctx := context.TODO()
+
+		date := time.Now().UnixMilli()
+
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{
+			OrderId:       uint(1),
+			OrderStatus:   "Criado",
+			PaymentStatus: "Aprovado",
+		}, nil)
+
+		err := sut.Execute(ctx, dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    PaymentNotificationApproved,
+		}, date)
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "FinishOrderWithPayment", mock.Anything, uint(1), "12345", date)
+	})
+
+	t.Run("got success when approved payment notification races with another one in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		bus := events.NewOrderEventBus(10)
+		subscription, _, _ := bus.Subscribe(0)
+		finishOrderWithPayment := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), bus)
+
+		sut := NewProcessPaymentNotificationUseCase(mockRepo, finishOrderWithPayment)
+
+		ctx := context.TODO()
+
+		date := time.Now().UnixMilli()
+
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{
+			OrderId:     uint(1),
+			OrderStatus: "Em pagamento",
+		}, nil).Once()
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{
+			OrderId:       uint(1),
+			OrderStatus:   "Criado",
+			PaymentStatus: "Aprovado",
+		}, nil).Once()
+		mockRepo.On("GetOrderById", mock.Anything, uint(1)).Return(dto.OrderResponse{
+			OrderId:     uint(1),
+			OrderStatus: "Em pagamento",
+		}, nil)
+		mockRepo.On("FinishOrderWithPayment", mock.Anything, uint(1), "12345", date).Return(&responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: "The order is no longer in Em pagamento status",
+		})
+
+		err := sut.Execute(ctx, dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    PaymentNotificationApproved,
+		}, date)
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "RefundPayment", ctx, "12345")
+
+		// the bus delivers synchronously, so an event would already be buffered
+		assert.Empty(t, subscription.Events())
+	})
+
+	t.Run("got success when refunding approved payment of order canceled while paying in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		finishOrderWithPayment := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		sut := NewProcessPaymentNotificationUseCase(mockRepo, finishOrderWithPayment)
+
+		ctx := context.TODO()
+
+		date := time.Now().UnixMilli()
+
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{
+			OrderId:       uint(1),
+			OrderStatus:   "Cancelado",
+			PaymentStatus: "Pendente",
+		}, nil)
+		mockRepo.On("RefundPayment", ctx, "12345").Return(nil)
+
+		err := sut.Execute(ctx, dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    PaymentNotificationApproved,
+		}, date)
+
+		assert.NoError(t, err)
+		mockRepo.AssertCalled(t, "RefundPayment", ctx, "12345")
+		mockRepo.AssertNotCalled(t, "FinishOrderWithPayment", mock.Anything, uint(1), "12345", date)
+	})
+
+	t.Run("got success when refunding approved payment of order canceled during the confirmation in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		finishOrderWithPayment := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		sut := NewProcessPaymentNotificationUseCase(mockRepo, finishOrderWithPayment)
+
+		ctx := context.TODO()
+
+		date := time.Now().UnixMilli()
+
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{
+			OrderId:     uint(1),
+			OrderStatus: "Em pagamento",
+		}, nil).Once()
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{
+			OrderId:       uint(1),
+			OrderStatus:   "Cancelado",
+			PaymentStatus: "Pendente",
+		}, nil).Once()
+		mockRepo.On("GetOrderById", mock.Anything, uint(1)).Return(dto.OrderResponse{
+			OrderId:     uint(1),
+			OrderStatus: "Em pagamento",
+		}, nil)
+		mockRepo.On("FinishOrderWithPayment", mock.Anything, uint(1), "12345", date).Return(&responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: "The order is no longer in Em pagamento status",
+		})
+		mockRepo.On("RefundPayment", ctx, "12345").Return(nil)
+
+		err := sut.Execute(ctx, dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    PaymentNotificationApproved,
+		}, date)
+
+		assert.NoError(t, err)
+		mockRepo.AssertCalled(t, "RefundPayment", ctx, "12345")
+	})
+
+	t.Run("got success when processing replayed payment notification of paid canceled order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		finishOrderWithPayment := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		sut := NewProcessPaymentNotificationUseCase(mockRepo, finishOrderWithPayment)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{
+			OrderId:       uint(1),
+			OrderStatus:   "Cancelado",
+			PaymentStatus: "Aprovado",
+		}, nil)
+
+		err := sut.Execute(ctx, dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    PaymentNotificationApproved,
+		}, time.Now().UnixMilli())
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "RefundPayment", ctx, "12345")
+	})
+
+	t.Run("got error when refunding approved payment of canceled order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		finishOrderWithPayment := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		sut := NewProcessPaymentNotificationUseCase(mockRepo, finishOrderWithPayment)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{
+			OrderId:       uint(1),
+			OrderStatus:   "Cancelado",
+			PaymentStatus: "Pendente",
+		}, nil)
+		mockRepo.On("RefundPayment", ctx, "12345").Return(&responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "service unavailable",
+		})
+
+		err := sut.Execute(ctx, dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    PaymentNotificationApproved,
+		}, time.Now().UnixMilli())
+
+		assert.Error(t, err)
+	})
+
+	t.Run("got success when processing rejected payment notification in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		finishOrderWithPayment := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		sut := NewProcessPaymentNotificationUseCase(mockRepo, finishOrderWithPayment)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{
+			OrderId:       uint(1),
+			OrderStatus:   "Em pagamento",
+			PaymentStatus: "Pendente",
+		}, nil)
+		mockRepo.On("UpdatePaymentStatus", ctx, uint(1), "Recusado").Return(nil)
+
+		err := sut.Execute(ctx, dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    PaymentNotificationRejected,
+		}, time.Now().UnixMilli())
+
+		assert.NoError(t, err)
+		mockRepo.AssertCalled(t, "UpdatePaymentStatus", ctx, uint(1), "Recusado")
+	})
+
+	t.Run("got success when processing replayed rejected payment notification in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		finishOrderWithPayment := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		sut := NewProcessPaymentNotificationUseCase(mockRepo, finishOrderWithPayment)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{
+			OrderId:       uint(1),
+			OrderStatus:   "Em pagamento",
+			PaymentStatus: "Recusado",
+		}, nil)
+
+		err := sut.Execute(ctx, dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    PaymentNotificationRejected,
+		}, time.Now().UnixMilli())
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "UpdatePaymentStatus", ctx, uint(1), "Recusado")
+	})
+
+	t.Run("got error when processing unknown payment status in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		finishOrderWithPayment := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		sut := NewProcessPaymentNotificationUseCase(mockRepo, finishOrderWithPayment)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{
+			OrderId:     uint(1),
+			OrderStatus: "Em pagamento",
+		}, nil)
+
+		err := sut.Execute(ctx, dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    "in_process",
+		}, time.Now().UnixMilli())
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when processing payment notification of unknown order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		finishOrderWithPayment := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		sut := NewProcessPaymentNotificationUseCase(mockRepo, finishOrderWithPayment)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Order not found",
+		})
+		mockRepo.On("RefundPayment", ctx, "12345").Return(&responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "record not found",
+		})
+
+		err := sut.Execute(ctx, dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    PaymentNotificationApproved,
+		}, time.Now().UnixMilli())
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
+	t.Run("got success when refunding approved payment of expired order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		finishOrderWithPayment := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		sut := NewProcessPaymentNotificationUseCase(mockRepo, finishOrderWithPayment)
+
+		ctx := context.TODO()
+
+		date := time.Now().UnixMilli()
+
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Order not found",
+		})
+		mockRepo.On("RefundPayment", ctx, "12345").Return(nil)
+
+		err := sut.Execute(ctx, dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    PaymentNotificationApproved,
+		}, date)
+
+		assert.NoError(t, err)
+		mockRepo.AssertCalled(t, "RefundPayment", ctx, "12345")
+		mockRepo.AssertNotCalled(t, "FinishOrderWithPayment", mock.Anything, mock.Anything, "12345", date)
+	})
+
+	t.Run("got success when processing rejected payment notification of expired order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		finishOrderWithPayment := NewFinishOrderWithPaymentUseCase(mockRepo, statemachine.NewOrderStateMachine(), events.NewOrderEventBus(10))
+
+		sut := NewProcessPaymentNotificationUseCase(mockRepo, finishOrderWithPayment)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderByPaymentId", ctx, "12345").Return(dto.OrderResponse{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Order not found",
+		})
+
+		err := sut.Execute(ctx, dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    PaymentNotificationRejected,
+		}, time.Now().UnixMilli())
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "RefundPayment", ctx, "12345")
+	})
+}
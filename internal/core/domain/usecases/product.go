@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/repository"
 	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
@@ -14,8 +15,9 @@ type CreateProductUseCase interface {
 }
 
 type CreateProductUseCaseImpl struct {
-	repository      repository.ProductRepository
-	validateUseCase *ValidateProductCategoryUseCase
+	repository         repository.ProductRepository
+	categoryRepository repository.CategoryRepository
+	validateUseCase    *ValidateProductCategoryUseCase
 }
 
 type GetProductsByCategoryUseCase interface {
@@ -42,26 +44,42 @@ type DeleteProductUseCaseImpl struct {
 	repository repository.ProductRepository
 }
 
+type RestoreProductUseCase interface {
+	Execute(ctx context.Context, productId uint) error
+}
+
+type RestoreProductUseCaseImpl struct {
+	repository repository.ProductRepository
+}
+
 type UpdateProductUseCase interface {
 	Execute(ctx context.Context, product dto.ProductForm) error
 }
 
 type UpdateProductUseCaseImpl struct {
-	repository repository.ProductRepository
+	repository         repository.ProductRepository
+	categoryRepository repository.CategoryRepository
+	validateUseCase    *ValidateProductCategoryUseCase
 }
 
-type GetCategoriesUseCase interface {
-	Execute() []string
+type PatchProductUseCase interface {
+	Execute(ctx context.Context, productId uint, patch dto.ProductPatchForm) error
 }
 
-type GetCategoriesUseCaseImpl struct {
-	repository repository.ProductRepository
+type PatchProductUseCaseImpl struct {
+	repository    repository.ProductRepository
+	updateUseCase UpdateProductUseCase
 }
 
-func NewCreateProductUseCase(validateUseCase *ValidateProductCategoryUseCase, repository repository.ProductRepository) CreateProductUseCase {
+func NewCreateProductUseCase(
+	validateUseCase *ValidateProductCategoryUseCase,
+	repository repository.ProductRepository,
+	categoryRepository repository.CategoryRepository,
+) CreateProductUseCase {
 	return &CreateProductUseCaseImpl{
-		repository:      repository,
-		validateUseCase: validateUseCase,
+		repository:         repository,
+		categoryRepository: categoryRepository,
+		validateUseCase:    validateUseCase,
 	}
 }
 
@@ -83,24 +101,39 @@ func NewDeleteProductUseCase(repository repository.ProductRepository) DeleteProd
 	}
 }
 
-func NewUpdateProductUseCase(repository repository.ProductRepository) UpdateProductUseCase {
-	return &UpdateProductUseCaseImpl{
+func NewRestoreProductUseCase(repository repository.ProductRepository) RestoreProductUseCase {
+	return &RestoreProductUseCaseImpl{
 		repository: repository,
 	}
 }
 
-func NewGetCategoriesUseCase(repository repository.ProductRepository) GetCategoriesUseCase {
-	return &GetCategoriesUseCaseImpl{
-		repository: repository,
+func NewUpdateProductUseCase(
+	validateUseCase *ValidateProductCategoryUseCase,
+	repository repository.ProductRepository,
+	categoryRepository repository.CategoryRepository,
+) UpdateProductUseCase {
+	return &UpdateProductUseCaseImpl{
+		repository:         repository,
+		categoryRepository: categoryRepository,
+		validateUseCase:    validateUseCase,
+	}
+}
+
+func NewPatchProductUseCase(
+	repository repository.ProductRepository,
+	updateUseCase UpdateProductUseCase,
+) PatchProductUseCase {
+	return &PatchProductUseCaseImpl{
+		repository:    repository,
+		updateUseCase: updateUseCase,
 	}
 }
 
 func (service *CreateProductUseCaseImpl) Execute(ctx context.Context, product dto.ProductForm) (uint, error) {
-	if !service.validateUseCase.Execute(product) {
-		return 0, &responses.BusinessResponse{
-			StatusCode: http.StatusBadRequest,
-			Message:    "Combo needs products",
-		}
+	err := validateProduct(ctx, service.validateUseCase, service.categoryRepository, product)
+
+	if err != nil {
+		return 0, err
 	}
 
 	productId, err := service.repository.CreateProduct(ctx, product)
@@ -132,8 +165,19 @@ func (service *GetProductByIdUseCaseImpl) Execute(ctx context.Context, id uint)
 	return products, nil
 }
 
+// Execute archives the product instead of deleting it, the orders with it still need to show it
 func (service *DeleteProductUseCaseImpl) Execute(ctx context.Context, productId uint) error {
-	err := service.repository.DeleteProduct(ctx, productId)
+	err := service.repository.ArchiveProduct(ctx, productId)
+
+	if err != nil {
+		return responses.GetResponseError(err, "ProductService")
+	}
+
+	return nil
+}
+
+func (service *RestoreProductUseCaseImpl) Execute(ctx context.Context, productId uint) error {
+	err := service.repository.RestoreProduct(ctx, productId)
 
 	if err != nil {
 		return responses.GetResponseError(err, "ProductService")
@@ -143,7 +187,13 @@ func (service *DeleteProductUseCaseImpl) Execute(ctx context.Context, productId
 }
 
 func (service *UpdateProductUseCaseImpl) Execute(ctx context.Context, product dto.ProductForm) error {
-	err := service.repository.UpdateProduct(ctx, product)
+	err := service.validateProductUpdate(ctx, product)
+
+	if err != nil {
+		return err
+	}
+
+	err = service.repository.UpdateProduct(ctx, product)
 
 	if err != nil {
 		return responses.GetResponseError(err, "ProductService")
@@ -152,6 +202,93 @@ func (service *UpdateProductUseCaseImpl) Execute(ctx context.Context, product dt
 	return nil
 }
 
-func (service *GetCategoriesUseCaseImpl) Execute() []string {
-	return service.repository.GetCategories()
+// Execute applies the patch over the saved product and updates it like a full form,
+// so the patched product goes through the same validations
+func (service *PatchProductUseCaseImpl) Execute(ctx context.Context, productId uint, patch dto.ProductPatchForm) error {
+	product, err := service.repository.GetProductById(ctx, productId)
+
+	if err != nil {
+		return responses.GetResponseError(err, "ProductService")
+	}
+
+	return service.updateUseCase.Execute(ctx, applyProductPatch(product, patch))
+}
+
+// applyProductPatch builds the form from the saved product. The combo products are only sent
+// when the patch has them, so the combo keeps the products, or the slots, it has
+func applyProductPatch(product dto.ProductResponse, patch dto.ProductPatchForm) dto.ProductForm {
+	form := dto.ProductForm{
+		Id:          product.Id,
+		Name:        product.Name,
+		Description: product.Description,
+		Category:    product.Category,
+		Price:       product.Price,
+		Images:      product.Images,
+	}
+
+	if patch.Name != nil {
+		form.Name = *patch.Name
+	}
+
+	if patch.Description != nil {
+		form.Description = *patch.Description
+	}
+
+	if patch.Category != nil {
+		form.Category = *patch.Category
+	}
+
+	if patch.Price != nil {
+		form.Price = *patch.Price
+	}
+
+	if patch.Images != nil {
+		form.Images = *patch.Images
+	}
+
+	if patch.ComboProductsIds != nil {
+		form.ComboProductsIds = patch.ComboProductsIds
+	}
+
+	return form
+}
+
+// validateProduct checks a product form before it is created or updated
+func validateProduct(
+	ctx context.Context,
+	validateUseCase *ValidateProductCategoryUseCase,
+	categoryRepository repository.CategoryRepository,
+	product dto.ProductForm,
+) error {
+	if !validateUseCase.Execute(product) {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Combo needs products",
+		}
+	}
+
+	return validateCategoryExists(ctx, categoryRepository, product.Category)
+}
+
+// validateProductUpdate checks the form like validateProduct. A combo sent without
+// comboProductsIds keeps the products, or the slots, it already has
+func (service *UpdateProductUseCaseImpl) validateProductUpdate(ctx context.Context, product dto.ProductForm) error {
+	if product.Category != model.CategoryCombo || product.ComboProductsIds != nil {
+		return validateProduct(ctx, service.validateUseCase, service.categoryRepository, product)
+	}
+
+	hasComponents, err := service.repository.HasComboComponents(ctx, product.Id)
+
+	if err != nil {
+		return responses.GetResponseError(err, "ProductService")
+	}
+
+	if !hasComponents {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Combo needs products",
+		}
+	}
+
+	return validateCategoryExists(ctx, service.categoryRepository, product.Category)
 }
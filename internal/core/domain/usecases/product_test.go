@@ -18,21 +18,6 @@ var (
 func TestProductsUseCase(t *testing.T) {
 	t.Parallel()
 
-	t.Run("got success when getting product categories in services", func(t *testing.T) {
-		t.Parallel()
-
-		mockRepo := new(MockProductRepository)
-		sut := NewGetCategoriesUseCase(mockRepo)
-
-		mockRepo.On("GetCategories").Return([]string{"Combo", "Bebidas", "Lanches"})
-
-		response := sut.Execute()
-
-		assert.NotEmpty(t, response)
-
-		assert.Equal(t, 3, len(response))
-	})
-
 	t.Run("got success when getting products by category in services", func(t *testing.T) {
 		t.Parallel()
 
@@ -127,10 +112,12 @@ func TestProductsUseCase(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockProductRepository)
-		sut := NewCreateProductUseCase(uc, mockRepo)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewCreateProductUseCase(uc, mockRepo, categoryRepo)
 
 		ctx := context.TODO()
 
+		categoryRepo.On("GetCategoryByName", ctx, "Category").Return(dto.CategoryResponse{Id: 1, Name: "Category"}, nil)
 		mockRepo.On("CreateProduct", ctx, productCreation).Return(uint(1), nil)
 
 		response, err := sut.Execute(ctx, productCreation)
@@ -147,10 +134,12 @@ func TestProductsUseCase(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockProductRepository)
-		sut := NewCreateProductUseCase(uc, mockRepo)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewCreateProductUseCase(uc, mockRepo, categoryRepo)
 
 		ctx := context.TODO()
 
+		categoryRepo.On("GetCategoryByName", ctx, "Category").Return(dto.CategoryResponse{Id: 1, Name: "Category"}, nil)
 		mockRepo.On("CreateProduct", ctx, productCreation).Return(uint(0), &responses.LocalError{
 			Code:    3,
 			Message: "DATABASE_CONFLICT_ERROR",
@@ -168,6 +157,54 @@ func TestProductsUseCase(t *testing.T) {
 		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
 	})
 
+	t.Run("got bad request when creating product with unknown category in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewCreateProductUseCase(uc, mockRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategoryByName", ctx, "Category").Return(dto.CategoryResponse{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "record not found",
+		})
+
+		response, err := sut.Execute(ctx, productCreation)
+
+		categoryRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CreateProduct", ctx, productCreation)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got error when looking up the category fails in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewCreateProductUseCase(uc, mockRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategoryByName", ctx, "Category").Return(dto.CategoryResponse{}, &responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "service unavailable",
+		})
+
+		_, err := sut.Execute(ctx, productCreation)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusServiceUnavailable, businessError.StatusCode)
+	})
+
 	t.Run("got success when deleting product in services", func(t *testing.T) {
 		t.Parallel()
 
@@ -176,7 +213,7 @@ func TestProductsUseCase(t *testing.T) {
 
 		ctx := context.TODO()
 
-		mockRepo.On("DeleteProduct", ctx, uint(12)).Return(nil)
+		mockRepo.On("ArchiveProduct", ctx, uint(12)).Return(nil)
 
 		err := sut.Execute(ctx, uint(12))
 
@@ -193,7 +230,7 @@ func TestProductsUseCase(t *testing.T) {
 
 		ctx := context.TODO()
 
-		mockRepo.On("DeleteProduct", ctx, uint(12)).Return(&responses.LocalError{
+		mockRepo.On("ArchiveProduct", ctx, uint(12)).Return(&responses.LocalError{
 			Code:    3,
 			Message: "DATABASE_CONFLICT_ERROR",
 		})
@@ -209,14 +246,56 @@ func TestProductsUseCase(t *testing.T) {
 		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
 	})
 
+	t.Run("got success when restoring product in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockProductRepository)
+		sut := NewRestoreProductUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("RestoreProduct", ctx, uint(12)).Return(nil)
+
+		err := sut.Execute(ctx, uint(12))
+
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got error when restoring combo with archived products in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockProductRepository)
+		sut := NewRestoreProductUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("RestoreProduct", ctx, uint(12)).Return(&responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: "Combo has the archived products Soda (2). Restore them first",
+		})
+
+		err := sut.Execute(ctx, uint(12))
+
+		mockRepo.AssertExpectations(t)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
+		assert.Contains(t, businessError.Message, "Soda (2)")
+	})
+
 	t.Run("got success when updating product in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockProductRepository)
-		sut := NewUpdateProductUseCase(mockRepo)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewUpdateProductUseCase(uc, mockRepo, categoryRepo)
 
 		ctx := context.TODO()
 
+		categoryRepo.On("GetCategoryByName", ctx, "Category").Return(dto.CategoryResponse{Id: 1, Name: "Category"}, nil)
 		mockRepo.On("UpdateProduct", ctx, productUpdate).Return(nil)
 
 		err := sut.Execute(ctx, productUpdate)
@@ -230,10 +309,12 @@ func TestProductsUseCase(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockProductRepository)
-		sut := NewUpdateProductUseCase(mockRepo)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewUpdateProductUseCase(uc, mockRepo, categoryRepo)
 
 		ctx := context.TODO()
 
+		categoryRepo.On("GetCategoryByName", ctx, "Category").Return(dto.CategoryResponse{Id: 1, Name: "Category"}, nil)
 		mockRepo.On("UpdateProduct", ctx, productUpdate).Return(&responses.LocalError{
 			Code:    3,
 			Message: "DATABASE_CONFLICT_ERROR",
@@ -249,4 +330,249 @@ func TestProductsUseCase(t *testing.T) {
 		assert.Equal(t, true, errors.As(err, &businessError))
 		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
 	})
+
+	t.Run("got bad request when updating combo without products in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewUpdateProductUseCase(uc, mockRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		combo := productUpdate
+		combo.Category = "Combo"
+
+		mockRepo.On("HasComboComponents", ctx, combo.Id).Return(false, nil)
+
+		err := sut.Execute(ctx, combo)
+
+		mockRepo.AssertNotCalled(t, "UpdateProduct")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got success when updating slot combo without combo products in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewUpdateProductUseCase(uc, mockRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		combo := productUpdate
+		combo.Category = "Combo"
+
+		mockRepo.On("HasComboComponents", ctx, combo.Id).Return(true, nil)
+		categoryRepo.On("GetCategoryByName", ctx, "Combo").Return(dto.CategoryResponse{Id: 1, Name: "Combo"}, nil)
+		mockRepo.On("UpdateProduct", ctx, combo).Return(nil)
+
+		err := sut.Execute(ctx, combo)
+
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got conflict when updating slot combo with combo products in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewUpdateProductUseCase(uc, mockRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		combo := productUpdate
+		combo.Category = "Combo"
+		combo.ComboProductsIds = &[]uint{3}
+
+		categoryRepo.On("GetCategoryByName", ctx, "Combo").Return(dto.CategoryResponse{Id: 1, Name: "Combo"}, nil)
+		mockRepo.On("UpdateProduct", ctx, combo).Return(&responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: "Combo has slots, it must be updated by the combo endpoints",
+		})
+
+		err := sut.Execute(ctx, combo)
+
+		mockRepo.AssertNotCalled(t, "HasComboComponents")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
+	})
+
+	t.Run("got bad request when updating product with unknown category in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewUpdateProductUseCase(uc, mockRepo, categoryRepo)
+
+		ctx := context.TODO()
+
+		categoryRepo.On("GetCategoryByName", ctx, "Category").Return(dto.CategoryResponse{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "record not found",
+		})
+
+		err := sut.Execute(ctx, productUpdate)
+
+		categoryRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "UpdateProduct")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got success when patching product in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewPatchProductUseCase(mockRepo, NewUpdateProductUseCase(uc, mockRepo, categoryRepo))
+
+		ctx := context.TODO()
+
+		price := float64(1990)
+		images := []dto.ProducImage{{ImageUrl: "newImageUrl"}}
+
+		patched := productUpdate
+		patched.Price = price
+		patched.Images = images
+
+		mockRepo.On("GetProductById", ctx, uint(12)).Return(productsByCategory[0], nil)
+		categoryRepo.On("GetCategoryByName", ctx, "Category").Return(dto.CategoryResponse{Id: 1, Name: "Category"}, nil)
+		mockRepo.On("UpdateProduct", ctx, patched).Return(nil)
+
+		err := sut.Execute(ctx, uint(12), dto.ProductPatchForm{
+			Price:  &price,
+			Images: &images,
+		})
+
+		mockRepo.AssertExpectations(t)
+		categoryRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got success when patching combo keeping its products in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewPatchProductUseCase(mockRepo, NewUpdateProductUseCase(uc, mockRepo, categoryRepo))
+
+		ctx := context.TODO()
+
+		name := "New Combo"
+
+		combo := productsByCategory[0]
+		combo.Category = "Combo"
+		combo.ComboProducts = &[]dto.ProductResponse{{Id: 3}, {Id: 4}}
+
+		patched := productUpdate
+		patched.Name = name
+		patched.Category = "Combo"
+
+		mockRepo.On("GetProductById", ctx, uint(12)).Return(combo, nil)
+		mockRepo.On("HasComboComponents", ctx, uint(12)).Return(true, nil)
+		categoryRepo.On("GetCategoryByName", ctx, "Combo").Return(dto.CategoryResponse{Id: 1, Name: "Combo"}, nil)
+		mockRepo.On("UpdateProduct", ctx, patched).Return(nil)
+
+		err := sut.Execute(ctx, uint(12), dto.ProductPatchForm{
+			Name: &name,
+		})
+
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got success when patching slot combo in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewPatchProductUseCase(mockRepo, NewUpdateProductUseCase(uc, mockRepo, categoryRepo))
+
+		ctx := context.TODO()
+
+		description := "New Description"
+
+		combo := productsByCategory[0]
+		combo.Category = "Combo"
+		combo.ComboProducts = &[]dto.ProductResponse{}
+
+		patched := productUpdate
+		patched.Description = description
+		patched.Category = "Combo"
+
+		mockRepo.On("GetProductById", ctx, uint(12)).Return(combo, nil)
+		mockRepo.On("HasComboComponents", ctx, uint(12)).Return(true, nil)
+		categoryRepo.On("GetCategoryByName", ctx, "Combo").Return(dto.CategoryResponse{Id: 1, Name: "Combo"}, nil)
+		mockRepo.On("UpdateProduct", ctx, patched).Return(nil)
+
+		err := sut.Execute(ctx, uint(12), dto.ProductPatchForm{
+			Description: &description,
+		})
+
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got bad request when patching combo removing its products in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewPatchProductUseCase(mockRepo, NewUpdateProductUseCase(uc, mockRepo, categoryRepo))
+
+		ctx := context.TODO()
+
+		combo := productsByCategory[0]
+		combo.Category = "Combo"
+		combo.ComboProducts = &[]dto.ProductResponse{{Id: 3}, {Id: 4}}
+
+		mockRepo.On("GetProductById", ctx, uint(12)).Return(combo, nil)
+
+		err := sut.Execute(ctx, uint(12), dto.ProductPatchForm{
+			ComboProductsIds: &[]uint{},
+		})
+
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "UpdateProduct")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got not found when patching unknown product in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockProductRepository)
+		categoryRepo := new(MockCategoryRepository)
+		sut := NewPatchProductUseCase(mockRepo, NewUpdateProductUseCase(uc, mockRepo, categoryRepo))
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetProductById", ctx, uint(12)).Return(dto.ProductResponse{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "record not found",
+		})
+
+		err := sut.Execute(ctx, uint(12), dto.ProductPatchForm{})
+
+		mockRepo.AssertExpectations(t)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
 }
@@ -0,0 +1,124 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1-orders/pkg/pix"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+const qrCodeImageSize = 256
+
+// QRCodeSettings identifies the store in the PIX payload and how long the customer has to pay
+type QRCodeSettings struct {
+	PixKey       string
+	MerchantName string
+	MerchantCity string
+	Expiration   time.Duration
+}
+
+type CreateQRCodeOrderUseCase interface {
+	Execute(ctx context.Context, order dto.QRCodeOrder) (dto.QRCodeOrderResponse, error)
+}
+
+type CreateQRCodeOrderUseCaseImpl struct {
+	createPayingOrder CreatePayingOrderUseCase
+	settings          QRCodeSettings
+}
+
+type ExpirePayingOrdersUseCase interface {
+	Execute(ctx context.Context, now time.Time) error
+}
+
+type ExpirePayingOrdersUseCaseImpl struct {
+	orderRepo  repository.OrderRepository
+	expiration time.Duration
+}
+
+func NewCreateQRCodeOrderUseCase(
+	createPayingOrder CreatePayingOrderUseCase,
+	settings QRCodeSettings,
+) CreateQRCodeOrderUseCase {
+	return &CreateQRCodeOrderUseCaseImpl{
+		createPayingOrder: createPayingOrder,
+		settings:          settings,
+	}
+}
+
+func NewExpirePayingOrdersUseCase(
+	orderRepo repository.OrderRepository,
+	expiration time.Duration,
+) ExpirePayingOrdersUseCase {
+	return &ExpirePayingOrdersUseCaseImpl{
+		orderRepo:  orderRepo,
+		expiration: expiration,
+	}
+}
+
+// Execute creates a paying order and uses its PaymentID as the PIX txid. The same txid is
+// the PaymentID the payment gateway sends back in the webhook notification
+func (usecase *CreateQRCodeOrderUseCaseImpl) Execute(ctx context.Context, order dto.QRCodeOrder) (dto.QRCodeOrderResponse, error) {
+	// without the store key the customer would pay a QR Code nobody receives
+	if usecase.settings.PixKey == "" {
+		return dto.QRCodeOrderResponse{}, &responses.BusinessResponse{
+			StatusCode: http.StatusServiceUnavailable,
+			Message:    "The PIX checkout is not configured",
+		}
+	}
+
+	response, err := usecase.createPayingOrder.Execute(ctx, dto.PayingOrder{
+		TotalPrice:   order.TotalPrice,
+		CPF:          order.CPF,
+		OrderProduct: order.OrderProduct,
+	})
+
+	if err != nil {
+		return dto.QRCodeOrderResponse{}, responses.GetResponseError(err, "OrderService -> CreateQRCodeOrder")
+	}
+
+	payload := pix.Payload{
+		Key:          usecase.settings.PixKey,
+		MerchantName: usecase.settings.MerchantName,
+		MerchantCity: usecase.settings.MerchantCity,
+		Amount:       response.TotalPrice,
+		TxID:         response.PaymentID,
+	}.String()
+
+	image, err := pix.QRCodePNG(payload, qrCodeImageSize)
+
+	if err != nil {
+		return dto.QRCodeOrderResponse{}, responses.GetResponseError(err, "OrderService -> CreateQRCodeOrder")
+	}
+
+	return dto.QRCodeOrderResponse{
+		OrderId:     response.OrderId,
+		PaymentID:   response.PaymentID,
+		TotalPrice:  response.TotalPrice,
+		QRCodeData:  payload,
+		QRCodeImage: image,
+		ExpiresAt:   response.OrderDate.Add(usecase.settings.Expiration),
+	}, nil
+}
+
+// Execute deletes every order still waiting for payment after the expiration window.
+// The repository skips the run when another replica is already expiring orders
+func (usecase *ExpirePayingOrdersUseCaseImpl) Execute(ctx context.Context, now time.Time) error {
+	orderIDs, err := usecase.orderRepo.DeleteExpiredPayingOrders(ctx, now.Add(-usecase.expiration))
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> ExpirePayingOrders")
+	}
+
+	if len(orderIDs) > 0 {
+		log.Print("expired paying orders", map[string]interface{}{
+			"orderIds": orderIDs,
+		})
+	}
+
+	return nil
+}
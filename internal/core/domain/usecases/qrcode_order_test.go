@@ -0,0 +1,179 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/pkg/pix"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+var qrCodeSettings = QRCodeSettings{
+	PixKey:       "fastfood@pix.com",
+	MerchantName: "Fast Food",
+	MerchantCity: "Sao Paulo",
+	Expiration:   15 * time.Minute,
+}
+
+func TestQRCodeOrderServices(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when creating qr code order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		customerRepo := new(MockCustomerRepository)
+		productRepo := new(MockProductRepository)
+		createPayingOrder := NewCreatePayingOrderUseCase(mockRepo, customerRepo, NewPriceOrderUseCase(productRepo, new(MockComboRepository)), events.NewOrderEventBus(10))
+
+		sut := NewCreateQRCodeOrderUseCase(createPayingOrder, qrCodeSettings)
+
+		ctx := context.TODO()
+
+		orderDate := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1, 2}).Return(orderProducts, nil)
+		mockRepo.On("CreatePayingOrder", ctx, mock.MatchedBy(func(order dto.Order) bool {
+			return len(order.PaymentID) == 24
+		})).Return(dto.OrderResponse{
+			OrderId:   uint(1),
+			OrderDate: orderDate,
+		}, nil)
+
+		response, err := sut.Execute(ctx, dto.QRCodeOrder{
+			TotalPrice:   12345,
+			OrderProduct: payingOrderCreation.OrderProduct,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), response.OrderId)
+		assert.Equal(t, float64(12345), response.TotalPrice)
+		assert.Equal(t, orderDate.Add(15*time.Minute), response.ExpiresAt)
+		assert.Contains(t, response.QRCodeData, "0116fastfood@pix.com")
+		assert.Contains(t, response.QRCodeData, "540812345.00")
+		assert.True(t, strings.Contains(response.QRCodeData, "0524"+response.PaymentID))
+		assert.Equal(t, pix.Checksum(response.QRCodeData[:len(response.QRCodeData)-4]), response.QRCodeData[len(response.QRCodeData)-4:])
+		assert.NotEmpty(t, response.QRCodeImage)
+	})
+
+	t.Run("got error when creating qr code order with unknown product in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		customerRepo := new(MockCustomerRepository)
+		productRepo := new(MockProductRepository)
+		createPayingOrder := NewCreatePayingOrderUseCase(mockRepo, customerRepo, NewPriceOrderUseCase(productRepo, new(MockComboRepository)), events.NewOrderEventBus(10))
+
+		sut := NewCreateQRCodeOrderUseCase(createPayingOrder, qrCodeSettings)
+
+		ctx := context.TODO()
+
+		productRepo.On("GetProductsByIds", ctx, []uint{1, 2}).Return(orderProducts[:1], nil)
+
+		response, err := sut.Execute(ctx, dto.QRCodeOrder{
+			TotalPrice:   12345,
+			OrderProduct: payingOrderCreation.OrderProduct,
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+		mockRepo.AssertNotCalled(t, "CreatePayingOrder", ctx, mock.Anything)
+	})
+
+	t.Run("got error when creating qr code order without pix key in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		customerRepo := new(MockCustomerRepository)
+		productRepo := new(MockProductRepository)
+		createPayingOrder := NewCreatePayingOrderUseCase(mockRepo, customerRepo, NewPriceOrderUseCase(productRepo, new(MockComboRepository)), events.NewOrderEventBus(10))
+
+		settings := qrCodeSettings
+		settings.PixKey = ""
+
+		sut := NewCreateQRCodeOrderUseCase(createPayingOrder, settings)
+
+		ctx := context.TODO()
+
+		response, err := sut.Execute(ctx, dto.QRCodeOrder{
+			OrderProduct: payingOrderCreation.OrderProduct,
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusServiceUnavailable, businessError.StatusCode)
+		mockRepo.AssertNotCalled(t, "CreatePayingOrder", ctx, mock.Anything)
+	})
+
+	t.Run("got success when expiring paying orders in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewExpirePayingOrdersUseCase(mockRepo, 15*time.Minute)
+
+		ctx := context.TODO()
+
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+
+		mockRepo.On("DeleteExpiredPayingOrders", ctx, now.Add(-15*time.Minute)).Return([]uint{1, 2}, nil)
+
+		err := sut.Execute(ctx, now)
+
+		assert.NoError(t, err)
+		mockRepo.AssertCalled(t, "DeleteExpiredPayingOrders", ctx, now.Add(-15*time.Minute))
+	})
+
+	t.Run("got success when another replica is expiring paying orders in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewExpirePayingOrdersUseCase(mockRepo, 15*time.Minute)
+
+		ctx := context.TODO()
+
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+
+		mockRepo.On("DeleteExpiredPayingOrders", ctx, now.Add(-15*time.Minute)).Return([]uint{}, nil)
+
+		err := sut.Execute(ctx, now)
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "DeleteOrder", ctx, mock.Anything)
+	})
+
+	t.Run("got error on DeleteExpiredPayingOrders Repository when expiring paying orders in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewExpirePayingOrdersUseCase(mockRepo, 15*time.Minute)
+
+		ctx := context.TODO()
+
+		mockRepo.On("DeleteExpiredPayingOrders", ctx, mock.Anything).Return([]uint{}, &responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "DB Error",
+		})
+
+		err := sut.Execute(ctx, time.Now())
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,79 @@
+package usecases
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+)
+
+// OrderSortView is the status ranking of a panel, first status on top.
+// Statuses not in the ranking go after all the ranked ones
+type OrderSortView struct {
+	StatusRank []string
+}
+
+var (
+	// KitchenOrderSortView shows the orders being prepared before the ones waiting to start
+	KitchenOrderSortView = OrderSortView{
+		StatusRank: []string{
+			model.OrderStatusPreparing,
+			model.OrderStatusCreated,
+		},
+	}
+
+	// PickupOrderSortView shows the orders ready to be picked up first
+	PickupOrderSortView = OrderSortView{
+		StatusRank: []string{
+			model.OrderStatusDone,
+			model.OrderStatusPreparing,
+			model.OrderStatusCreated,
+		},
+	}
+)
+
+type SortOrdersUseCase struct {
+	statusRank map[string]int
+}
+
+func NewSortOrdersUseCase(view OrderSortView) *SortOrdersUseCase {
+	statusRank := map[string]int{}
+
+	for rank, status := range view.StatusRank {
+		if _, ok := statusRank[status]; !ok {
+			statusRank[status] = rank
+		}
+	}
+
+	return &SortOrdersUseCase{
+		statusRank: statusRank,
+	}
+}
+
+func (usecase *SortOrdersUseCase) Execute(orders []dto.OrderResponse) {
+	slices.SortFunc(orders, usecase.compare)
+}
+
+// compare orders by status rank, then higher priority, then the oldest order,
+// then the lower ticket number. The order ID is the last tie-break, so two
+// different orders are never equal and the queue is the same on every request
+func (usecase *SortOrdersUseCase) compare(previous, next dto.OrderResponse) int {
+	return cmp.Or(
+		cmp.Compare(usecase.rank(previous.OrderStatus), usecase.rank(next.OrderStatus)),
+		cmp.Compare(next.Priority, previous.Priority),
+		previous.OrderDate.Compare(next.OrderDate),
+		cmp.Compare(previous.TicketNumber, next.TicketNumber),
+		cmp.Compare(previous.OrderId, next.OrderId),
+	)
+}
+
+func (usecase *SortOrdersUseCase) rank(status string) int {
+	rank, ok := usecase.statusRank[status]
+
+	if !ok {
+		return len(usecase.statusRank)
+	}
+
+	return rank
+}
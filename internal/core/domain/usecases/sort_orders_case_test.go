@@ -1,19 +1,67 @@
 package usecases
 
 import (
+	"math/rand"
+	"reflect"
+	"slices"
 	"testing"
+	"testing/quick"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
 )
 
+// sortableOrder is a random order for the property tests. The values are drawn
+// from small sets, so ties in every criterion happen often
+type sortableOrder dto.OrderResponse
+
+func (sortableOrder) Generate(random *rand.Rand, size int) reflect.Value {
+	statuses := []string{
+		model.OrderStatusCreated,
+		model.OrderStatusPreparing,
+		model.OrderStatusDone,
+		model.OrderStatusDelivered,
+	}
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	return reflect.ValueOf(sortableOrder{
+		OrderId:      uint(random.Intn(4) + 1),
+		OrderStatus:  statuses[random.Intn(len(statuses))],
+		Priority:     random.Intn(3),
+		OrderDate:    start.Add(time.Duration(random.Intn(3)) * time.Minute),
+		TicketNumber: random.Intn(3) + 1,
+	})
+}
+
+func sameSortKey(sut *SortOrdersUseCase, first, second dto.OrderResponse) bool {
+	return sut.rank(first.OrderStatus) == sut.rank(second.OrderStatus) &&
+		first.Priority == second.Priority &&
+		first.OrderDate.Equal(second.OrderDate) &&
+		first.TicketNumber == second.TicketNumber &&
+		first.OrderId == second.OrderId
+}
+
+func sign(value int) int {
+	if value < 0 {
+		return -1
+	}
+
+	if value > 0 {
+		return 1
+	}
+
+	return 0
+}
+
 func TestSortOrdersUseCase(t *testing.T) {
 	t.Parallel()
 
 	t.Run("got success when sorting orders with many orders use case", func(t *testing.T) {
 		t.Parallel()
 
-		sut := NewSortOrdersUseCase()
+		sut := NewSortOrdersUseCase(PickupOrderSortView)
 
 		orders := []dto.OrderResponse{
 			{
@@ -66,7 +114,7 @@ func TestSortOrdersUseCase(t *testing.T) {
 	t.Run("got success when sorting orders with few already ordered orders use case", func(t *testing.T) {
 		t.Parallel()
 
-		sut := NewSortOrdersUseCase()
+		sut := NewSortOrdersUseCase(PickupOrderSortView)
 
 		orders := []dto.OrderResponse{
 			{
@@ -89,7 +137,7 @@ func TestSortOrdersUseCase(t *testing.T) {
 	t.Run("got success when sorting orders with few unordered orders use case", func(t *testing.T) {
 		t.Parallel()
 
-		sut := NewSortOrdersUseCase()
+		sut := NewSortOrdersUseCase(PickupOrderSortView)
 
 		orders := []dto.OrderResponse{
 			{
@@ -112,7 +160,7 @@ func TestSortOrdersUseCase(t *testing.T) {
 	t.Run("got success when sorting orders with few unordered unfinished orders use case", func(t *testing.T) {
 		t.Parallel()
 
-		sut := NewSortOrdersUseCase()
+		sut := NewSortOrdersUseCase(PickupOrderSortView)
 
 		orders := []dto.OrderResponse{
 			{
@@ -131,4 +179,123 @@ func TestSortOrdersUseCase(t *testing.T) {
 		assert.Equal(t, "Preparando", orders[0].OrderStatus)
 		assert.Equal(t, "Criado", orders[1].OrderStatus)
 	})
+
+	t.Run("got success when sorting orders for the kitchen use case", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewSortOrdersUseCase(KitchenOrderSortView)
+
+		now := time.Now()
+
+		orders := []dto.OrderResponse{
+			{OrderId: 1, OrderStatus: "Criado", OrderDate: now.Add(-10 * time.Minute), TicketNumber: 1},
+			{OrderId: 2, OrderStatus: "Criado", OrderDate: now.Add(-5 * time.Minute), TicketNumber: 2, Priority: 1},
+			{OrderId: 3, OrderStatus: "Preparando", OrderDate: now.Add(-2 * time.Minute), TicketNumber: 3},
+			{OrderId: 4, OrderStatus: "Preparando", OrderDate: now.Add(-8 * time.Minute), TicketNumber: 4},
+			{OrderId: 5, OrderStatus: "Criado", OrderDate: now.Add(-10 * time.Minute), TicketNumber: 0},
+			{OrderId: 6, OrderStatus: "Finalizado", OrderDate: now.Add(-20 * time.Minute), TicketNumber: 6},
+		}
+
+		sut.Execute(orders)
+
+		ids := []uint{}
+
+		for _, order := range orders {
+			ids = append(ids, order.OrderId)
+		}
+
+		// preparing oldest first, then the priority order, then the oldest by ticket, unranked last
+		assert.Equal(t, []uint{4, 3, 2, 5, 1, 6}, ids)
+	})
+
+	t.Run("got success when sorting orders by priority before age use case", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewSortOrdersUseCase(PickupOrderSortView)
+
+		now := time.Now()
+
+		orders := []dto.OrderResponse{
+			{OrderId: 1, OrderStatus: "Finalizado", OrderDate: now.Add(-10 * time.Minute)},
+			{OrderId: 2, OrderStatus: "Finalizado", OrderDate: now, Priority: 2},
+			{OrderId: 3, OrderStatus: "Finalizado", OrderDate: now, Priority: 1},
+		}
+
+		sut.Execute(orders)
+
+		assert.Equal(t, uint(2), orders[0].OrderId)
+		assert.Equal(t, uint(3), orders[1].OrderId)
+		assert.Equal(t, uint(1), orders[2].OrderId)
+	})
+
+	for name, view := range map[string]OrderSortView{"kitchen": KitchenOrderSortView, "pickup": PickupOrderSortView} {
+		t.Run("got a total order when comparing "+name+" orders use case", func(t *testing.T) {
+			t.Parallel()
+
+			sut := NewSortOrdersUseCase(view)
+
+			reflexive := func(order sortableOrder) bool {
+				return sut.compare(dto.OrderResponse(order), dto.OrderResponse(order)) == 0
+			}
+
+			antisymmetric := func(first, second sortableOrder) bool {
+				return sign(sut.compare(dto.OrderResponse(first), dto.OrderResponse(second))) ==
+					-sign(sut.compare(dto.OrderResponse(second), dto.OrderResponse(first)))
+			}
+
+			transitive := func(first, second, third sortableOrder) bool {
+				a, b, c := dto.OrderResponse(first), dto.OrderResponse(second), dto.OrderResponse(third)
+
+				if sut.compare(a, b) <= 0 && sut.compare(b, c) <= 0 {
+					return sut.compare(a, c) <= 0
+				}
+
+				return true
+			}
+
+			total := func(first, second sortableOrder) bool {
+				a, b := dto.OrderResponse(first), dto.OrderResponse(second)
+
+				return (sut.compare(a, b) == 0) == sameSortKey(sut, a, b)
+			}
+
+			config := &quick.Config{MaxCount: 2000}
+
+			assert.NoError(t, quick.Check(reflexive, config))
+			assert.NoError(t, quick.Check(antisymmetric, config))
+			assert.NoError(t, quick.Check(transitive, config))
+			assert.NoError(t, quick.Check(total, config))
+		})
+
+		t.Run("got the same queue for any input order when sorting "+name+" orders use case", func(t *testing.T) {
+			t.Parallel()
+
+			sut := NewSortOrdersUseCase(view)
+
+			sameQueue := func(samples []sortableOrder, seed int64) bool {
+				orders := []dto.OrderResponse{}
+				seen := map[uint]bool{}
+
+				// the order ID identifies an order, so the queue only has one of each
+				for _, sample := range samples {
+					if !seen[sample.OrderId] {
+						seen[sample.OrderId] = true
+						orders = append(orders, dto.OrderResponse(sample))
+					}
+				}
+
+				shuffled := slices.Clone(orders)
+				rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+					shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+				})
+
+				sut.Execute(orders)
+				sut.Execute(shuffled)
+
+				return slices.IsSortedFunc(orders, sut.compare) && reflect.DeepEqual(orders, shuffled)
+			}
+
+			assert.NoError(t, quick.Check(sameQueue, &quick.Config{MaxCount: 500}))
+		})
+	}
 }
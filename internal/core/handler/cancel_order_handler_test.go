@@ -0,0 +1,138 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestCancelOrderHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when calling cancel order handler", func(t *testing.T) {
+		t.Parallel()
+
+		cancel := dto.OrderCancelForm{
+			Reason: "CUSTOMER_REQUEST",
+			Note:   "Customer gave up",
+		}
+
+		jsonData, err := json.Marshal(cancel)
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/cancel", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		cancelOrder := new(MockCancelOrderUseCase)
+
+		cancelOrder.On("Execute", req.Context(), uint(12), cancel).Return(nil)
+
+		cancelOrderHandler := handler.CancelOrderHandler(cancelOrder)
+
+		cancelOrderHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+	})
+
+	t.Run("got error on UseCase when calling cancel order handler", func(t *testing.T) {
+		t.Parallel()
+
+		cancel := dto.OrderCancelForm{
+			Reason: "OUT_OF_STOCK",
+		}
+
+		jsonData, err := json.Marshal(cancel)
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/cancel", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		cancelOrder := new(MockCancelOrderUseCase)
+
+		cancelOrder.On("Execute", req.Context(), uint(12), cancel).Return(&responses.BusinessResponse{
+			StatusCode: 403,
+		})
+
+		cancelOrderHandler := handler.CancelOrderHandler(cancelOrder)
+
+		cancelOrderHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("got error on unknown reason when calling cancel order handler", func(t *testing.T) {
+		t.Parallel()
+
+		jsonData, err := json.Marshal(dto.OrderCancelForm{
+			Reason: "BORED",
+		})
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/cancel", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		cancelOrder := new(MockCancelOrderUseCase)
+
+		cancelOrderHandler := handler.CancelOrderHandler(cancelOrder)
+
+		cancelOrderHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		cancelOrder.AssertNotCalled(t, "Execute")
+	})
+
+	t.Run("got error on invalid id when calling cancel order handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/cancel", nil)
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "x12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		cancelOrder := new(MockCancelOrderUseCase)
+
+		cancelOrderHandler := handler.CancelOrderHandler(cancelOrder)
+
+		cancelOrderHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
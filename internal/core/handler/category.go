@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/usecases"
+	"github.com/thiagoluis88git/tech1-orders/pkg/httpserver"
+)
+
+// @Summary Create new category
+// @Description Create new category. It is active unless active is sent as false
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param category body dto.CategoryForm true "category"
+// @Success 200 {object} dto.CategoryCreationResponse
+// @Failure 400 "Category has required fields"
+// @Failure 409 "This Category is already added"
+// @Router /api/admin/categories [post]
+func CreateCategoryHandler(createCategory usecases.CreateCategoryUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var category dto.CategoryForm
+
+		err := httpserver.DecodeJSONBody(w, r, &category)
+
+		if err != nil {
+			log.Print("decoding category body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		categoryId, err := createCategory.Execute(r.Context(), category)
+
+		if err != nil {
+			log.Print("create category", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, dto.CategoryCreationResponse{
+			Id: categoryId,
+		})
+	}
+}
+
+// @Summary List the menu categories
+// @Description List the active categories in display order
+// @Tags Category
+// @Produce json
+// @Success 200 {object} []dto.CategoryResponse
+// @Router /api/categories [get]
+func GetMenuCategoriesHandler(getCategories usecases.GetCategoriesUseCase) http.HandlerFunc {
+	return getCategoryListHandler(getCategories, true)
+}
+
+// @Summary List all categories
+// @Description List the active and inactive categories in display order
+// @Tags Category
+// @Produce json
+// @Success 200 {object} []dto.CategoryResponse
+// @Router /api/admin/categories [get]
+func GetAdminCategoriesHandler(getCategories usecases.GetCategoriesUseCase) http.HandlerFunc {
+	return getCategoryListHandler(getCategories, false)
+}
+
+func getCategoryListHandler(getCategories usecases.GetCategoriesUseCase, onlyActive bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		categories, err := getCategories.Execute(r.Context(), onlyActive)
+
+		if err != nil {
+			log.Print("get categories", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, categories)
+	}
+}
+
+// @Summary Get category by ID
+// @Description Get category by ID
+// @Tags Category
+// @Param id path int true "12"
+// @Produce json
+// @Success 200 {object} dto.CategoryResponse
+// @Failure 404 "Category not found"
+// @Router /api/admin/categories/{id} [get]
+func GetCategoryByIdHandler(getCategoryById usecases.GetCategoryByIdUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		categoryId, err := getCategoryIdFromRequest(r)
+
+		if err != nil {
+			log.Print("get category by id", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		category, err := getCategoryById.Execute(r.Context(), categoryId)
+
+		if err != nil {
+			log.Print("get category by id", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, category)
+	}
+}
+
+// @Summary Update a category
+// @Description Update a category by ID. A category without active in the body is set as active.
+// @Description System categories, like Combo and Acompanhamento, can not be renamed
+// @Tags Category
+// @Param id path int true "12"
+// @Param category body dto.CategoryForm true "category"
+// @Accept json
+// @Produce json
+// @Success 204
+// @Failure 404 "Category not found"
+// @Failure 409 "There is already a Category with this name or a system Category was renamed"
+// @Router /api/admin/categories/{id} [put]
+func UpdateCategoryHandler(updateCategory usecases.UpdateCategoryUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		categoryId, err := getCategoryIdFromRequest(r)
+
+		if err != nil {
+			log.Print("update category", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		var category dto.CategoryForm
+
+		err = httpserver.DecodeJSONBody(w, r, &category)
+
+		if err != nil {
+			log.Print("decoding category body for update category", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		category.Id = categoryId
+		err = updateCategory.Execute(r.Context(), category)
+
+		if err != nil {
+			log.Print("update category", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
+
+// @Summary Delete a category
+// @Description Delete a category by ID. Categories with products can not be deleted, deactivate them instead.
+// @Description System categories can not be deleted
+// @Tags Category
+// @Param id path int true "12"
+// @Produce json
+// @Success 204
+// @Failure 404 "Category not found"
+// @Failure 409 "Category has products or is used by the system"
+// @Router /api/admin/categories/{id} [delete]
+func DeleteCategoryHandler(deleteCategory usecases.DeleteCategoryUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		categoryId, err := getCategoryIdFromRequest(r)
+
+		if err != nil {
+			log.Print("delete category", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		err = deleteCategory.Execute(r.Context(), categoryId)
+
+		if err != nil {
+			log.Print("delete category", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
+
+func getCategoryIdFromRequest(r *http.Request) (uint, error) {
+	categoryIdStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+	if err != nil {
+		return 0, err
+	}
+
+	categoryId, err := strconv.ParseUint(categoryIdStr, 10, 0)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(categoryId), nil
+}
@@ -0,0 +1,255 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestCategoryHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when calling create category handler", func(t *testing.T) {
+		t.Parallel()
+
+		category := dto.CategoryForm{Name: "Saladas", DisplayOrder: 6, ImageUrl: "ImageUrl"}
+
+		jsonData, err := json.Marshal(category)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/categories", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		recorder := httptest.NewRecorder()
+
+		createCategoryUseCase := new(MockCreateCategoryUseCase)
+		createCategoryUseCase.On("Execute", req.Context(), category).Return(uint(6), nil)
+
+		handler.CreateCategoryHandler(createCategoryUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response dto.CategoryCreationResponse
+		err = json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(6), response.Id)
+	})
+
+	t.Run("got bad request when calling create category handler without name", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/categories", bytes.NewBufferString(`{"displayOrder": 1}`))
+		req.Header.Add("Content-Type", "application/json")
+
+		recorder := httptest.NewRecorder()
+
+		createCategoryUseCase := new(MockCreateCategoryUseCase)
+
+		handler.CreateCategoryHandler(createCategoryUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		createCategoryUseCase.AssertNotCalled(t, "Execute")
+	})
+
+	t.Run("got conflict when calling create category handler with a name in use", func(t *testing.T) {
+		t.Parallel()
+
+		category := dto.CategoryForm{Name: "Bebida"}
+
+		jsonData, err := json.Marshal(category)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/categories", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		recorder := httptest.NewRecorder()
+
+		createCategoryUseCase := new(MockCreateCategoryUseCase)
+		createCategoryUseCase.On("Execute", req.Context(), category).Return(uint(0), &responses.BusinessResponse{
+			StatusCode: http.StatusConflict,
+			Message:    "Conflict",
+		})
+
+		handler.CreateCategoryHandler(createCategoryUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+	})
+
+	t.Run("got only active categories when calling menu categories handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/categories", nil)
+		recorder := httptest.NewRecorder()
+
+		getCategoriesUseCase := new(MockGetCategoryUseCase)
+		getCategoriesUseCase.On("Execute", req.Context(), true).Return([]dto.CategoryResponse{
+			{Id: 1, Name: "Combo", DisplayOrder: 1, Active: true, ImageUrl: "ImageUrl"},
+		}, nil)
+
+		handler.GetMenuCategoriesHandler(getCategoriesUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response []dto.CategoryResponse
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(response))
+		assert.Equal(t, "ImageUrl", response[0].ImageUrl)
+		getCategoriesUseCase.AssertExpectations(t)
+	})
+
+	t.Run("got every category when calling admin categories handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/categories", nil)
+		recorder := httptest.NewRecorder()
+
+		getCategoriesUseCase := new(MockGetCategoryUseCase)
+		getCategoriesUseCase.On("Execute", req.Context(), false).Return([]dto.CategoryResponse{
+			{Id: 1, Name: "Combo", DisplayOrder: 1, Active: true},
+			{Id: 6, Name: "Bebidas", DisplayOrder: 6, Active: false},
+		}, nil)
+
+		handler.GetAdminCategoriesHandler(getCategoriesUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response []dto.CategoryResponse
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(response))
+		assert.False(t, response[1].Active)
+	})
+
+	t.Run("got success when calling get category by id handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/categories/{id}", nil)
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "2")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		getCategoryByIdUseCase := new(MockGetCategoryByIdUseCase)
+		getCategoryByIdUseCase.On("Execute", req.Context(), uint(2)).Return(dto.CategoryResponse{Id: 2, Name: "Lanche"}, nil)
+
+		handler.GetCategoryByIdHandler(getCategoryByIdUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response dto.CategoryResponse
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Lanche", response.Name)
+	})
+
+	t.Run("got bad request when calling get category by id handler with invalid id", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/categories/{id}", nil)
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "abc")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		getCategoryByIdUseCase := new(MockGetCategoryByIdUseCase)
+
+		handler.GetCategoryByIdHandler(getCategoryByIdUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("got success when calling update category handler", func(t *testing.T) {
+		t.Parallel()
+
+		active := false
+
+		jsonData, err := json.Marshal(dto.CategoryForm{Name: "Lanche", DisplayOrder: 2, Active: &active})
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/admin/categories/{id}", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "2")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		updateCategoryUseCase := new(MockUpdateCategoryUseCase)
+		updateCategoryUseCase.On("Execute", req.Context(), dto.CategoryForm{
+			Id:           2,
+			Name:         "Lanche",
+			DisplayOrder: 2,
+			Active:       &active,
+		}).Return(nil)
+
+		handler.UpdateCategoryHandler(updateCategoryUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		updateCategoryUseCase.AssertExpectations(t)
+	})
+
+	t.Run("got success when calling delete category handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/admin/categories/{id}", nil)
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "7")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		deleteCategoryUseCase := new(MockDeleteCategoryUseCase)
+		deleteCategoryUseCase.On("Execute", req.Context(), uint(7)).Return(nil)
+
+		handler.DeleteCategoryHandler(deleteCategoryUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+	})
+
+	t.Run("got conflict when calling delete category handler for a category with products", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/admin/categories/{id}", nil)
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "2")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		deleteCategoryUseCase := new(MockDeleteCategoryUseCase)
+		deleteCategoryUseCase.On("Execute", req.Context(), uint(2)).Return(&responses.BusinessResponse{
+			StatusCode: http.StatusConflict,
+			Message:    "Category has 3 products",
+		})
+
+		handler.DeleteCategoryHandler(deleteCategoryUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+	})
+}
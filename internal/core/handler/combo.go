@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/usecases"
+	"github.com/thiagoluis88git/tech1-orders/pkg/httpserver"
+)
+
+// @Summary Create new combo
+// @Description Create new combo with its slots. Only Lanche, Bebida, Sobremesa and Acompanhamento products
+// @Description can be in a combo. A slot with a category lets the customer pick any product of it.
+// @Description A fixed combo costs its price, a discount combo costs the chosen products with the discount
+// @Tags Combo
+// @Accept json
+// @Produce json
+// @Param combo body dto.ComboForm true "combo"
+// @Success 200 {object} dto.ProductCreationResponse
+// @Failure 400 "Combo has required fields or products that can not be in a combo"
+// @Failure 409 "This Combo is already added"
+// @Router /api/admin/combos [post]
+func CreateComboHandler(createCombo usecases.CreateComboUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var combo dto.ComboForm
+
+		err := httpserver.DecodeJSONBody(w, r, &combo)
+
+		if err != nil {
+			log.Print("decoding combo body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		comboId, err := createCombo.Execute(r.Context(), combo)
+
+		if err != nil {
+			log.Print("create combo", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, dto.ProductCreationResponse{
+			Id: comboId,
+		})
+	}
+}
+
+// @Summary Update a combo
+// @Description Update a combo by ID, its slots are replaced by the ones sent
+// @Tags Combo
+// @Param id path int true "12"
+// @Param combo body dto.ComboForm true "combo"
+// @Accept json
+// @Produce json
+// @Success 204
+// @Failure 400 "Combo has required fields or products that can not be in a combo"
+// @Failure 404 "Combo not found"
+// @Router /api/admin/combos/{id} [put]
+func UpdateComboHandler(updateCombo usecases.UpdateComboUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		comboId, err := getComboIdFromRequest(r)
+
+		if err != nil {
+			log.Print("update combo", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		var combo dto.ComboForm
+
+		err = httpserver.DecodeJSONBody(w, r, &combo)
+
+		if err != nil {
+			log.Print("decoding combo body for update combo", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		combo.Id = comboId
+		err = updateCombo.Execute(r.Context(), combo)
+
+		if err != nil {
+			log.Print("update combo", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
+
+// @Summary List the menu combos
+// @Description List the combos of the menu with the options of each slot
+// @Tags Combo
+// @Produce json
+// @Success 200 {object} []dto.Combo
+// @Router /api/combos [get]
+func GetCombosHandler(getCombos usecases.GetCombosUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		combos, err := getCombos.Execute(r.Context())
+
+		if err != nil {
+			log.Print("get combos", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, combos)
+	}
+}
+
+// @Summary Get combo by ID
+// @Description Get combo by ID with the options of each slot
+// @Tags Combo
+// @Param id path int true "12"
+// @Produce json
+// @Success 200 {object} dto.Combo
+// @Failure 404 "Combo not found"
+// @Router /api/combos/{id} [get]
+func GetComboByIdHandler(getComboById usecases.GetComboByIdUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		comboId, err := getComboIdFromRequest(r)
+
+		if err != nil {
+			log.Print("get combo by id", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		combo, err := getComboById.Execute(r.Context(), comboId)
+
+		if err != nil {
+			log.Print("get combo by id", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, combo)
+	}
+}
+
+func getComboIdFromRequest(r *http.Request) (uint, error) {
+	comboIdStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+	if err != nil {
+		return 0, err
+	}
+
+	comboId, err := strconv.ParseUint(comboIdStr, 10, 0)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(comboId), nil
+}
@@ -0,0 +1,260 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestComboHandler(t *testing.T) {
+	t.Parallel()
+
+	combo := dto.ComboForm{
+		Name:        "Combo",
+		Description: "Combo",
+		Images: []dto.ProducImage{
+			{ImageUrl: "ImageUrl"},
+		},
+		Pricing:  "discount",
+		Discount: 10,
+		Slots: []dto.ComboSlotForm{
+			{Name: "Lanche", ProductIds: []uint{1}},
+			{Name: "Bebida", Category: "Bebida"},
+		},
+	}
+
+	t.Run("got success when calling create combo handler", func(t *testing.T) {
+		t.Parallel()
+
+		jsonData, err := json.Marshal(combo)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/combos", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		recorder := httptest.NewRecorder()
+
+		createComboUseCase := new(MockCreateComboUseCase)
+		createComboUseCase.On("Execute", req.Context(), combo).Return(uint(9), nil)
+
+		handler.CreateComboHandler(createComboUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response dto.ProductCreationResponse
+		err = json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(9), response.Id)
+	})
+
+	t.Run("got bad request when calling create combo handler with a slot with products and category", func(t *testing.T) {
+		t.Parallel()
+
+		invalidCombo := combo
+		invalidCombo.Slots = []dto.ComboSlotForm{
+			{Name: "Bebida", ProductIds: []uint{6}, Category: "Bebida"},
+		}
+
+		jsonData, err := json.Marshal(invalidCombo)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/combos", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		recorder := httptest.NewRecorder()
+
+		createComboUseCase := new(MockCreateComboUseCase)
+
+		handler.CreateComboHandler(createComboUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		createComboUseCase.AssertNotCalled(t, "Execute")
+	})
+
+	t.Run("got bad request when calling create combo handler with unknown pricing", func(t *testing.T) {
+		t.Parallel()
+
+		invalidCombo := combo
+		invalidCombo.Pricing = "free"
+
+		jsonData, err := json.Marshal(invalidCombo)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/combos", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		recorder := httptest.NewRecorder()
+
+		createComboUseCase := new(MockCreateComboUseCase)
+
+		handler.CreateComboHandler(createComboUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		createComboUseCase.AssertNotCalled(t, "Execute")
+	})
+
+	t.Run("got bad request when calling create combo handler with a component that can not be in a combo", func(t *testing.T) {
+		t.Parallel()
+
+		jsonData, err := json.Marshal(combo)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/combos", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		recorder := httptest.NewRecorder()
+
+		createComboUseCase := new(MockCreateComboUseCase)
+		createComboUseCase.On("Execute", req.Context(), combo).Return(uint(0), &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Product 1 is in Combo category and can not be in a combo",
+		})
+
+		handler.CreateComboHandler(createComboUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("got success when calling update combo handler", func(t *testing.T) {
+		t.Parallel()
+
+		jsonData, err := json.Marshal(combo)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/admin/combos/{id}", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "9")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		updatedCombo := combo
+		updatedCombo.Id = 9
+
+		updateComboUseCase := new(MockUpdateComboUseCase)
+		updateComboUseCase.On("Execute", req.Context(), updatedCombo).Return(nil)
+
+		handler.UpdateComboHandler(updateComboUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		updateComboUseCase.AssertExpectations(t)
+	})
+
+	t.Run("got bad request when calling update combo handler with invalid id", func(t *testing.T) {
+		t.Parallel()
+
+		jsonData, err := json.Marshal(combo)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/admin/combos/{id}", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "abc")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		updateComboUseCase := new(MockUpdateComboUseCase)
+
+		handler.UpdateComboHandler(updateComboUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		updateComboUseCase.AssertNotCalled(t, "Execute")
+	})
+
+	t.Run("got success when calling get combos handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/combos", nil)
+
+		recorder := httptest.NewRecorder()
+
+		getCombosUseCase := new(MockGetCombosUseCase)
+		getCombosUseCase.On("Execute", req.Context()).Return([]dto.Combo{
+			{
+				Id:      9,
+				Name:    "Combo",
+				Pricing: "fixed",
+				Price:   22,
+				Slots: []dto.ComboSlot{
+					{Id: 1, Name: "Lanche", Options: []dto.ProductResponse{{Id: 1, Name: "Burger"}}},
+				},
+			},
+		}, nil)
+
+		handler.GetCombosHandler(getCombosUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response []dto.Combo
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(response))
+		assert.Equal(t, "Burger", response[0].Slots[0].Options[0].Name)
+	})
+
+	t.Run("got success when calling get combo by id handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/combos/{id}", nil)
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "9")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		getComboByIdUseCase := new(MockGetComboByIdUseCase)
+		getComboByIdUseCase.On("Execute", req.Context(), uint(9)).Return(dto.Combo{Id: 9, Name: "Combo"}, nil)
+
+		handler.GetComboByIdHandler(getComboByIdUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response dto.Combo
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(9), response.Id)
+	})
+
+	t.Run("got not found when calling get combo by id handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/combos/{id}", nil)
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "9")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		getComboByIdUseCase := new(MockGetComboByIdUseCase)
+		getComboByIdUseCase.On("Execute", req.Context(), uint(9)).Return(dto.Combo{}, &responses.BusinessResponse{
+			StatusCode: http.StatusNotFound,
+			Message:    "Combo not found",
+		})
+
+		handler.GetComboByIdHandler(getComboByIdUseCase).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
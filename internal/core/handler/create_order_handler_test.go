@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"sync"
 	"testing"
 	"time"
 
@@ -60,11 +59,7 @@ func TestCreateOrderHandler(t *testing.T) {
 		now := time.Now()
 		orderDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
 
-		var wg sync.WaitGroup
-		channel := make(chan bool, 1)
-		wg.Add(1)
-
-		createOrderUseCase.On("Execute", req.Context(), mockOrder(), orderDate.UnixMilli(), &wg, channel).
+		createOrderUseCase.On("Execute", req.Context(), mockOrder(), orderDate.UnixMilli()).
 			Return(dto.OrderResponse{
 				OrderId: uint(2),
 			}, nil)
@@ -106,11 +101,7 @@ func TestCreateOrderHandler(t *testing.T) {
 		now := time.Now()
 		orderDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
 
-		var wg sync.WaitGroup
-		channel := make(chan bool, 1)
-		wg.Add(1)
-
-		createOrderUseCase.On("Execute", req.Context(), mockOrder(), orderDate.UnixMilli(), &wg, channel).
+		createOrderUseCase.On("Execute", req.Context(), mockOrder(), orderDate.UnixMilli()).
 			Return(dto.OrderResponse{}, &responses.BusinessResponse{
 				StatusCode: 500,
 			})
@@ -122,6 +113,35 @@ func TestCreateOrderHandler(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
 	})
 
+	t.Run("got error on invalid quantity when calling create order handler", func(t *testing.T) {
+		t.Parallel()
+
+		order := mockOrder()
+		order.OrderProduct[0].Quantity = 100
+
+		jsonData, err := json.Marshal(order)
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/order", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		createOrderUseCase := new(MockCreateOrderUseCase)
+
+		createOrderHandler := handler.CreateOrderHandler(createOrderUseCase)
+
+		createOrderHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		createOrderUseCase.AssertNotCalled(t, "Execute")
+	})
+
 	t.Run("got error on Decoding invalid json when calling create order handler", func(t *testing.T) {
 		t.Parallel()
 
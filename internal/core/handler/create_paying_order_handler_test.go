@@ -0,0 +1,127 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func mockPayingOrder() dto.PayingOrder {
+	return dto.PayingOrder{
+		TotalPrice: 46.8,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID:    1,
+				ProductPrice: 23.4,
+			},
+			{
+				ProductID:    2,
+				ProductPrice: 23.4,
+			},
+		},
+	}
+}
+
+func TestCreatePayingOrderHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when calling create paying order handler", func(t *testing.T) {
+		t.Parallel()
+
+		jsonData, err := json.Marshal(mockPayingOrder())
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/orders/paying", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		createPayingOrderUseCase := new(MockCreatePayingOrderUseCase)
+
+		createPayingOrderUseCase.On("Execute", req.Context(), mockPayingOrder()).
+			Return(dto.OrderResponse{
+				OrderId:     uint(2),
+				OrderStatus: "Em pagamento",
+			}, nil)
+
+		createPayingOrderHandler := handler.CreatePayingOrderHandler(createPayingOrderUseCase)
+
+		createPayingOrderHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response dto.OrderResponse
+		err = json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), response.OrderId)
+		assert.Equal(t, "Em pagamento", response.OrderStatus)
+		assert.Equal(t, 0, response.TicketNumber)
+	})
+
+	t.Run("got error on UseCase when calling create paying order handler", func(t *testing.T) {
+		t.Parallel()
+
+		jsonData, err := json.Marshal(mockPayingOrder())
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/orders/paying", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		createPayingOrderUseCase := new(MockCreatePayingOrderUseCase)
+
+		createPayingOrderUseCase.On("Execute", req.Context(), mockPayingOrder()).
+			Return(dto.OrderResponse{}, &responses.BusinessResponse{
+				StatusCode: 422,
+			})
+
+		createPayingOrderHandler := handler.CreatePayingOrderHandler(createPayingOrderUseCase)
+
+		createPayingOrderHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+
+	t.Run("got error on missing products when calling create paying order handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/orders/paying", bytes.NewBuffer([]byte("{}")))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		createPayingOrderUseCase := new(MockCreatePayingOrderUseCase)
+
+		createPayingOrderHandler := handler.CreatePayingOrderHandler(createPayingOrderUseCase)
+
+		createPayingOrderHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		createPayingOrderUseCase.AssertNotCalled(t, "Execute")
+	})
+}
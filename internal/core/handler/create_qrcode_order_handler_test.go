@@ -0,0 +1,143 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func mockQRCodeOrder() dto.QRCodeOrder {
+	return dto.QRCodeOrder{
+		TotalPrice: 46.8,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID:    1,
+				ProductPrice: 23.4,
+			},
+			{
+				ProductID:    2,
+				ProductPrice: 23.4,
+			},
+		},
+	}
+}
+
+func TestCreateQRCodeOrderHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when calling create qr code order handler", func(t *testing.T) {
+		t.Parallel()
+
+		jsonData, err := json.Marshal(mockQRCodeOrder())
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/orders/qrcode", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		createQRCodeOrderUseCase := new(MockCreateQRCodeOrderUseCase)
+
+		createQRCodeOrderUseCase.On("Execute", req.Context(), mockQRCodeOrder()).
+			Return(dto.QRCodeOrderResponse{
+				OrderId:     uint(2),
+				PaymentID:   "abc123",
+				QRCodeData:  "000201",
+				QRCodeImage: []byte{0x89, 0x50, 0x4E, 0x47},
+			}, nil)
+
+		createQRCodeOrderHandler := handler.CreateQRCodeOrderHandler(createQRCodeOrderUseCase)
+
+		createQRCodeOrderHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response dto.QRCodeOrderResponse
+		err = json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), response.OrderId)
+		assert.Equal(t, "abc123", response.PaymentID)
+		assert.Equal(t, []byte{0x89, 0x50, 0x4E, 0x47}, response.QRCodeImage)
+	})
+
+	t.Run("got error on UseCase when calling create qr code order handler", func(t *testing.T) {
+		t.Parallel()
+
+		jsonData, err := json.Marshal(mockQRCodeOrder())
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/orders/qrcode", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		createQRCodeOrderUseCase := new(MockCreateQRCodeOrderUseCase)
+
+		createQRCodeOrderUseCase.On("Execute", req.Context(), mockQRCodeOrder()).
+			Return(dto.QRCodeOrderResponse{}, &responses.BusinessResponse{
+				StatusCode: 422,
+			})
+
+		createQRCodeOrderHandler := handler.CreateQRCodeOrderHandler(createQRCodeOrderUseCase)
+
+		createQRCodeOrderHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+
+	t.Run("got success without total price when calling create qr code order handler", func(t *testing.T) {
+		t.Parallel()
+
+		order := mockQRCodeOrder()
+		order.TotalPrice = 0
+
+		jsonData, err := json.Marshal(order)
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/orders/qrcode", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		createQRCodeOrderUseCase := new(MockCreateQRCodeOrderUseCase)
+
+		createQRCodeOrderUseCase.On("Execute", req.Context(), order).
+			Return(dto.QRCodeOrderResponse{
+				OrderId:    uint(2),
+				PaymentID:  "abc123",
+				TotalPrice: 46.8,
+			}, nil)
+
+		createQRCodeOrderHandler := handler.CreateQRCodeOrderHandler(createQRCodeOrderUseCase)
+
+		createQRCodeOrderHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		createQRCodeOrderUseCase.AssertCalled(t, "Execute", req.Context(), order)
+	})
+}
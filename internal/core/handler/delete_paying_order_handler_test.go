@@ -0,0 +1,89 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestDeletePayingOrderHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when calling delete paying order handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/orders/{id}", nil)
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		deletePayingOrder := new(MockDeletePayingOrderUseCase)
+
+		deletePayingOrder.On("Execute", req.Context(), uint(12)).Return(nil)
+
+		deletePayingOrderHandler := handler.DeletePayingOrderHandler(deletePayingOrder)
+
+		deletePayingOrderHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+	})
+
+	t.Run("got error on UseCase when calling delete paying order handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/orders/{id}", nil)
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		deletePayingOrder := new(MockDeletePayingOrderUseCase)
+
+		deletePayingOrder.On("Execute", req.Context(), uint(12)).Return(&responses.BusinessResponse{
+			StatusCode: 428,
+		})
+
+		deletePayingOrderHandler := handler.DeletePayingOrderHandler(deletePayingOrder)
+
+		deletePayingOrderHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+	})
+
+	t.Run("got error on invalid id when calling delete paying order handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/orders/{id}", nil)
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "x12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		deletePayingOrder := new(MockDeletePayingOrderUseCase)
+
+		deletePayingOrderHandler := handler.DeletePayingOrderHandler(deletePayingOrder)
+
+		deletePayingOrderHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
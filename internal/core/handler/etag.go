@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/usecases"
+)
+
+const (
+	etagHeader    = "ETag"
+	ifMatchHeader = "If-Match"
+)
+
+// OrderIfMatchMiddleware puts the If-Match header in the request context. Clients that
+// want optimistic concurrency send the ETag of GET /api/orders/{id} when moving the order
+func OrderIfMatchMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifMatch := r.Header.Get(ifMatchHeader)
+
+		if ifMatch != "" {
+			r = r.WithContext(usecases.WithIfMatch(r.Context(), ifMatch))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,133 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestFinishOrderWithPaymentHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when calling finish order with payment handler", func(t *testing.T) {
+		t.Parallel()
+
+		jsonData, err := json.Marshal(dto.OrderPaymentForm{
+			PaymentID: "12345",
+		})
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/payment", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		finishOrderWithPayment := new(MockFinishOrderWithPaymentUseCase)
+
+		now := time.Now()
+		orderDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+		finishOrderWithPayment.On("Execute", req.Context(), uint(12), "12345", orderDate.UnixMilli()).Return(nil)
+
+		finishOrderWithPaymentHandler := handler.FinishOrderWithPaymentHandler(finishOrderWithPayment)
+
+		finishOrderWithPaymentHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+	})
+
+	t.Run("got error on UseCase when calling finish order with payment handler", func(t *testing.T) {
+		t.Parallel()
+
+		jsonData, err := json.Marshal(dto.OrderPaymentForm{
+			PaymentID: "12345",
+		})
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/payment", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		finishOrderWithPayment := new(MockFinishOrderWithPaymentUseCase)
+
+		now := time.Now()
+		orderDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+		finishOrderWithPayment.On("Execute", req.Context(), uint(12), "12345", orderDate.UnixMilli()).Return(&responses.BusinessResponse{
+			StatusCode: 428,
+		})
+
+		finishOrderWithPaymentHandler := handler.FinishOrderWithPaymentHandler(finishOrderWithPayment)
+
+		finishOrderWithPaymentHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+	})
+
+	t.Run("got error on missing payment id when calling finish order with payment handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/payment", bytes.NewBuffer([]byte("{}")))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		finishOrderWithPayment := new(MockFinishOrderWithPaymentUseCase)
+
+		finishOrderWithPaymentHandler := handler.FinishOrderWithPaymentHandler(finishOrderWithPayment)
+
+		finishOrderWithPaymentHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("got error on invalid id when calling finish order with payment handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/payment", nil)
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "x12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		finishOrderWithPayment := new(MockFinishOrderWithPaymentUseCase)
+
+		finishOrderWithPaymentHandler := handler.FinishOrderWithPaymentHandler(finishOrderWithPayment)
+
+		finishOrderWithPaymentHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -41,7 +42,8 @@ func TestGetOrderByIDHandler(t *testing.T) {
 
 		getOrderByIdUseCase.On("Execute", req.Context(), uint(12)).
 			Return(dto.OrderResponse{
-				OrderId: uint(12),
+				OrderId:   uint(12),
+				UpdatedAt: time.UnixMicro(1720614600123456),
 			}, nil)
 
 		getOrderByIdHandler := handler.GetOrderByIdHandler(getOrderByIdUseCase)
@@ -49,6 +51,7 @@ func TestGetOrderByIDHandler(t *testing.T) {
 		getOrderByIdHandler.ServeHTTP(recorder, req)
 
 		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "\"1720614600123456\"", recorder.Header().Get("ETag"))
 
 		var response dto.OrderResponse
 		err = json.Unmarshal(recorder.Body.Bytes(), &response)
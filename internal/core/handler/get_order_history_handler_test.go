@@ -0,0 +1,112 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestGetOrderHistoryHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when calling get order history handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/orders/{id}/history", nil)
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		getOrderHistoryUseCase := new(MockGetOrderHistoryUseCase)
+
+		getOrderHistoryUseCase.On("Execute", req.Context(), uint(12)).
+			Return([]dto.OrderStatusEventResponse{
+				{
+					PreviousStatus: "",
+					NewStatus:      "Criado",
+					Actor:          "waiter",
+					RequestID:      "host/abc-000001",
+				},
+				{
+					PreviousStatus: "Criado",
+					NewStatus:      "Cancelado",
+					Actor:          "manager",
+					RequestID:      "host/abc-000002",
+				},
+			}, nil)
+
+		getOrderHistoryHandler := handler.GetOrderHistoryHandler(getOrderHistoryUseCase)
+
+		getOrderHistoryHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response []dto.OrderStatusEventResponse
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.NoError(t, err)
+
+		assert.Equal(t, 2, len(response))
+		assert.Equal(t, "Cancelado", response[1].NewStatus)
+		assert.Equal(t, "host/abc-000002", response[1].RequestID)
+	})
+
+	t.Run("got error on GetOrderHistory UseCase when calling get order history handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/orders/{id}/history", nil)
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		getOrderHistoryUseCase := new(MockGetOrderHistoryUseCase)
+
+		getOrderHistoryUseCase.On("Execute", req.Context(), uint(12)).
+			Return([]dto.OrderStatusEventResponse{}, &responses.BusinessResponse{
+				StatusCode: 404,
+			})
+
+		getOrderHistoryHandler := handler.GetOrderHistoryHandler(getOrderHistoryUseCase)
+
+		getOrderHistoryHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("got error on invalid id param when calling get order history handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/orders/{id}/history", nil)
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "x12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		getOrderHistoryUseCase := new(MockGetOrderHistoryUseCase)
+
+		getOrderHistoryHandler := handler.GetOrderHistoryHandler(getOrderHistoryUseCase)
+
+		getOrderHistoryHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/pkg/httpserver"
+)
+
+const (
+	HealthStatusOk       = "ok"
+	HealthStatusDegraded = "degraded"
+)
+
+type CircuitBreakerReporter interface {
+	CircuitBreakers() map[string]string
+}
+
+// @Summary Health check
+// @Description Report the service health and the circuit breaker state of every host called by the service.
+// @Description The status is degraded while any circuit is open, still answered with 200 since the service itself is up
+// @Tags Health
+// @Produce json
+// @Success 200 {object} dto.HealthResponse
+// @Router /health [get]
+func HealthHandler(breakers CircuitBreakerReporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := dto.HealthResponse{
+			Status:          HealthStatusOk,
+			CircuitBreakers: breakers.CircuitBreakers(),
+		}
+
+		for _, state := range response.CircuitBreakers {
+			if state == httpserver.CircuitOpen {
+				response.Status = HealthStatusDegraded
+			}
+		}
+
+		httpserver.SendResponseSuccess(w, response)
+	}
+}
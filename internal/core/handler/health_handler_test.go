@@ -0,0 +1,78 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
+	"github.com/thiagoluis88git/tech1-orders/pkg/httpserver"
+)
+
+func TestHealthHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when calling health handler", func(t *testing.T) {
+		t.Parallel()
+
+		transport := httpserver.NewResilientTransport(http.DefaultTransport, httpserver.DefaultResilienceSettings)
+
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		recorder := httptest.NewRecorder()
+
+		handler.HealthHandler(transport).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response dto.HealthResponse
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, handler.HealthStatusOk, response.Status)
+		assert.Empty(t, response.CircuitBreakers)
+	})
+
+	t.Run("got degraded status when a circuit breaker is open", func(t *testing.T) {
+		t.Parallel()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer ts.Close()
+
+		transport := httpserver.NewResilientTransport(http.DefaultTransport, httpserver.ResilienceSettings{
+			MaxAttempts:      1,
+			AttemptTimeout:   time.Second,
+			FailureThreshold: 1,
+			OpenTimeout:      time.Minute,
+		})
+		client := httpserver.NewHTTPClient(transport)
+
+		_, err := httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.Customer{})
+		assert.Error(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		recorder := httptest.NewRecorder()
+
+		handler.HealthHandler(transport).ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response dto.HealthResponse
+		err = json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.NoError(t, err)
+
+		tsURL, err := url.Parse(ts.URL)
+
+		assert.NoError(t, err)
+		assert.Equal(t, handler.HealthStatusDegraded, response.Status)
+		assert.Equal(t, httpserver.CircuitOpen, response.CircuitBreakers[tsURL.Host])
+	})
+}
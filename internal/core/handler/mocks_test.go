@@ -2,7 +2,6 @@ package handler_test
 
 import (
 	"context"
-	"sync"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
@@ -20,10 +19,42 @@ type MockCreateOrderUseCase struct {
 	mock.Mock
 }
 
+type MockCreatePayingOrderUseCase struct {
+	mock.Mock
+}
+
+type MockFinishOrderWithPaymentUseCase struct {
+	mock.Mock
+}
+
+type MockDeletePayingOrderUseCase struct {
+	mock.Mock
+}
+
+type MockProcessPaymentNotificationUseCase struct {
+	mock.Mock
+}
+
+type MockCreateQRCodeOrderUseCase struct {
+	mock.Mock
+}
+
+type MockCancelOrderUseCase struct {
+	mock.Mock
+}
+
+type MockUpdateOrderPriorityUseCase struct {
+	mock.Mock
+}
+
 type MockGetOrderByIdUseCase struct {
 	mock.Mock
 }
 
+type MockGetOrderHistoryUseCase struct {
+	mock.Mock
+}
+
 type MockGetOrdersToPrepareUseCase struct {
 	mock.Mock
 }
@@ -36,6 +67,14 @@ type MockGetOrdersWaitingPaymentUseCase struct {
 	mock.Mock
 }
 
+type MockSearchOrdersUseCase struct {
+	mock.Mock
+}
+
+type MockUpdateOrderStatusUseCase struct {
+	mock.Mock
+}
+
 type MockUpdateToPreparingUseCase struct {
 	mock.Mock
 }
@@ -68,14 +107,54 @@ type MockDeleteProductUseCase struct {
 	mock.Mock
 }
 
+type MockRestoreProductUseCase struct {
+	mock.Mock
+}
+
 type MockUpdateProductUseCase struct {
 	mock.Mock
 }
 
+type MockPatchProductUseCase struct {
+	mock.Mock
+}
+
 type MockGetCategoryUseCase struct {
 	mock.Mock
 }
 
+type MockCreateCategoryUseCase struct {
+	mock.Mock
+}
+
+type MockGetCategoryByIdUseCase struct {
+	mock.Mock
+}
+
+type MockUpdateCategoryUseCase struct {
+	mock.Mock
+}
+
+type MockDeleteCategoryUseCase struct {
+	mock.Mock
+}
+
+type MockCreateComboUseCase struct {
+	mock.Mock
+}
+
+type MockUpdateComboUseCase struct {
+	mock.Mock
+}
+
+type MockGetCombosUseCase struct {
+	mock.Mock
+}
+
+type MockGetComboByIdUseCase struct {
+	mock.Mock
+}
+
 func (mock *MockPayOrderUseCase) Execute(ctx context.Context, payment dto.Payment) (dto.PaymentResponse, error) {
 	args := mock.Called(ctx, payment)
 	err := args.Error(1)
@@ -120,13 +199,23 @@ func (mock *MockGetOrdersWaitingPaymentUseCase) Execute(ctx context.Context) ([]
 	return args.Get(0).([]dto.OrderResponse), nil
 }
 
+func (mock *MockSearchOrdersUseCase) Execute(ctx context.Context, search dto.OrderSearch) (dto.OrderSearchResponse, error) {
+	args := mock.Called(ctx, search)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.OrderSearchResponse{}, err
+	}
+
+	return args.Get(0).(dto.OrderSearchResponse), nil
+}
+
 func (m *MockCreateOrderUseCase) Execute(
 	ctx context.Context,
 	order dto.Order,
 	date int64,
-	wg *sync.WaitGroup,
-	_ chan bool) (dto.OrderResponse, error) {
-	args := m.Called(ctx, order, date, wg, mock.Anything)
+) (dto.OrderResponse, error) {
+	args := m.Called(ctx, order, date)
 	err := args.Error(1)
 
 	if err != nil {
@@ -169,15 +258,15 @@ func (m *MockGetProductsByIDUseCase) Execute(ctx context.Context, id uint) (dto.
 	return args.Get(0).(dto.ProductResponse), nil
 }
 
-func (mock *MockCreateOrderUseCase) GenerateTicket(ctx context.Context, date int64) int {
-	args := mock.Called(ctx, date)
-	err := args.Error(1)
+func (mock *MockUpdateOrderStatusUseCase) Execute(ctx context.Context, orderId uint, status string) error {
+	args := mock.Called(ctx, orderId, status)
+	err := args.Error(0)
 
 	if err != nil {
-		return 0
+		return err
 	}
 
-	return args.Get(0).(int)
+	return nil
 }
 
 func (mock *MockUpdateToPreparingUseCase) Execute(ctx context.Context, orderId uint) error {
@@ -202,6 +291,17 @@ func (mock *MockDeleteProductUseCase) Execute(ctx context.Context, productId uin
 	return nil
 }
 
+func (mock *MockRestoreProductUseCase) Execute(ctx context.Context, productId uint) error {
+	args := mock.Called(ctx, productId)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (mock *MockUpdateProductUseCase) Execute(ctx context.Context, product dto.ProductForm) error {
 	args := mock.Called(ctx, product)
 	err := args.Error(0)
@@ -213,6 +313,17 @@ func (mock *MockUpdateProductUseCase) Execute(ctx context.Context, product dto.P
 	return nil
 }
 
+func (mock *MockPatchProductUseCase) Execute(ctx context.Context, productId uint, patch dto.ProductPatchForm) error {
+	args := mock.Called(ctx, productId, patch)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (mock *MockUpdateToDoneUseCase) Execute(ctx context.Context, orderId uint) error {
 	args := mock.Called(ctx, orderId)
 	err := args.Error(1)
@@ -246,6 +357,17 @@ func (mock *MockUpdateToNotDeliveredUseCase) Execute(ctx context.Context, orderI
 	return nil
 }
 
+func (mock *MockGetOrderHistoryUseCase) Execute(ctx context.Context, orderId uint) ([]dto.OrderStatusEventResponse, error) {
+	args := mock.Called(ctx, orderId)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.OrderStatusEventResponse{}, err
+	}
+
+	return args.Get(0).([]dto.OrderStatusEventResponse), nil
+}
+
 func (mock *MockGetOrderByIdUseCase) Execute(ctx context.Context, orderId uint) (dto.OrderResponse, error) {
 	args := mock.Called(ctx, orderId)
 	err := args.Error(1)
@@ -262,7 +384,166 @@ func (mock *MockGetPaymentTypesUseCase) Execute() []string {
 	return args.Get(0).([]string)
 }
 
-func (mock *MockGetCategoryUseCase) Execute() []string {
-	args := mock.Called()
-	return args.Get(0).([]string)
+func (mock *MockGetCategoryUseCase) Execute(ctx context.Context, onlyActive bool) ([]dto.CategoryResponse, error) {
+	args := mock.Called(ctx, onlyActive)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.CategoryResponse{}, err
+	}
+
+	return args.Get(0).([]dto.CategoryResponse), nil
+}
+
+func (mock *MockCreateCategoryUseCase) Execute(ctx context.Context, category dto.CategoryForm) (uint, error) {
+	args := mock.Called(ctx, category)
+	err := args.Error(1)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return args.Get(0).(uint), nil
+}
+
+func (mock *MockGetCategoryByIdUseCase) Execute(ctx context.Context, id uint) (dto.CategoryResponse, error) {
+	args := mock.Called(ctx, id)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.CategoryResponse{}, err
+	}
+
+	return args.Get(0).(dto.CategoryResponse), nil
+}
+
+func (mock *MockUpdateCategoryUseCase) Execute(ctx context.Context, category dto.CategoryForm) error {
+	args := mock.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (mock *MockDeleteCategoryUseCase) Execute(ctx context.Context, id uint) error {
+	args := mock.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (mock *MockCreatePayingOrderUseCase) Execute(ctx context.Context, order dto.PayingOrder) (dto.OrderResponse, error) {
+	args := mock.Called(ctx, order)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.OrderResponse{}, err
+	}
+
+	return args.Get(0).(dto.OrderResponse), nil
+}
+
+func (mock *MockFinishOrderWithPaymentUseCase) Execute(ctx context.Context, orderId uint, paymentId string, date int64) error {
+	args := mock.Called(ctx, orderId, paymentId, date)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockDeletePayingOrderUseCase) Execute(ctx context.Context, orderId uint) error {
+	args := mock.Called(ctx, orderId)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockProcessPaymentNotificationUseCase) Execute(ctx context.Context, notification dto.PaymentNotification, date int64) error {
+	args := mock.Called(ctx, notification, date)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockCreateQRCodeOrderUseCase) Execute(ctx context.Context, order dto.QRCodeOrder) (dto.QRCodeOrderResponse, error) {
+	args := mock.Called(ctx, order)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.QRCodeOrderResponse{}, err
+	}
+
+	return args.Get(0).(dto.QRCodeOrderResponse), nil
+}
+
+func (mock *MockCancelOrderUseCase) Execute(ctx context.Context, orderId uint, cancel dto.OrderCancelForm) error {
+	args := mock.Called(ctx, orderId, cancel)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockUpdateOrderPriorityUseCase) Execute(ctx context.Context, orderId uint, form dto.OrderPriorityForm) error {
+	args := mock.Called(ctx, orderId, form)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockCreateComboUseCase) Execute(ctx context.Context, combo dto.ComboForm) (uint, error) {
+	args := mock.Called(ctx, combo)
+	err := args.Error(1)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return args.Get(0).(uint), nil
+}
+
+func (mock *MockUpdateComboUseCase) Execute(ctx context.Context, combo dto.ComboForm) error {
+	args := mock.Called(ctx, combo)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockGetCombosUseCase) Execute(ctx context.Context) ([]dto.Combo, error) {
+	args := mock.Called(ctx)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.Combo{}, err
+	}
+
+	return args.Get(0).([]dto.Combo), nil
+}
+
+func (mock *MockGetComboByIdUseCase) Execute(ctx context.Context, id uint) (dto.Combo, error) {
+	args := mock.Called(ctx, id)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.Combo{}, err
+	}
+
+	return args.Get(0).(dto.Combo), nil
 }
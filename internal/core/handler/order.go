@@ -4,7 +4,6 @@ import (
 	"log"
 	"net/http"
 	"strconv"
-	"sync"
 	"time"
 
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
@@ -38,18 +37,95 @@ func CreateOrderHandler(createOrder usecases.CreateOrderUseCase) http.HandlerFun
 			return
 		}
 
-		now := time.Now()
-		orderDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		response, err := createOrder.Execute(r.Context(), order, getTicketDate())
 
-		// Create this to prevent 2 process/goroutines create order with the same TicketNumber
-		var waitGroup sync.WaitGroup
-		ch := make(chan bool, 1)
-		waitGroup.Add(1)
+		if err != nil {
+			log.Print("create order", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, response)
+	}
+}
 
-		response, err := createOrder.Execute(r.Context(), order, orderDate.UnixMilli(), &waitGroup, ch)
+// @Summary Create new paying order
+// @Description Create a new order waiting for the payment, with Em pagamento status.
+// @Description The order gets its Ticket only when the payment is confirmed.
+// @Description The paymentId to charge is generated by the server and returned in the response
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param product body dto.PayingOrder true "order"
+// @Success 200 {object} dto.OrderResponse
+// @Failure 400 "Order has required fields"
+// @Failure 422 "Some product does not exist or the prices are outdated"
+// @Router /api/orders/paying [post]
+func CreatePayingOrderHandler(createPayingOrder usecases.CreatePayingOrderUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var order dto.PayingOrder
+
+		err := httpserver.DecodeJSONBody(w, r, &order)
 
 		if err != nil {
-			log.Print("create order", map[string]interface{}{
+			log.Print("decoding paying order body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		response, err := createPayingOrder.Execute(r.Context(), order)
+
+		if err != nil {
+			log.Print("create paying order", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, response)
+	}
+}
+
+// @Summary Create new QR Code order
+// @Description Create a new paying order to be paid with PIX. The response has the PIX copia e cola
+// @Description payload and its QR Code PNG image (base64) to be shown by the totem.
+// @Description Orders not paid until expiresAt are deleted and a payment approved after that is refunded
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param order body dto.QRCodeOrder true "order"
+// @Success 200 {object} dto.QRCodeOrderResponse
+// @Failure 400 "Order has required fields"
+// @Failure 422 "Some product does not exist or the prices are outdated"
+// @Failure 503 "The PIX checkout is not configured"
+// @Router /api/orders/qrcode [post]
+func CreateQRCodeOrderHandler(createQRCodeOrder usecases.CreateQRCodeOrderUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var order dto.QRCodeOrder
+
+		err := httpserver.DecodeJSONBody(w, r, &order)
+
+		if err != nil {
+			log.Print("decoding qr code order body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		response, err := createQRCodeOrder.Execute(r.Context(), order)
+
+		if err != nil {
+			log.Print("create qr code order", map[string]interface{}{
 				"error":  err.Error(),
 				"status": httpserver.GetStatusCodeFromError(err),
 			})
@@ -61,8 +137,127 @@ func CreateOrderHandler(createOrder usecases.CreateOrderUseCase) http.HandlerFun
 	}
 }
 
+// @Summary Confirm the payment of an order
+// @Description Finish a paying order with the payment ID, when the payment was not confirmed
+// @Description by the payment webhook. The order goes to Criado status and gets the next Ticket number of the day.
+// @Description Only a manager (a manager staff token) can confirm a payment
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param id path int true "12"
+// @Param payment body dto.OrderPaymentForm true "payment"
+// @Success 204
+// @Failure 400 "The payment ID is required"
+// @Failure 403 "A manager staff token is required"
+// @Failure 404 "Order not found"
+// @Failure 409 "The payment ID is already used by another order"
+// @Failure 428 "Precondition failed: Need to be with status Em pagamento"
+// @Router /api/orders/{id}/payment [put]
+func FinishOrderWithPaymentHandler(finishOrderWithPayment usecases.FinishOrderWithPaymentUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("finish order with payment", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		id, err := getOrderId(idStr)
+
+		if err != nil {
+			log.Print("finish order with payment", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		var form dto.OrderPaymentForm
+
+		err = httpserver.DecodeJSONBody(w, r, &form)
+
+		if err != nil {
+			log.Print("decoding order payment body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		err = finishOrderWithPayment.Execute(r.Context(), id, form.PaymentID, getTicketDate())
+
+		if err != nil {
+			log.Print("finish order with payment", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
+
+// @Summary Delete a paying order
+// @Description Delete an order whose payment was abandoned or expired.
+// @Description Only orders with Em pagamento status can be deleted
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param id path int true "12"
+// @Success 204
+// @Failure 404 "Order not found"
+// @Failure 409 "The order was paid while it was being deleted"
+// @Failure 428 "Precondition failed: Need to be with status Em pagamento"
+// @Router /api/orders/{id} [delete]
+func DeletePayingOrderHandler(deletePayingOrder usecases.DeletePayingOrderUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("delete paying order", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		id, err := getOrderId(idStr)
+
+		if err != nil {
+			log.Print("delete paying order", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		err = deletePayingOrder.Execute(r.Context(), id)
+
+		if err != nil {
+			log.Print("delete paying order", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
+
 // @Summary Get order by Id
-// @Description Get an order by Id
+// @Description Get an order by Id. The ETag header can be sent back in If-Match when changing the order status
 // @Tags Order
 // @Accept json
 // @Produce json
@@ -105,6 +300,57 @@ func GetOrderByIdHandler(getOrderById usecases.GetOrderByIdUseCase) http.Handler
 			return
 		}
 
+		w.Header().Set(etagHeader, usecases.OrderETag(response))
+		httpserver.SendResponseSuccess(w, response)
+	}
+}
+
+// @Summary Get order status history
+// @Description Get every status change of an order, oldest first, with the role that made it
+// @Description and the request ID. This endpoint will be used by the support to check disputed orders
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param id path int true "12"
+// @Success 200 {object} []dto.OrderStatusEventResponse
+// @Failure 400 "Invalid order id"
+// @Failure 404 "Order history not found"
+// @Router /api/orders/{id}/history [get]
+func GetOrderHistoryHandler(getOrderHistory usecases.GetOrderHistoryUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("get order history path", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		id, err := getOrderId(idStr)
+
+		if err != nil {
+			log.Print("get order history path", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		response, err := getOrderHistory.Execute(r.Context(), id)
+
+		if err != nil {
+			log.Print("get order history", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
 		httpserver.SendResponseSuccess(w, response)
 	}
 }
@@ -182,14 +428,218 @@ func GetOrdersWaitingPaymentHandler(getOrdersWaitingPayment usecases.GetOrdersWa
 	}
 }
 
+// @Summary Update an order status
+// @Description Update an order to any status allowed by the order state machine.
+// @Description The role of the staff token sent in the Authorization header must be allowed to make the transition
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param id path int true "12"
+// @Param If-Match header string false "ETag of GET /api/orders/{id}"
+// @Param status body dto.OrderStatusForm true "status"
+// @Success 204
+// @Failure 403 "The role can not make this transition"
+// @Failure 404 "Order not found"
+// @Failure 409 "The order status was changed by another request"
+// @Failure 412 "The If-Match header does not match the order ETag"
+// @Failure 428 "Precondition failed: The order is not in a status that allows this transition"
+// @Router /api/orders/{id}/status [put]
+func UpdateOrderStatusHandler(updateOrderStatus usecases.UpdateOrderStatusUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("update order status", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		id, err := getOrderId(idStr)
+
+		if err != nil {
+			log.Print("update order status", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		var form dto.OrderStatusForm
+
+		err = httpserver.DecodeJSONBody(w, r, &form)
+
+		if err != nil {
+			log.Print("decoding order status body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		err = updateOrderStatus.Execute(r.Context(), id, form.Status)
+
+		if err != nil {
+			log.Print("update order status", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
+
+// @Summary Cancel an order
+// @Description Cancel an order with a reason code. Orders can be cancelled in Em pagamento and Criado status.
+// @Description Orders in Preparando status can only be cancelled by a manager (a manager staff token).
+// @Description A pending refund is created when the order was already paid
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param id path int true "12"
+// @Param If-Match header string false "ETag of GET /api/orders/{id}"
+// @Param cancel body dto.OrderCancelForm true "cancel"
+// @Success 204
+// @Failure 403 "The role can not cancel the order"
+// @Failure 404 "Order not found"
+// @Failure 409 "The order status was changed by another request"
+// @Failure 412 "The If-Match header does not match the order ETag"
+// @Failure 428 "Precondition failed: Need to be with status Em pagamento, Criado or Preparando"
+// @Router /api/orders/{id}/cancel [put]
+func CancelOrderHandler(cancelOrder usecases.CancelOrderUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("cancel order", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		id, err := getOrderId(idStr)
+
+		if err != nil {
+			log.Print("cancel order", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		var form dto.OrderCancelForm
+
+		err = httpserver.DecodeJSONBody(w, r, &form)
+
+		if err != nil {
+			log.Print("decoding order cancel body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		err = cancelOrder.Execute(r.Context(), id, form)
+
+		if err != nil {
+			log.Print("cancel order", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
+
+// @Summary Update an order priority
+// @Description Update the priority used to sort the kitchen queue. Only a manager (a manager staff token) can change it
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param id path int true "12"
+// @Param priority body dto.OrderPriorityForm true "priority"
+// @Success 204
+// @Failure 400 "The priority must not be negative"
+// @Failure 403 "Only a manager can change the order priority"
+// @Failure 404 "Order not found"
+// @Router /api/orders/{id}/priority [put]
+func UpdateOrderPriorityHandler(updateOrderPriority usecases.UpdateOrderPriorityUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("update order priority", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		id, err := getOrderId(idStr)
+
+		if err != nil {
+			log.Print("update order priority", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		var form dto.OrderPriorityForm
+
+		err = httpserver.DecodeJSONBody(w, r, &form)
+
+		if err != nil {
+			log.Print("decoding order priority body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		err = updateOrderPriority.Execute(r.Context(), id, form)
+
+		if err != nil {
+			log.Print("update order priority", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
+
 // @Summary Update an order to PREPARING
 // @Description Update an order. This service wil be used by the kitchen to notify a customer that the order is being prepared
 // @Tags Order
 // @Accept json
 // @Produce json
 // @Param id path int true "12"
+// @Param If-Match header string false "ETag of GET /api/orders/{id}"
 // @Success 204
 // @Failure 404 "Order not found"
+// @Failure 409 "The order status was changed by another request"
+// @Failure 412 "The If-Match header does not match the order ETag"
 // @Failure 428 "Precondition failed: Need to be with status Criado"
 // @Router /api/orders/{id}/preparing [put]
 func UpdateOrderPreparingHandler(updateToPreparing usecases.UpdateToPreparingUseCase) http.HandlerFunc {
@@ -237,8 +687,11 @@ func UpdateOrderPreparingHandler(updateToPreparing usecases.UpdateToPreparingUse
 // @Accept json
 // @Produce json
 // @Param id path int true "12"
+// @Param If-Match header string false "ETag of GET /api/orders/{id}"
 // @Success 204
 // @Failure 404 "Order not found"
+// @Failure 409 "The order status was changed by another request"
+// @Failure 412 "The If-Match header does not match the order ETag"
 // @Failure 428 "Precondition failed: Need to be with status Preparando"
 // @Router /api/orders/{id}/done [put]
 func UpdateOrderDoneHandler(updateToDone usecases.UpdateToDoneUseCase) http.HandlerFunc {
@@ -286,8 +739,11 @@ func UpdateOrderDoneHandler(updateToDone usecases.UpdateToDoneUseCase) http.Hand
 // @Accept json
 // @Produce json
 // @Param id path int true "12"
+// @Param If-Match header string false "ETag of GET /api/orders/{id}"
 // @Success 204
 // @Failure 404 "Order not found"
+// @Failure 409 "The order status was changed by another request"
+// @Failure 412 "The If-Match header does not match the order ETag"
 // @Failure 428 "Precondition failed: Need to be with status Finalizado"
 // @Router /api/orders/{id}/delivered [put]
 func UpdateOrderDeliveredHandler(updateToDelivered usecases.UpdateToDeliveredUseCase) http.HandlerFunc {
@@ -335,8 +791,11 @@ func UpdateOrderDeliveredHandler(updateToDelivered usecases.UpdateToDeliveredUse
 // @Accept json
 // @Produce json
 // @Param id path int true "12"
+// @Param If-Match header string false "ETag of GET /api/orders/{id}"
 // @Success 204
 // @Failure 404 "Order not found"
+// @Failure 409 "The order status was changed by another request"
+// @Failure 412 "The If-Match header does not match the order ETag"
 // @Failure 428 "Precondition failed: Need to be with status Finalizado"
 // @Router /api/orders/{id}/not-delivered [put]
 func UpdateOrderNotDeliveredandler(updateToNotDelivered usecases.UpdateToNotDeliveredUseCase) http.HandlerFunc {
@@ -387,3 +846,9 @@ func getOrderId(orderdStr string) (uint, error) {
 
 	return uint(orderId), nil
 }
+
+// getTicketDate returns the start of the current day, used to restart the Ticket numbers every day
+func getTicketDate() int64 {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).UnixMilli()
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/pkg/httpserver"
+)
+
+const (
+	lastEventIdHeader = "Last-Event-ID"
+	lastEventIdQuery  = "lastEventId"
+
+	// orderEventsReset tells the client that some events were lost and the
+	// orders must be reloaded from /api/orders/to-prepare or /api/orders/follow
+	orderEventsReset = "reset"
+
+	streamWriteTimeout = 10 * time.Second
+	streamHeartbeat    = 15 * time.Second
+	streamRetry        = 3000
+)
+
+// @Summary Order events stream
+// @Description Server-Sent Events stream of order created, status changed and canceled events.
+// @Description This endpoint will be used by the kitchen and the customer panel instead of polling.
+// @Description Clients resume with the Last-Event-ID header (or the lastEventId query) after reconnecting.
+// @Description A reset event means some events were lost and the orders must be reloaded
+// @Tags Order
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "Last event received"
+// @Param lastEventId query int false "Last event received"
+// @Success 200 {object} events.OrderEvent
+// @Failure 400 "Invalid Last-Event-ID"
+// @Router /api/orders/events [get]
+func OrderEventsHandler(bus *events.OrderEventBus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lastEventId, err := getLastEventId(r)
+
+		if err != nil {
+			log.Print("order events last event id", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		subscription, missed, complete := bus.Subscribe(lastEventId)
+		defer bus.Unsubscribe(subscription)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		stream := &eventStream{
+			w:          w,
+			controller: http.NewResponseController(w),
+		}
+
+		err = stream.write(fmt.Sprintf("retry: %d\n\n", streamRetry))
+
+		if err == nil && !complete {
+			err = stream.write(fmt.Sprintf("id: 0\nevent: %v\ndata: {}\n\n", orderEventsReset))
+		}
+
+		for _, event := range missed {
+			if err != nil {
+				break
+			}
+
+			err = stream.writeEvent(event)
+		}
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		for err == nil {
+			select {
+			case <-r.Context().Done():
+				return
+			case event, ok := <-subscription.Events():
+				if !ok {
+					return
+				}
+
+				err = stream.writeEvent(event)
+			case <-heartbeat.C:
+				err = stream.write(": ping\n\n")
+			}
+		}
+
+		log.Print("order events stream", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// @Summary Order events WebSocket
+// @Description WebSocket option of /api/orders/events. Each message is a JSON order event.
+// @Description Clients resume with the lastEventId query after reconnecting
+// @Tags Order
+// @Param lastEventId query int false "Last event received"
+// @Success 101
+// @Failure 400 "Invalid lastEventId"
+// @Router /api/orders/events/ws [get]
+func OrderEventsWebSocketHandler(bus *events.OrderEventBus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lastEventId, err := getLastEventId(r)
+
+		if err != nil {
+			log.Print("order events last event id", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		// the server write timeout would still be set on the connection after the upgrade
+		controller := http.NewResponseController(w)
+		controller.SetReadDeadline(time.Time{})
+		controller.SetWriteDeadline(time.Time{})
+
+		// the feed is read only, so the panels can be served from any origin
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+			OriginPatterns: []string{"*"},
+		})
+
+		if err != nil {
+			log.Print("order events websocket accept", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+		defer conn.CloseNow()
+
+		ctx := conn.CloseRead(r.Context())
+
+		subscription, missed, complete := bus.Subscribe(lastEventId)
+		defer bus.Unsubscribe(subscription)
+
+		if !complete {
+			err = writeWebSocketEvent(ctx, conn, events.OrderEvent{Type: orderEventsReset})
+		}
+
+		for _, event := range missed {
+			if err != nil {
+				break
+			}
+
+			err = writeWebSocketEvent(ctx, conn, event)
+		}
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		for err == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-subscription.Events():
+				if !ok {
+					conn.Close(websocket.StatusTryAgainLater, "client too slow, reconnect with lastEventId")
+					return
+				}
+
+				err = writeWebSocketEvent(ctx, conn, event)
+			case <-heartbeat.C:
+				pingCtx, cancel := context.WithTimeout(ctx, streamWriteTimeout)
+				err = conn.Ping(pingCtx)
+				cancel()
+			}
+		}
+
+		log.Print("order events websocket", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// eventStream writes Server-Sent Events. Each write gets its own deadline so the
+// server write timeout does not close the stream, while dead clients are still dropped
+type eventStream struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+}
+
+func (stream *eventStream) writeEvent(event events.OrderEvent) error {
+	data, err := json.Marshal(event)
+
+	if err != nil {
+		return err
+	}
+
+	return stream.write(fmt.Sprintf("id: %d\nevent: %v\ndata: %s\n\n", event.ID, event.Type, data))
+}
+
+func (stream *eventStream) write(message string) error {
+	err := stream.controller.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	_, err = stream.w.Write([]byte(message))
+
+	if err != nil {
+		return err
+	}
+
+	return stream.controller.Flush()
+}
+
+func writeWebSocketEvent(ctx context.Context, conn *websocket.Conn, event events.OrderEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, streamWriteTimeout)
+	defer cancel()
+
+	return wsjson.Write(ctx, conn, event)
+}
+
+func getLastEventId(r *http.Request) (uint64, error) {
+	value := r.Header.Get(lastEventIdHeader)
+
+	if value == "" {
+		value = r.URL.Query().Get(lastEventIdQuery)
+	}
+
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseUint(value, 10, 64)
+}
@@ -0,0 +1,230 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/events"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
+)
+
+// newOrderEventsServer uses a write timeout much shorter than the test waits, like httpserver.Server does
+func newOrderEventsServer(h http.HandlerFunc) *httptest.Server {
+	server := httptest.NewUnstartedServer(h)
+	server.Config.WriteTimeout = 200 * time.Millisecond
+	server.Start()
+
+	return server
+}
+
+// sseClient fails the test instead of hanging when the stream stops sending
+var sseClient = &http.Client{Timeout: 5 * time.Second}
+
+type sseMessage struct {
+	id    string
+	event string
+	data  string
+}
+
+func readSSEMessage(t *testing.T, reader *bufio.Reader) sseMessage {
+	var message sseMessage
+
+	for {
+		line, err := reader.ReadString('\n')
+
+		if !assert.NoError(t, err) {
+			return message
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+
+		if line == "" {
+			if message.event != "" {
+				return message
+			}
+
+			continue
+		}
+
+		if value, ok := strings.CutPrefix(line, "id: "); ok {
+			message.id = value
+		} else if value, ok := strings.CutPrefix(line, "event: "); ok {
+			message.event = value
+		} else if value, ok := strings.CutPrefix(line, "data: "); ok {
+			message.data = value
+		}
+	}
+}
+
+func TestOrderEventsHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got events after the server write timeout when calling order events handler", func(t *testing.T) {
+		t.Parallel()
+
+		bus := events.NewOrderEventBus(10)
+		server := newOrderEventsServer(handler.OrderEventsHandler(bus))
+		defer server.Close()
+
+		response, err := sseClient.Get(server.URL)
+		assert.NoError(t, err)
+		defer response.Body.Close()
+
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+		time.Sleep(400 * time.Millisecond)
+
+		bus.Publish(events.OrderEvent{
+			Type:        events.OrderCreated,
+			OrderID:     12,
+			OrderStatus: "Criado",
+		})
+
+		message := readSSEMessage(t, bufio.NewReader(response.Body))
+
+		assert.Equal(t, "1", message.id)
+		assert.Equal(t, events.OrderCreated, message.event)
+
+		var event events.OrderEvent
+		err = json.Unmarshal([]byte(message.data), &event)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(12), event.OrderID)
+		assert.Equal(t, "Criado", event.OrderStatus)
+	})
+
+	t.Run("got missed events when resuming with Last-Event-ID in order events handler", func(t *testing.T) {
+		t.Parallel()
+
+		bus := events.NewOrderEventBus(10)
+		server := newOrderEventsServer(handler.OrderEventsHandler(bus))
+		defer server.Close()
+
+		bus.Publish(events.OrderEvent{Type: events.OrderCreated, OrderID: 1})
+		bus.Publish(events.OrderEvent{Type: events.OrderStatusChanged, OrderID: 1})
+		bus.Publish(events.OrderEvent{Type: events.OrderCanceled, OrderID: 1})
+
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+		req.Header.Add("Last-Event-ID", "1")
+
+		response, err := sseClient.Do(req)
+		assert.NoError(t, err)
+		defer response.Body.Close()
+
+		reader := bufio.NewReader(response.Body)
+
+		message := readSSEMessage(t, reader)
+		assert.Equal(t, "2", message.id)
+		assert.Equal(t, events.OrderStatusChanged, message.event)
+
+		message = readSSEMessage(t, reader)
+		assert.Equal(t, "3", message.id)
+		assert.Equal(t, events.OrderCanceled, message.event)
+	})
+
+	t.Run("got reset event when resuming from a lost event in order events handler", func(t *testing.T) {
+		t.Parallel()
+
+		bus := events.NewOrderEventBus(10)
+		server := newOrderEventsServer(handler.OrderEventsHandler(bus))
+		defer server.Close()
+
+		response, err := sseClient.Get(server.URL + "?lastEventId=40")
+		assert.NoError(t, err)
+		defer response.Body.Close()
+
+		message := readSSEMessage(t, bufio.NewReader(response.Body))
+
+		assert.Equal(t, "0", message.id)
+		assert.Equal(t, "reset", message.event)
+	})
+
+	t.Run("got error on invalid Last-Event-ID when calling order events handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/orders/events", nil)
+		req.Header.Add("Last-Event-ID", "x1")
+
+		recorder := httptest.NewRecorder()
+
+		orderEventsHandler := handler.OrderEventsHandler(events.NewOrderEventBus(10))
+
+		orderEventsHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestOrderEventsWebSocketHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got events after the server write timeout when calling order events websocket handler", func(t *testing.T) {
+		t.Parallel()
+
+		bus := events.NewOrderEventBus(10)
+		server := newOrderEventsServer(handler.OrderEventsWebSocketHandler(bus))
+		defer server.Close()
+
+		bus.Publish(events.OrderEvent{Type: events.OrderCreated, OrderID: 1})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		conn, _, err := websocket.Dial(ctx, server.URL+"?lastEventId=0", nil)
+		assert.NoError(t, err)
+		defer conn.CloseNow()
+
+		time.Sleep(400 * time.Millisecond)
+
+		bus.Publish(events.OrderEvent{
+			Type:           events.OrderStatusChanged,
+			OrderID:        1,
+			PreviousStatus: "Criado",
+			OrderStatus:    "Preparando",
+		})
+
+		var event events.OrderEvent
+		err = wsjson.Read(ctx, conn, &event)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), event.ID)
+		assert.Equal(t, events.OrderStatusChanged, event.Type)
+		assert.Equal(t, "Preparando", event.OrderStatus)
+	})
+
+	t.Run("got missed events when resuming with lastEventId in order events websocket handler", func(t *testing.T) {
+		t.Parallel()
+
+		bus := events.NewOrderEventBus(10)
+		server := newOrderEventsServer(handler.OrderEventsWebSocketHandler(bus))
+		defer server.Close()
+
+		bus.Publish(events.OrderEvent{Type: events.OrderCreated, OrderID: 1})
+		bus.Publish(events.OrderEvent{Type: events.OrderCanceled, OrderID: 1})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		conn, _, err := websocket.Dial(ctx, server.URL+"?lastEventId=1", nil)
+		assert.NoError(t, err)
+		defer conn.CloseNow()
+
+		var event events.OrderEvent
+		err = wsjson.Read(ctx, conn, &event)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), event.ID)
+		assert.Equal(t, events.OrderCanceled, event.Type)
+	})
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/usecases"
+	"github.com/thiagoluis88git/tech1-orders/pkg/httpserver"
+)
+
+const searchDateLayout = "2006-01-02"
+
+// @Summary Search orders
+// @Description Search the orders of any status, including the delivered and cancelled ones.
+// @Description The orders are paginated by cursor: send the nextCursor of the response to get the next page,
+// @Description keeping the same filters and sort. nextCursor is null on the last page.
+// @Description from and to accept RFC 3339 or a date (2006-01-02). A date in to includes the whole day.
+// @Description Only the staff can search (a kitchen, waiter or manager staff token)
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param status query []string false "Order status, repeated or comma separated" collectionFormat(multi)
+// @Param from query string false "Orders created from (inclusive)"
+// @Param to query string false "Orders created until (exclusive)"
+// @Param cpf query string false "Customer CPF"
+// @Param ticket query int false "Ticket number"
+// @Param productId query int false "Orders with this product"
+// @Param sort query string false "orderDate, updatedAt or totalPrice. Prefix with - to sort descending" default(-orderDate)
+// @Param limit query int false "Page size, up to 100" default(20)
+// @Param cursor query string false "nextCursor of the previous page"
+// @Success 200 {object} dto.OrderSearchResponse
+// @Failure 400 "Invalid filter, sort, limit or cursor"
+// @Failure 401 "A staff token is required to search the orders"
+// @Router /api/orders [get]
+func SearchOrdersHandler(searchOrders usecases.SearchOrdersUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		search, err := getOrderSearch(r.URL.Query())
+
+		if err != nil {
+			log.Print("search orders query", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		response, err := searchOrders.Execute(r.Context(), search)
+
+		if err != nil {
+			log.Print("search orders", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, response)
+	}
+}
+
+func getOrderSearch(query url.Values) (dto.OrderSearch, error) {
+	search := dto.OrderSearch{
+		SortBy:     dto.OrderSortOrderDate,
+		Descending: true,
+		Cursor:     query.Get("cursor"),
+	}
+
+	for _, value := range query["status"] {
+		for _, status := range strings.Split(value, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				search.Statuses = append(search.Statuses, status)
+			}
+		}
+	}
+
+	if value := query.Get("from"); value != "" {
+		from, err := getSearchDate(value, false)
+
+		if err != nil {
+			return dto.OrderSearch{}, err
+		}
+
+		search.From = &from
+	}
+
+	if value := query.Get("to"); value != "" {
+		to, err := getSearchDate(value, true)
+
+		if err != nil {
+			return dto.OrderSearch{}, err
+		}
+
+		search.To = &to
+	}
+
+	if value := query.Get("cpf"); value != "" {
+		search.CPF = &value
+	}
+
+	if value := query.Get("ticket"); value != "" {
+		ticket, err := strconv.Atoi(value)
+
+		if err != nil {
+			return dto.OrderSearch{}, fmt.Errorf("invalid ticket: %w", err)
+		}
+
+		search.TicketNumber = &ticket
+	}
+
+	if value := query.Get("productId"); value != "" {
+		productId, err := strconv.ParseUint(value, 10, 0)
+
+		if err != nil {
+			return dto.OrderSearch{}, fmt.Errorf("invalid productId: %w", err)
+		}
+
+		id := uint(productId)
+		search.ProductID = &id
+	}
+
+	if value := query.Get("sort"); value != "" {
+		search.SortBy = strings.TrimPrefix(value, "-")
+		search.Descending = strings.HasPrefix(value, "-")
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+
+		if err != nil {
+			return dto.OrderSearch{}, fmt.Errorf("invalid limit: %w", err)
+		}
+
+		search.Limit = limit
+	}
+
+	return search, nil
+}
+
+// getSearchDate parses RFC 3339 or a date. A date ending the range moves to the next day,
+// so the range includes all the orders of that day
+func getSearchDate(value string, end bool) (time.Time, error) {
+	date, err := time.Parse(time.RFC3339, value)
+
+	if err == nil {
+		return date, nil
+	}
+
+	date, err = time.ParseInLocation(searchDateLayout, value, time.Local)
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %v, use RFC 3339 or %v", value, searchDateLayout)
+	}
+
+	if end {
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return date, nil
+}
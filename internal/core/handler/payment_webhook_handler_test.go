@@ -0,0 +1,164 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+const webhookSecret = "webhook-secret"
+
+// fakePaymentGateway sends signed notifications to the webhook the same way the payment gateway does
+type fakePaymentGateway struct {
+	secret string
+	url    string
+}
+
+func newFakePaymentGateway(t *testing.T, secret string, processPaymentNotification *MockProcessPaymentNotificationUseCase) *fakePaymentGateway {
+	router := chi.NewRouter()
+	router.Post("/api/webhooks/payments", handler.PaymentWebhookHandler(webhookSecret, processPaymentNotification))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return &fakePaymentGateway{
+		secret: secret,
+		url:    server.URL + "/api/webhooks/payments",
+	}
+}
+
+func (gateway *fakePaymentGateway) notify(t *testing.T, notification dto.PaymentNotification) *http.Response {
+	jsonData, err := json.Marshal(notification)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, gateway.url, bytes.NewBuffer(jsonData))
+	assert.NoError(t, err)
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add(handler.PaymentSignatureHeader, handler.SignPayload(gateway.secret, jsonData))
+
+	response, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+
+	t.Cleanup(func() { response.Body.Close() })
+
+	return response
+}
+
+func TestPaymentWebhookHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when calling payment webhook handler", func(t *testing.T) {
+		t.Parallel()
+
+		processPaymentNotification := new(MockProcessPaymentNotificationUseCase)
+		gateway := newFakePaymentGateway(t, webhookSecret, processPaymentNotification)
+
+		notification := dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    "approved",
+		}
+
+		processPaymentNotification.On("Execute", mock.Anything, notification, mock.Anything).Return(nil)
+
+		response := gateway.notify(t, notification)
+
+		assert.Equal(t, http.StatusNoContent, response.StatusCode)
+		processPaymentNotification.AssertNumberOfCalls(t, "Execute", 1)
+	})
+
+	t.Run("got success when replaying notification on payment webhook handler", func(t *testing.T) {
+		t.Parallel()
+
+		processPaymentNotification := new(MockProcessPaymentNotificationUseCase)
+		gateway := newFakePaymentGateway(t, webhookSecret, processPaymentNotification)
+
+		notification := dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    "approved",
+		}
+
+		processPaymentNotification.On("Execute", mock.Anything, notification, mock.Anything).Return(nil)
+
+		firstResponse := gateway.notify(t, notification)
+		replayedResponse := gateway.notify(t, notification)
+
+		assert.Equal(t, http.StatusNoContent, firstResponse.StatusCode)
+		assert.Equal(t, http.StatusNoContent, replayedResponse.StatusCode)
+	})
+
+	t.Run("got error on invalid signature when calling payment webhook handler", func(t *testing.T) {
+		t.Parallel()
+
+		processPaymentNotification := new(MockProcessPaymentNotificationUseCase)
+		gateway := newFakePaymentGateway(t, "another-secret", processPaymentNotification)
+
+		response := gateway.notify(t, dto.PaymentNotification{
+			PaymentID: "12345",
+			Status:    "approved",
+		})
+
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		processPaymentNotification.AssertNotCalled(t, "Execute")
+	})
+
+	t.Run("got error on missing signature when calling payment webhook handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/webhooks/payments", bytes.NewBuffer([]byte(`{"paymentId":"12345","status":"approved"}`)))
+		req.Header.Add("Content-Type", "application/json")
+
+		recorder := httptest.NewRecorder()
+
+		processPaymentNotification := new(MockProcessPaymentNotificationUseCase)
+
+		paymentWebhookHandler := handler.PaymentWebhookHandler(webhookSecret, processPaymentNotification)
+
+		paymentWebhookHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("got error on missing payment id when calling payment webhook handler", func(t *testing.T) {
+		t.Parallel()
+
+		processPaymentNotification := new(MockProcessPaymentNotificationUseCase)
+		gateway := newFakePaymentGateway(t, webhookSecret, processPaymentNotification)
+
+		response := gateway.notify(t, dto.PaymentNotification{
+			Status: "approved",
+		})
+
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		processPaymentNotification.AssertNotCalled(t, "Execute")
+	})
+
+	t.Run("got error on UseCase when calling payment webhook handler", func(t *testing.T) {
+		t.Parallel()
+
+		processPaymentNotification := new(MockProcessPaymentNotificationUseCase)
+		gateway := newFakePaymentGateway(t, webhookSecret, processPaymentNotification)
+
+		notification := dto.PaymentNotification{
+			PaymentID: "99999",
+			Status:    "approved",
+		}
+
+		processPaymentNotification.On("Execute", mock.Anything, notification, mock.Anything).Return(&responses.BusinessResponse{
+			StatusCode: 404,
+		})
+
+		response := gateway.notify(t, notification)
+
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+}
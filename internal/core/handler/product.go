@@ -136,12 +136,14 @@ func GetProductsByIdHandler(getProductById usecases.GetProductByIdUseCase) http.
 }
 
 // @Summary Delete a product
-// @Description Delete a product by ID
+// @Description Archive a product by ID. It leaves the menu but the orders still show it, and it can be restored
 // @Tags Product
 // @Param id path int true "12"
 // @Accept json
 // @Produce json
 // @Success 204
+// @Failure 404 "Product not found"
+// @Failure 409 "Product is in combos that are not archived"
 // @Router /api/admin/products/{id} [delete]
 func DeleteProductHandler(deleteProduct usecases.DeleteProductUseCase) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -182,13 +184,66 @@ func DeleteProductHandler(deleteProduct usecases.DeleteProductUseCase) http.Hand
 	}
 }
 
+// @Summary Restore a product
+// @Description Restore an archived product by ID, so it shows up in the menu again
+// @Tags Product
+// @Param id path int true "12"
+// @Accept json
+// @Produce json
+// @Success 204
+// @Failure 404 "Product not found"
+// @Failure 409 "Combo has archived products"
+// @Router /api/admin/products/{id}/restore [post]
+func RestoreProductHandler(restoreProduct usecases.RestoreProductUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		productIdStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("restore product", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		productId, err := strconv.Atoi(productIdStr)
+
+		if err != nil {
+			log.Print("restore product", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		err = restoreProduct.Execute(r.Context(), uint(productId))
+
+		if err != nil {
+			log.Print("restore product", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
+
 // @Summary Update a product
-// @Description Update a product by ID
+// @Description Update a product by ID with its images and combo products. A combo sent without comboProductsIds keeps its products or slots
 // @Tags Product
 // @Param id path int true "12"
+// @Param product body dto.ProductForm true "product"
 // @Accept json
 // @Produce json
 // @Success 204
+// @Failure 400 "Combo needs products or the category does not exist"
+// @Failure 404 "Product not found"
+// @Failure 409 "Combo has slots, it must be updated by the combo endpoints"
 // @Router /api/admin/products/{id} [put]
 func UpdateProductHandler(updateProduct usecases.UpdateProductUseCase) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -243,16 +298,96 @@ func UpdateProductHandler(updateProduct usecases.UpdateProductUseCase) http.Hand
 	}
 }
 
-// @Summary Get all categories
-// @Description Get all categories to filter in products by category
+// @Summary Patch a product
+// @Description Update only the fields sent of a product by ID. Images and comboProductsIds replace the whole list
 // @Tags Product
 // @Param id path int true "12"
+// @Param product body dto.ProductPatchForm true "product"
+// @Accept json
+// @Produce json
+// @Success 204
+// @Failure 400 "Combo needs products or the category does not exist"
+// @Failure 404 "Product not found"
+// @Failure 409 "Combo has slots, it must be updated by the combo endpoints"
+// @Router /api/admin/products/{id} [patch]
+func PatchProductHandler(patchProduct usecases.PatchProductUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		productIdStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("patch product", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		productId, err := strconv.Atoi(productIdStr)
+
+		if err != nil {
+			log.Print("patch product", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		var patch dto.ProductPatchForm
+
+		err = httpserver.DecodeJSONBody(w, r, &patch)
+
+		if err != nil {
+			log.Print("decoding product body for patch product", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		err = patchProduct.Execute(r.Context(), uint(productId), patch)
+
+		if err != nil {
+			log.Print("patch product", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
+
+// @Summary Get all categories
+// @Description Get the names of the menu categories, in display order, to filter in products by category
+// @Tags Product
 // @Accept json
 // @Produce json
 // @Success 200 {object} []string
 // @Router /api/products/categories [get]
 func GetCategoriesHandler(getCategoriesUseCase usecases.GetCategoriesUseCase) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		httpserver.SendResponseSuccess(w, getCategoriesUseCase.Execute())
+		categories, err := getCategoriesUseCase.Execute(r.Context(), true)
+
+		if err != nil {
+			log.Print("get categories", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		names := []string{}
+
+		for _, category := range categories {
+			names = append(names, category.Name)
+		}
+
+		httpserver.SendResponseSuccess(w, names)
 	}
 }
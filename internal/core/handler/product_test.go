@@ -67,7 +67,9 @@ func TestCreateProductHandler(t *testing.T) {
 
 		getCategoryUseCase := new(MockGetCategoryUseCase)
 
-		getCategoryUseCase.On("Execute").Return([]string{"CAT1, CAT2"})
+		getCategoryUseCase.On("Execute", req.Context(), true).Return([]dto.CategoryResponse{
+			{Id: 1, Name: "CAT1, CAT2", DisplayOrder: 1, Active: true},
+		}, nil)
 
 		getCategoryHandler := handler.GetCategoriesHandler(getCategoryUseCase)
 
@@ -630,4 +632,194 @@ func TestCreateProductHandler(t *testing.T) {
 
 		assert.Equal(t, http.StatusBadRequest, recorder.Code)
 	})
+
+	t.Run("got success when calling patching product handler", func(t *testing.T) {
+		t.Parallel()
+
+		price := float64(1990)
+		patch := dto.ProductPatchForm{
+			Price: &price,
+		}
+
+		jsonData, err := json.Marshal(patch)
+
+		assert.NoError(t, err)
+
+		body := bytes.NewBuffer(jsonData)
+
+		req := httptest.NewRequest(http.MethodPatch, "/api/admin/products/{id}", body)
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		patchProductUseCase := new(MockPatchProductUseCase)
+
+		patchProductUseCase.On("Execute", req.Context(), uint(12), patch).
+			Return(nil)
+
+		patchProductHandler := handler.PatchProductHandler(patchProductUseCase)
+
+		patchProductHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		patchProductUseCase.AssertExpectations(t)
+	})
+
+	t.Run("got error on PatchProduct UseCase when calling patching product handler", func(t *testing.T) {
+		t.Parallel()
+
+		body := bytes.NewBuffer([]byte(`{"comboProductsIds": []}`))
+
+		req := httptest.NewRequest(http.MethodPatch, "/api/admin/products/{id}", body)
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		patchProductUseCase := new(MockPatchProductUseCase)
+
+		patchProductUseCase.On("Execute", req.Context(), uint(12), dto.ProductPatchForm{ComboProductsIds: &[]uint{}}).
+			Return(&responses.BusinessResponse{
+				StatusCode: http.StatusBadRequest,
+			})
+
+		patchProductHandler := handler.PatchProductHandler(patchProductUseCase)
+
+		patchProductHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		patchProductUseCase.AssertExpectations(t)
+	})
+
+	t.Run("got error on empty name when calling patching product handler", func(t *testing.T) {
+		t.Parallel()
+
+		body := bytes.NewBuffer([]byte(`{"name": ""}`))
+
+		req := httptest.NewRequest(http.MethodPatch, "/api/admin/products/{id}", body)
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		patchProductUseCase := new(MockPatchProductUseCase)
+
+		patchProductHandler := handler.PatchProductHandler(patchProductUseCase)
+
+		patchProductHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		patchProductUseCase.AssertNotCalled(t, "Execute")
+	})
+
+	t.Run("got error on invalid id when calling patching product handler", func(t *testing.T) {
+		t.Parallel()
+
+		body := bytes.NewBuffer([]byte(`{}`))
+
+		req := httptest.NewRequest(http.MethodPatch, "/api/admin/products/{id}", body)
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "x12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		patchProductUseCase := new(MockPatchProductUseCase)
+
+		patchProductHandler := handler.PatchProductHandler(patchProductUseCase)
+
+		patchProductHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("got success when calling restoring product handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/products/{id}/restore", nil)
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "3")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		restoreProductUseCase := new(MockRestoreProductUseCase)
+
+		restoreProductUseCase.On("Execute", req.Context(), uint(3)).
+			Return(nil)
+
+		restoreProductHandler := handler.RestoreProductHandler(restoreProductUseCase)
+
+		restoreProductHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+	})
+
+	t.Run("got error on RestoreProduct UseCase when calling restoring product handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/products/{id}/restore", nil)
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "3")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		restoreProductUseCase := new(MockRestoreProductUseCase)
+
+		restoreProductUseCase.On("Execute", req.Context(), uint(3)).
+			Return(&responses.BusinessResponse{
+				StatusCode: http.StatusConflict,
+			})
+
+		restoreProductHandler := handler.RestoreProductHandler(restoreProductUseCase)
+
+		restoreProductHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+	})
+
+	t.Run("got error on invalid id when calling restoring product handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/products/{id}/restore", nil)
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "s3")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		restoreProductUseCase := new(MockRestoreProductUseCase)
+
+		restoreProductHandler := handler.RestoreProductHandler(restoreProductUseCase)
+
+		restoreProductHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
 }
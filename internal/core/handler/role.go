@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
+	"github.com/thiagoluis88git/tech1-orders/pkg/httpserver"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+const bearerPrefix = "Bearer "
+
+// OrderRoleMiddleware puts the role of the staff token sent in the Authorization header
+// (kitchen, waiter, manager) in the request context so the order state machine can check
+// who is moving the order. roleTokens maps each token to its role and is set by the server,
+// the client can never choose its role. Requests without a token have no role and can not
+// move orders, an unknown token is rejected
+func OrderRoleMiddleware(roleTokens map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization := r.Header.Get("Authorization")
+
+			if authorization == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := strings.CutPrefix(authorization, bearerPrefix)
+			role := ""
+
+			for staffToken, staffRole := range roleTokens {
+				if subtle.ConstantTimeCompare([]byte(token), []byte(staffToken)) == 1 {
+					role = staffRole
+				}
+			}
+
+			if !ok || token == "" || !statemachine.IsStaffRole(role) {
+				httpserver.SendResponseError(w, &responses.BusinessResponse{
+					StatusCode: http.StatusUnauthorized,
+					Message:    "Invalid staff token",
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(statemachine.WithRole(r.Context(), role)))
+		})
+	}
+}
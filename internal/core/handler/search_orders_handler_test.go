@@ -0,0 +1,138 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestSearchOrdersHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when calling search orders handler with filters", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(
+			http.MethodGet,
+			"/api/orders?status=Entregue,Cancelado&status=Finalizado&from=2026-10-01&to=2026-10-16"+
+				"&cpf=12345678910&ticket=7&productId=3&sort=totalPrice&limit=50&cursor=abc",
+			nil,
+		)
+
+		recorder := httptest.NewRecorder()
+
+		searchOrdersUseCase := new(MockSearchOrdersUseCase)
+
+		nextCursor := "next"
+		ticket := 7
+		productId := uint(3)
+		cpf := "12345678910"
+		from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+		to := time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local)
+
+		searchOrdersUseCase.On("Execute", req.Context(), dto.OrderSearch{
+			Statuses:     []string{"Entregue", "Cancelado", "Finalizado"},
+			From:         &from,
+			To:           &to,
+			CPF:          &cpf,
+			TicketNumber: &ticket,
+			ProductID:    &productId,
+			SortBy:       dto.OrderSortTotalPrice,
+			Descending:   false,
+			Limit:        50,
+			Cursor:       "abc",
+		}).Return(dto.OrderSearchResponse{
+			Orders: []dto.OrderResponse{
+				{
+					OrderId: uint(12),
+				},
+			},
+			NextCursor: &nextCursor,
+		}, nil)
+
+		searchOrdersHandler := handler.SearchOrdersHandler(searchOrdersUseCase)
+
+		searchOrdersHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response dto.OrderSearchResponse
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(12), response.Orders[0].OrderId)
+		assert.Equal(t, "next", *response.NextCursor)
+	})
+
+	t.Run("got newest orders first when calling search orders handler without sort", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/orders?from=2026-10-01T10:00:00Z", nil)
+
+		recorder := httptest.NewRecorder()
+
+		searchOrdersUseCase := new(MockSearchOrdersUseCase)
+
+		searchOrdersUseCase.On("Execute", req.Context(), mock.MatchedBy(func(search dto.OrderSearch) bool {
+			return search.SortBy == dto.OrderSortOrderDate &&
+				search.Descending &&
+				search.From.Equal(time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)) &&
+				search.To == nil
+		})).Return(dto.OrderSearchResponse{Orders: []dto.OrderResponse{}}, nil)
+
+		searchOrdersHandler := handler.SearchOrdersHandler(searchOrdersUseCase)
+
+		searchOrdersHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		searchOrdersUseCase.AssertExpectations(t)
+	})
+
+	t.Run("got bad request when calling search orders handler with invalid query", func(t *testing.T) {
+		t.Parallel()
+
+		for _, query := range []string{"from=yesterday", "to=2026-13-01", "ticket=A1", "productId=-1", "limit=ten"} {
+			req := httptest.NewRequest(http.MethodGet, "/api/orders?"+query, nil)
+
+			recorder := httptest.NewRecorder()
+
+			searchOrdersUseCase := new(MockSearchOrdersUseCase)
+
+			searchOrdersHandler := handler.SearchOrdersHandler(searchOrdersUseCase)
+
+			searchOrdersHandler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+			searchOrdersUseCase.AssertNotCalled(t, "Execute")
+		}
+	})
+
+	t.Run("got error on SearchOrders Use Case when calling search orders handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/orders?sort=ticketNumber", nil)
+
+		recorder := httptest.NewRecorder()
+
+		searchOrdersUseCase := new(MockSearchOrdersUseCase)
+
+		searchOrdersUseCase.On("Execute", req.Context(), mock.Anything).
+			Return(dto.OrderSearchResponse{}, &responses.BusinessResponse{
+				StatusCode: http.StatusBadRequest,
+			})
+
+		searchOrdersHandler := handler.SearchOrdersHandler(searchOrdersUseCase)
+
+		searchOrdersHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
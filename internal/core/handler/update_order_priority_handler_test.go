@@ -0,0 +1,137 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestUpdateOrderPriorityHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when calling update order priority handler", func(t *testing.T) {
+		t.Parallel()
+
+		form := dto.OrderPriorityForm{
+			Priority: 2,
+		}
+
+		jsonData, err := json.Marshal(form)
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/priority", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		updateOrderPriority := new(MockUpdateOrderPriorityUseCase)
+
+		updateOrderPriority.On("Execute", req.Context(), uint(12), form).Return(nil)
+
+		updateOrderPriorityHandler := handler.UpdateOrderPriorityHandler(updateOrderPriority)
+
+		updateOrderPriorityHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+	})
+
+	t.Run("got error on UseCase when calling update order priority handler", func(t *testing.T) {
+		t.Parallel()
+
+		form := dto.OrderPriorityForm{
+			Priority: 1,
+		}
+
+		jsonData, err := json.Marshal(form)
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/priority", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		updateOrderPriority := new(MockUpdateOrderPriorityUseCase)
+
+		updateOrderPriority.On("Execute", req.Context(), uint(12), form).Return(&responses.BusinessResponse{
+			StatusCode: 403,
+		})
+
+		updateOrderPriorityHandler := handler.UpdateOrderPriorityHandler(updateOrderPriority)
+
+		updateOrderPriorityHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("got error on negative priority when calling update order priority handler", func(t *testing.T) {
+		t.Parallel()
+
+		jsonData, err := json.Marshal(dto.OrderPriorityForm{
+			Priority: -1,
+		})
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/priority", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		updateOrderPriority := new(MockUpdateOrderPriorityUseCase)
+
+		updateOrderPriorityHandler := handler.UpdateOrderPriorityHandler(updateOrderPriority)
+
+		updateOrderPriorityHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		updateOrderPriority.AssertNotCalled(t, "Execute")
+	})
+
+	t.Run("got error on invalid id when calling update order priority handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/priority", nil)
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "x12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		updateOrderPriority := new(MockUpdateOrderPriorityUseCase)
+
+		updateOrderPriorityHandler := handler.UpdateOrderPriorityHandler(updateOrderPriority)
+
+		updateOrderPriorityHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
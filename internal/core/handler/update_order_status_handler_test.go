@@ -0,0 +1,208 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/statemachine"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/usecases"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/handler"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+func TestUpdateOrderStatusHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when calling update order status handler", func(t *testing.T) {
+		t.Parallel()
+
+		jsonData, err := json.Marshal(dto.OrderStatusForm{
+			Status: "Preparando",
+		})
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/status", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		updateOrderStatus := new(MockUpdateOrderStatusUseCase)
+
+		updateOrderStatus.On("Execute", req.Context(), uint(12), "Preparando").Return(nil)
+
+		updateOrderStatusHandler := handler.UpdateOrderStatusHandler(updateOrderStatus)
+
+		updateOrderStatusHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+	})
+
+	t.Run("got error on UpdateOrderStatus UseCase when calling update order status handler", func(t *testing.T) {
+		t.Parallel()
+
+		jsonData, err := json.Marshal(dto.OrderStatusForm{
+			Status: "Finalizado",
+		})
+
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/status", bytes.NewBuffer(jsonData))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		updateOrderStatus := new(MockUpdateOrderStatusUseCase)
+
+		updateOrderStatus.On("Execute", req.Context(), uint(12), "Finalizado").Return(&responses.BusinessResponse{
+			StatusCode: 428,
+		})
+
+		updateOrderStatusHandler := handler.UpdateOrderStatusHandler(updateOrderStatus)
+
+		updateOrderStatusHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+	})
+
+	t.Run("got error on missing status when calling update order status handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/status", bytes.NewBuffer([]byte("{}")))
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		updateOrderStatus := new(MockUpdateOrderStatusUseCase)
+
+		updateOrderStatusHandler := handler.UpdateOrderStatusHandler(updateOrderStatus)
+
+		updateOrderStatusHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("got error on invalid id when calling update order status handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/status", nil)
+		req.Header.Add("Content-Type", "application/json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "x12")
+
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		recorder := httptest.NewRecorder()
+
+		updateOrderStatus := new(MockUpdateOrderStatusUseCase)
+
+		updateOrderStatusHandler := handler.UpdateOrderStatusHandler(updateOrderStatus)
+
+		updateOrderStatusHandler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	staffRoleTokens := map[string]string{"KitchenToken": statemachine.RoleKitchen}
+
+	t.Run("got role in context when calling order role middleware with a staff token", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/status", nil)
+		req.Header.Add("Authorization", "Bearer KitchenToken")
+
+		recorder := httptest.NewRecorder()
+
+		var role string
+
+		middleware := handler.OrderRoleMiddleware(staffRoleTokens)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role = statemachine.RoleFromContext(r.Context())
+		}))
+
+		middleware.ServeHTTP(recorder, req)
+
+		assert.Equal(t, statemachine.RoleKitchen, role)
+	})
+
+	t.Run("got no role in context when calling order role middleware with a role header", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/status", nil)
+		req.Header.Add("X-Role", statemachine.RoleSystem)
+
+		recorder := httptest.NewRecorder()
+
+		role := "unset"
+
+		middleware := handler.OrderRoleMiddleware(staffRoleTokens)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role = statemachine.RoleFromContext(r.Context())
+		}))
+
+		middleware.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "", role)
+	})
+
+	t.Run("got unauthorized when calling order role middleware with an unknown token", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/status", nil)
+		req.Header.Add("Authorization", "Bearer system")
+
+		recorder := httptest.NewRecorder()
+
+		called := false
+
+		middleware := handler.OrderRoleMiddleware(staffRoleTokens)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+
+		middleware.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, false, called)
+	})
+
+	t.Run("got If-Match in context when calling order If-Match middleware", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPut, "/api/orders/{id}/status", nil)
+		req.Header.Add("If-Match", "\"1720614600123456\"")
+
+		recorder := httptest.NewRecorder()
+
+		var ifMatch string
+
+		middleware := handler.OrderIfMatchMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ifMatch = usecases.IfMatchFromContext(r.Context())
+		}))
+
+		middleware.ServeHTTP(recorder, req)
+
+		assert.Equal(t, "\"1720614600123456\"", ifMatch)
+	})
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/usecases"
+	"github.com/thiagoluis88git/tech1-orders/pkg/httpserver"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+const (
+	PaymentSignatureHeader = "X-Signature"
+
+	signaturePrefix = "sha256="
+	maxWebhookBody  = 1048576
+)
+
+// @Summary Payment gateway webhook
+// @Description Receive a payment notification from the payment gateway.
+// @Description The X-Signature header must be the hex HMAC-SHA256 of the body, signed with the webhook secret.
+// @Description Approved payments move the order to Criado and rejected ones mark the payment as Recusado.
+// @Description Replayed notifications are accepted without changing the order
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param X-Signature header string true "sha256=<hex HMAC of the body>"
+// @Param notification body dto.PaymentNotification true "notification"
+// @Success 204
+// @Failure 401 "Invalid signature"
+// @Failure 404 "Order not found"
+// @Failure 422 "Unknown payment status"
+// @Router /api/webhooks/payments [post]
+func PaymentWebhookHandler(secret string, processPaymentNotification usecases.ProcessPaymentNotificationUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+
+		if err != nil {
+			log.Print("reading payment webhook body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		if !validSignature(secret, body, r.Header.Get(PaymentSignatureHeader)) {
+			err = &responses.BusinessResponse{
+				StatusCode: http.StatusUnauthorized,
+				Message:    "Invalid payment notification signature",
+			}
+			log.Print("payment webhook signature", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var notification dto.PaymentNotification
+
+		err = httpserver.DecodeJSONBody(w, r, &notification)
+
+		if err != nil {
+			log.Print("decoding payment notification body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		err = processPaymentNotification.Execute(r.Context(), notification, getTicketDate())
+
+		if err != nil {
+			log.Print("process payment notification", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
+
+// SignPayload returns the signature the payment gateway sends for the body
+func SignPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// validSignature compares in constant time. An empty secret rejects every notification
+func validSignature(secret string, body []byte, signature string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		signature = signaturePrefix + signature
+	}
+
+	return hmac.Equal([]byte(SignPayload(secret, body)), []byte(signature))
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultCategories are the categories of the menu before they were kept in the database
+var defaultCategories = []model.Category{
+	{Name: model.CategoryCombo, DisplayOrder: 1, Active: true, System: true},
+	{Name: model.CategorySnack, DisplayOrder: 2, Active: true, AllowedInCombo: true},
+	{Name: model.CategoryBeverage, DisplayOrder: 3, Active: true, AllowedInCombo: true},
+	{Name: model.CategoryToppings, DisplayOrder: 4, Active: true, AllowedInCombo: true, System: true},
+	{Name: model.CategoryDesert, DisplayOrder: 5, Active: true, AllowedInCombo: true},
+}
+
+// MigrateCategories creates the categories table with the default categories and moves
+// the products from the old category name column to the category foreign key.
+// The defaults are only seeded in an empty table, so a category deleted or renamed by
+// the admin does not come back on the next start.
+// Names that were not one of the menu categories are created inactive, since those
+// products were never shown, so the admin can review them before they show up.
+// It must run before the products table is migrated
+func MigrateCategories(db *gorm.DB) error {
+	hasAllowedInCombo := db.Migrator().HasColumn(&model.Category{}, "AllowedInCombo")
+	hasSystem := db.Migrator().HasColumn(&model.Category{}, "System")
+
+	err := db.AutoMigrate(&model.Category{})
+
+	if err != nil {
+		return err
+	}
+
+	// categories created before the flag existed keep the combos they were allowed in
+	if !hasAllowedInCombo {
+		err = db.Model(&model.Category{}).
+			Where("name IN ?", comboCategoryNames()).
+			Update("allowed_in_combo", true).
+			Error
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// the categories looked up by name before the flag existed can not be renamed or deleted anymore
+	if !hasSystem {
+		err = db.Model(&model.Category{}).
+			Where("name IN ?", systemCategoryNames()).
+			Update("system", true).
+			Error
+
+		if err != nil {
+			return err
+		}
+	}
+
+	var count int64
+
+	err = db.Unscoped().Model(&model.Category{}).Count(&count).Error
+
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		// Create fills the IDs, so the defaults are copied to be seeded in another database
+		categories := append([]model.Category{}, defaultCategories...)
+
+		err = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&categories).Error
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if !db.Migrator().HasTable("products") || !db.Migrator().HasColumn("products", "category") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			`INSERT INTO categories (name, display_order, active, image_url, created_at, updated_at)
+			SELECT missing.category,
+				(SELECT COALESCE(MAX(display_order), 0) FROM categories) + ROW_NUMBER() OVER (ORDER BY missing.category),
+				false, '', NOW(), NOW()
+			FROM (
+				SELECT DISTINCT category FROM products
+				WHERE category IS NOT NULL AND category NOT IN (SELECT name FROM categories)
+			) AS missing`,
+			"ALTER TABLE products ADD COLUMN IF NOT EXISTS category_id bigint",
+			"UPDATE products SET category_id = categories.id FROM categories WHERE categories.name = products.category",
+			"ALTER TABLE products DROP COLUMN category",
+		}
+
+		for _, statement := range statements {
+			err := tx.Exec(statement).Error
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func systemCategoryNames() []string {
+	names := []string{}
+
+	for _, category := range defaultCategories {
+		if category.System {
+			names = append(names, category.Name)
+		}
+	}
+
+	return names
+}
+
+func comboCategoryNames() []string {
+	names := []string{}
+
+	for _, category := range defaultCategories {
+		if category.AllowedInCombo {
+			names = append(names, category.Name)
+		}
+	}
+
+	return names
+}
@@ -1,11 +1,30 @@
 package database
 
 import (
+	"fmt"
+	"log"
+
 	"github.com/thiagoluis88git/tech1-orders/internal/core/data/model"
 
 	"gorm.io/gorm"
 )
 
+type orderSearchIndex struct {
+	name       string
+	definition string
+}
+
+// orderSearchIndexes keep the order search fast on big tables. They use the gorm.Model
+// columns, so they can not be declared in the model tags. CONCURRENTLY does not lock
+// the orders table while an existing database is indexed
+var orderSearchIndexes = []orderSearchIndex{
+	{name: "idx_orders_created_at_id", definition: "orders (created_at, id) WHERE deleted_at IS NULL"},
+	{name: "idx_orders_updated_at_id", definition: "orders (updated_at, id) WHERE deleted_at IS NULL"},
+	{name: "idx_orders_total_price_id", definition: "orders (total_price, id) WHERE deleted_at IS NULL"},
+	{name: "idx_orders_status_created_at_id", definition: "orders (order_status, created_at, id) WHERE deleted_at IS NULL"},
+	{name: "idx_order_products_product_id_order_id", definition: "order_products (product_id, order_id) WHERE deleted_at IS NULL"},
+}
+
 type Database struct {
 	Connection *gorm.DB
 }
@@ -17,16 +36,74 @@ func ConfigDatabase(dialector gorm.Dialector) (*Database, error) {
 		return &Database{}, err
 	}
 
+	// the products can not be migrated while they still point to the old category column
+	err = MigrateCategories(db)
+
+	if err != nil {
+		return &Database{}, err
+	}
+
 	db.AutoMigrate(
+		&model.Category{},
 		&model.Order{},
 		&model.OrderProduct{},
+		&model.OrderProductAddOn{},
+		&model.OrderProductComboItem{},
 		&model.Product{},
 		&model.ProductImage{},
 		&model.ComboProduct{},
+		&model.ComboSlot{},
+		&model.ComboSlotOption{},
 		&model.OrderTicketNumber{},
+		&model.Refund{},
+		&model.OrderStatusEvent{},
+		&model.OutboxEvent{},
 	)
 
+	err = createOrderSearchIndexes(db)
+
+	if err != nil {
+		return &Database{}, err
+	}
+
 	return &Database{
 		Connection: db,
 	}, nil
 }
+
+// createOrderSearchIndexes builds the missing indexes. A CONCURRENTLY build that fails leaves
+// an INVALID index behind, which IF NOT EXISTS would keep forever, so it is dropped and built again
+func createOrderSearchIndexes(db *gorm.DB) error {
+	for _, index := range orderSearchIndexes {
+		var invalid bool
+
+		err := db.Raw(`SELECT EXISTS (
+			SELECT 1 FROM pg_index JOIN pg_class ON pg_class.oid = pg_index.indexrelid
+			WHERE pg_class.relname = ? AND NOT pg_index.indisvalid
+		)`, index.name).Scan(&invalid).Error
+
+		if err != nil {
+			return err
+		}
+
+		if invalid {
+			log.Print("dropping invalid index", map[string]interface{}{
+				"index": index.name,
+			})
+
+			err = db.Exec(fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %v", index.name)).Error
+
+			if err != nil {
+				return err
+			}
+		}
+
+		err = db.Exec(fmt.Sprintf("CREATE INDEX CONCURRENTLY IF NOT EXISTS %v ON %v", index.name, index.definition)).Error
+
+		if err != nil {
+			return fmt.Errorf("creating index %v: %w", index.name, err)
+		}
+	}
+
+	return nil
+}
@@ -20,13 +20,17 @@ func setup() {
 	os.Setenv(environment.DBName, "Name")
 	os.Setenv(environment.Region, "Region")
 	os.Setenv(environment.CustomerRootAPI, "CustomerRootAPI")
+	os.Setenv(environment.WebhookSecret, "WebhookSecret")
+	os.Setenv(environment.PixKey, "PixKey")
+	os.Setenv(environment.PixMerchantName, "PixMerchantName")
+	os.Setenv(environment.PixMerchantCity, "PixMerchantCity")
 }
 
 func TestDatabaseConfig(t *testing.T) {
 	t.Parallel()
 	setup()
 
-	t.Run("got success when starting config database", func(t *testing.T) {
+	t.Run("got error when migrating categories in config database", func(t *testing.T) {
 		environment.LoadEnvironmentVariables()
 
 		conn, _, err := sqlmock.New()
@@ -41,8 +45,8 @@ func TestDatabaseConfig(t *testing.T) {
 
 		config, err := database.ConfigDatabase(dialector)
 
-		assert.NoError(t, err)
-		assert.NotEmpty(t, config)
+		assert.Error(t, err)
+		assert.Empty(t, config)
 	})
 
 	t.Run("got error when starting config database", func(t *testing.T) {
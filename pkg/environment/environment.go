@@ -4,7 +4,10 @@ import (
 	"flag"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -18,23 +21,76 @@ var (
 )
 
 const (
-	DBHost          = "DB_HOST"
-	DBUser          = "POSTGRES_USER"
-	DBPassword      = "POSTGRES_PASSWORD"
-	DBPort          = "DB_PORT"
-	DBName          = "POSTGRES_DB"
-	Region          = "AWS_REGION"
-	CustomerRootAPI = "CUSTOMER_ROOT_API"
+	DBHost           = "DB_HOST"
+	DBUser           = "POSTGRES_USER"
+	DBPassword       = "POSTGRES_PASSWORD"
+	DBPort           = "DB_PORT"
+	DBName           = "POSTGRES_DB"
+	Region           = "AWS_REGION"
+	CustomerRootAPI  = "CUSTOMER_ROOT_API"
+	WebhookSecret    = "PAYMENT_WEBHOOK_SECRET"
+	PixKey           = "PIX_KEY"
+	PixMerchantName  = "PIX_MERCHANT_NAME"
+	PixMerchantCity  = "PIX_MERCHANT_CITY"
+	QRCodeExpiration = "QR_CODE_EXPIRATION"
+	OutboxWebhookURL = "OUTBOX_WEBHOOK_URL"
+	OutboxSecret     = "OUTBOX_WEBHOOK_SECRET"
+	StaffRoleTokens  = "STAFF_ROLE_TOKENS"
+
+	CustomerCacheEnabled     = "CUSTOMER_CACHE_ENABLED"
+	CustomerCacheSize        = "CUSTOMER_CACHE_SIZE"
+	CustomerCacheTTL         = "CUSTOMER_CACHE_TTL"
+	CustomerCacheNegativeTTL = "CUSTOMER_CACHE_NEGATIVE_TTL"
+
+	HTTPClientCAFile   = "HTTP_CLIENT_CA_FILE"
+	HTTPClientCertFile = "HTTP_CLIENT_CERT_FILE"
+	HTTPClientKeyFile  = "HTTP_CLIENT_KEY_FILE"
+	HTTPClientInsecure = "HTTP_CLIENT_INSECURE"
+)
+
+// Payment windows shorter than this are too short for the customer to pay with the bank app
+const defaultQRCodeExpiration = 15 * time.Minute
+
+// The PIX payload needs a merchant name and city even before the store sets its own
+const (
+	defaultPixMerchantName = "Fast Food"
+	defaultPixMerchantCity = "Sao Paulo"
+)
+
+// The customer cache defaults keep a customer for a few minutes. The CPFs not found are
+// kept for less time, so a customer that just signed up is seen soon
+const (
+	defaultCustomerCacheSize        = 10000
+	defaultCustomerCacheTTL         = 5 * time.Minute
+	defaultCustomerCacheNegativeTTL = 30 * time.Second
 )
 
 type Environment struct {
-	dbHost          string
-	dbPort          string
-	dbName          string
-	dbUser          string
-	dbPassword      string
-	region          string
-	customerRootAPI string
+	dbHost           string
+	dbPort           string
+	dbName           string
+	dbUser           string
+	dbPassword       string
+	region           string
+	customerRootAPI  string
+	webhookSecret    string
+	pixKey           string
+	pixMerchantName  string
+	pixMerchantCity  string
+	qrCodeExpiration time.Duration
+	outboxWebhookURL string
+	outboxSecret     string
+	staffRoleTokens  map[string]string
+
+	customerCacheEnabled     bool
+	customerCacheSize        int
+	customerCacheTTL         time.Duration
+	customerCacheNegativeTTL time.Duration
+
+	httpClientCAFile   string
+	httpClientCertFile string
+	httpClientKeyFile  string
+	httpClientInsecure bool
 }
 
 func LoadEnvironmentVariables() {
@@ -55,18 +111,57 @@ func LoadEnvironmentVariables() {
 	dbName := getEnvironmentVariable(DBName)
 	region := getEnvironmentVariable(Region)
 	customerRootAPI := getEnvironmentVariable(CustomerRootAPI)
+	webhookSecret := getOptionalEnvironmentVariable(WebhookSecret)
+	pixKey := getOptionalEnvironmentVariable(PixKey)
+	pixMerchantName := getStringEnvironmentVariable(PixMerchantName, defaultPixMerchantName)
+	pixMerchantCity := getStringEnvironmentVariable(PixMerchantCity, defaultPixMerchantCity)
+	qrCodeExpiration := getDurationEnvironmentVariable(QRCodeExpiration, defaultQRCodeExpiration)
+	outboxWebhookURL := getOptionalEnvironmentVariable(OutboxWebhookURL)
+	outboxSecret := getOptionalEnvironmentVariable(OutboxSecret)
+	staffRoleTokens := getRoleTokensEnvironmentVariable(StaffRoleTokens)
+	customerCacheEnabled := getBoolEnvironmentVariable(CustomerCacheEnabled, false)
+	customerCacheSize := getIntEnvironmentVariable(CustomerCacheSize, defaultCustomerCacheSize)
+	customerCacheTTL := getDurationEnvironmentVariable(CustomerCacheTTL, defaultCustomerCacheTTL)
+	customerCacheNegativeTTL := getDurationEnvironmentVariable(CustomerCacheNegativeTTL, defaultCustomerCacheNegativeTTL)
+	httpClientCAFile := getOptionalEnvironmentVariable(HTTPClientCAFile)
+	httpClientCertFile := getOptionalEnvironmentVariable(HTTPClientCertFile)
+	httpClientKeyFile := getOptionalEnvironmentVariable(HTTPClientKeyFile)
+	httpClientInsecure := getBoolEnvironmentVariable(HTTPClientInsecure, false)
+
+	// skipping the TLS verification would expose the customers CPFs outside local development
+	if httpClientInsecure && *localDev == "false" {
+		log.Fatalf("%v is only allowed with the localDev flag", HTTPClientInsecure)
+	}
 
 	once := &sync.Once{}
 
 	once.Do(func() {
 		singleton = &Environment{
-			dbHost:          dbHost,
-			dbPort:          dbPort,
-			dbUser:          dbUser,
-			dbPassword:      dbPassword,
-			dbName:          dbName,
-			region:          region,
-			customerRootAPI: customerRootAPI,
+			dbHost:           dbHost,
+			dbPort:           dbPort,
+			dbUser:           dbUser,
+			dbPassword:       dbPassword,
+			dbName:           dbName,
+			region:           region,
+			customerRootAPI:  customerRootAPI,
+			webhookSecret:    webhookSecret,
+			pixKey:           pixKey,
+			pixMerchantName:  pixMerchantName,
+			pixMerchantCity:  pixMerchantCity,
+			qrCodeExpiration: qrCodeExpiration,
+			outboxWebhookURL: outboxWebhookURL,
+			outboxSecret:     outboxSecret,
+			staffRoleTokens:  staffRoleTokens,
+
+			customerCacheEnabled:     customerCacheEnabled,
+			customerCacheSize:        customerCacheSize,
+			customerCacheTTL:         customerCacheTTL,
+			customerCacheNegativeTTL: customerCacheNegativeTTL,
+
+			httpClientCAFile:   httpClientCAFile,
+			httpClientCertFile: httpClientCertFile,
+			httpClientKeyFile:  httpClientKeyFile,
+			httpClientInsecure: httpClientInsecure,
 		}
 	})
 }
@@ -81,6 +176,98 @@ func getEnvironmentVariable(key string) string {
 	return value
 }
 
+// getOptionalEnvironmentVariable returns an empty string when the variable is not set
+func getOptionalEnvironmentVariable(key string) string {
+	value, _ := os.LookupEnv(key)
+
+	return value
+}
+
+func getStringEnvironmentVariable(key string, defaultValue string) string {
+	value, hasKey := os.LookupEnv(key)
+
+	if !hasKey || value == "" {
+		return defaultValue
+	}
+
+	return value
+}
+
+// getRoleTokensEnvironmentVariable reads a comma separated list of role:token pairs,
+// like kitchen:abc,manager:def, and returns the role of each token
+func getRoleTokensEnvironmentVariable(key string) map[string]string {
+	roleTokens := map[string]string{}
+	value, hasKey := os.LookupEnv(key)
+
+	if !hasKey || value == "" {
+		return roleTokens
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		role, token, ok := strings.Cut(strings.TrimSpace(pair), ":")
+
+		if !ok || role == "" || token == "" {
+			log.Fatalf("The %v environment variable must be a list of role:token pairs", key)
+		}
+
+		if _, ok := roleTokens[token]; ok {
+			log.Fatalf("The %v environment variable has the same token for more than one role", key)
+		}
+
+		roleTokens[token] = role
+	}
+
+	return roleTokens
+}
+
+func getDurationEnvironmentVariable(key string, defaultValue time.Duration) time.Duration {
+	value, hasKey := os.LookupEnv(key)
+
+	if !hasKey {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+
+	if err != nil {
+		log.Fatalf("The %v environment variable is not a valid duration: %v", key, err.Error())
+	}
+
+	return duration
+}
+
+func getBoolEnvironmentVariable(key string, defaultValue bool) bool {
+	value, hasKey := os.LookupEnv(key)
+
+	if !hasKey {
+		return defaultValue
+	}
+
+	enabled, err := strconv.ParseBool(value)
+
+	if err != nil {
+		log.Fatalf("The %v environment variable is not a valid boolean: %v", key, err.Error())
+	}
+
+	return enabled
+}
+
+func getIntEnvironmentVariable(key string, defaultValue int) int {
+	value, hasKey := os.LookupEnv(key)
+
+	if !hasKey {
+		return defaultValue
+	}
+
+	number, err := strconv.Atoi(value)
+
+	if err != nil {
+		log.Fatalf("The %v environment variable is not a valid number: %v", key, err.Error())
+	}
+
+	return number
+}
+
 func GetDBHost() string {
 	return singleton.dbHost
 }
@@ -108,3 +295,68 @@ func GetRegion() string {
 func GetCustomerRootAPI() string {
 	return singleton.customerRootAPI
 }
+
+func GetWebhookSecret() string {
+	return singleton.webhookSecret
+}
+
+func GetPixKey() string {
+	return singleton.pixKey
+}
+
+func GetPixMerchantName() string {
+	return singleton.pixMerchantName
+}
+
+func GetPixMerchantCity() string {
+	return singleton.pixMerchantCity
+}
+
+func GetQRCodeExpiration() time.Duration {
+	return singleton.qrCodeExpiration
+}
+
+func GetOutboxWebhookURL() string {
+	return singleton.outboxWebhookURL
+}
+
+func GetOutboxWebhookSecret() string {
+	return singleton.outboxSecret
+}
+
+// GetStaffRoleTokens returns the role of each staff token
+func GetStaffRoleTokens() map[string]string {
+	return singleton.staffRoleTokens
+}
+
+func IsCustomerCacheEnabled() bool {
+	return singleton.customerCacheEnabled
+}
+
+func GetCustomerCacheSize() int {
+	return singleton.customerCacheSize
+}
+
+func GetCustomerCacheTTL() time.Duration {
+	return singleton.customerCacheTTL
+}
+
+func GetCustomerCacheNegativeTTL() time.Duration {
+	return singleton.customerCacheNegativeTTL
+}
+
+func GetHTTPClientCAFile() string {
+	return singleton.httpClientCAFile
+}
+
+func GetHTTPClientCertFile() string {
+	return singleton.httpClientCertFile
+}
+
+func GetHTTPClientKeyFile() string {
+	return singleton.httpClientKeyFile
+}
+
+func IsHTTPClientInsecure() bool {
+	return singleton.httpClientInsecure
+}
@@ -3,6 +3,7 @@ package environment_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/thiagoluis88git/tech1-orders/pkg/environment"
@@ -16,6 +17,17 @@ func setup() {
 	os.Setenv(environment.DBUser, "DBUser")
 	os.Setenv(environment.Region, "Region")
 	os.Setenv(environment.CustomerRootAPI, "CustomerRootAPI")
+	os.Setenv(environment.WebhookSecret, "WebhookSecret")
+	os.Setenv(environment.PixKey, "PixKey")
+	os.Setenv(environment.PixMerchantName, "PixMerchantName")
+	os.Setenv(environment.PixMerchantCity, "PixMerchantCity")
+	os.Setenv(environment.QRCodeExpiration, "10m")
+	os.Setenv(environment.OutboxWebhookURL, "OutboxWebhookURL")
+	os.Setenv(environment.StaffRoleTokens, "kitchen:KitchenToken, manager:ManagerToken")
+	os.Setenv(environment.CustomerCacheEnabled, "true")
+	os.Setenv(environment.CustomerCacheSize, "500")
+	os.Setenv(environment.CustomerCacheTTL, "1m")
+	os.Setenv(environment.HTTPClientCAFile, "HTTPClientCAFile")
 }
 
 func TestEnvironment(t *testing.T) {
@@ -36,5 +48,57 @@ func TestEnvironment(t *testing.T) {
 		assert.Equal(t, "DBUser", environment.GetDBUser())
 		assert.Equal(t, "Region", environment.GetRegion())
 		assert.Equal(t, "CustomerRootAPI", environment.GetCustomerRootAPI())
+		assert.Equal(t, "WebhookSecret", environment.GetWebhookSecret())
+		assert.Equal(t, "PixKey", environment.GetPixKey())
+		assert.Equal(t, "PixMerchantName", environment.GetPixMerchantName())
+		assert.Equal(t, "PixMerchantCity", environment.GetPixMerchantCity())
+		assert.Equal(t, 10*time.Minute, environment.GetQRCodeExpiration())
+		assert.Equal(t, "OutboxWebhookURL", environment.GetOutboxWebhookURL())
+		assert.Empty(t, environment.GetOutboxWebhookSecret())
+		assert.Equal(t, map[string]string{
+			"KitchenToken": "kitchen",
+			"ManagerToken": "manager",
+		}, environment.GetStaffRoleTokens())
+		assert.True(t, environment.IsCustomerCacheEnabled())
+		assert.Equal(t, 500, environment.GetCustomerCacheSize())
+		assert.Equal(t, time.Minute, environment.GetCustomerCacheTTL())
+		assert.Equal(t, 30*time.Second, environment.GetCustomerCacheNegativeTTL())
+		assert.Equal(t, "HTTPClientCAFile", environment.GetHTTPClientCAFile())
+		assert.Empty(t, environment.GetHTTPClientCertFile())
+		assert.Empty(t, environment.GetHTTPClientKeyFile())
+		assert.False(t, environment.IsHTTPClientInsecure())
+	})
+}
+
+func TestEnvironmentDefaults(t *testing.T) {
+	for _, key := range []string{
+		environment.DBHost,
+		environment.DBPassword,
+		environment.DBName,
+		environment.DBPort,
+		environment.DBUser,
+		environment.Region,
+		environment.CustomerRootAPI,
+	} {
+		t.Setenv(key, key)
+	}
+
+	for _, key := range []string{
+		environment.WebhookSecret,
+		environment.PixKey,
+		environment.PixMerchantName,
+		environment.PixMerchantCity,
+	} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+
+	t.Run("got success when loading environment without payment variables", func(t *testing.T) {
+		environment.LoadEnvironmentVariables()
+
+		assert.Empty(t, environment.GetWebhookSecret())
+		assert.Empty(t, environment.GetPixKey())
+		assert.Equal(t, "Fast Food", environment.GetPixMerchantName())
+		assert.Equal(t, "Sao Paulo", environment.GetPixMerchantCity())
 	})
 }
@@ -1,79 +1,209 @@
 package httpserver
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"time"
 
 	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
 )
 
-func NewHTTPClient() *http.Client {
+// DefaultMaxResponseSize is the largest response body read by the request helpers
+// when WithMaxResponseSize is not used
+const DefaultMaxResponseSize int64 = 10 << 20
+
+func NewHTTPTransport(tlsConfig *tls.Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return transport
+}
+
+// NewHTTPClient has no overall timeout. The calls are bounded by the attempt timeout
+// of the ResilientTransport and by the deadline of the request context
+func NewHTTPClient(transport http.RoundTripper) *http.Client {
 	client := http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		},
-		Timeout: 20 * time.Second,
+		Transport: transport,
 	}
 
 	return &client
 }
 
+type requestOptions struct {
+	headers         http.Header
+	maxResponseSize int64
+}
+
+type RequestOption func(options *requestOptions)
+
+func WithHeader(key, value string) RequestOption {
+	return func(options *requestOptions) {
+		options.headers.Set(key, value)
+	}
+}
+
+func WithBearerToken(token string) RequestOption {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithMaxResponseSize limits the response body read. Larger bodies are answered
+// with a 422 NetworkError instead of being read into memory
+func WithMaxResponseSize(size int64) RequestOption {
+	return func(options *requestOptions) {
+		options.maxResponseSize = size
+	}
+}
+
 func DoGetRequest[T any](
 	ctx context.Context,
 	client *http.Client,
 	endpoint string,
 	token *string,
 	dataResponse T,
+	options ...RequestOption,
 ) (T, error) {
-	var empty T
+	if token != nil {
+		options = append([]RequestOption{WithHeader("Authorization", *token)}, options...)
+	}
+
+	return doJSONRequest(ctx, client, http.MethodGet, endpoint, nil, dataResponse, options)
+}
+
+// DoPostRequest sends the body as JSON. A nil body sends no body at all
+func DoPostRequest[T any](
+	ctx context.Context,
+	client *http.Client,
+	endpoint string,
+	body any,
+	dataResponse T,
+	options ...RequestOption,
+) (T, error) {
+	return doJSONRequest(ctx, client, http.MethodPost, endpoint, body, dataResponse, options)
+}
+
+// DoPutRequest sends the body as JSON. A nil body sends no body at all
+func DoPutRequest[T any](
+	ctx context.Context,
+	client *http.Client,
+	endpoint string,
+	body any,
+	dataResponse T,
+	options ...RequestOption,
+) (T, error) {
+	return doJSONRequest(ctx, client, http.MethodPut, endpoint, body, dataResponse, options)
+}
+
+func DoDeleteRequest[T any](
+	ctx context.Context,
+	client *http.Client,
+	endpoint string,
+	dataResponse T,
+	options ...RequestOption,
+) (T, error) {
+	return doJSONRequest(ctx, client, http.MethodDelete, endpoint, nil, dataResponse, options)
+}
+
+// DoStreamRequest sends the body as it is read and gives back the response body without
+// reading it, for payloads too large to keep in memory. The caller must close it.
+// Error responses are read, up to the max response size, into the NetworkError.
+// No Content-Type is set, it is sent with WithHeader. The attempt timeout of the
+// ResilientTransport does not apply, the stream is bounded by the deadline of ctx
+func DoStreamRequest(
+	ctx context.Context,
+	client *http.Client,
+	method string,
+	endpoint string,
+	body io.Reader,
+	options ...RequestOption,
+) (io.ReadCloser, error) {
+	requestOptions := newRequestOptions(options)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	response, err := doRequest(withoutAttemptTimeout(ctx), client, method, endpoint, body, requestOptions)
 
 	if err != nil {
-		return empty, &responses.NetworkError{
-			Code:    http.StatusInternalServerError,
-			Message: err.Error(),
+		return nil, err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		defer response.Body.Close()
+
+		responseBody, err := readBody(response.Body, requestOptions.maxResponseSize)
+
+		if err != nil {
+			return nil, err
 		}
+
+		return nil, responses.IsNetworkResponseOk(response, string(responseBody))
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	return response.Body, nil
+}
 
-	if token != nil {
-		req.Header.Set("Authorization", *token)
+func doJSONRequest[T any](
+	ctx context.Context,
+	client *http.Client,
+	method string,
+	endpoint string,
+	body any,
+	dataResponse T,
+	options []RequestOption,
+) (T, error) {
+	var empty T
+
+	// the given options come last, so they can replace the JSON headers
+	requestOptions := newRequestOptions(append([]RequestOption{
+		WithHeader("Content-Type", "application/json"),
+		WithHeader("Accept", "application/json"),
+	}, options...))
+
+	var reader io.Reader
+
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+
+		if err != nil {
+			return empty, &responses.NetworkError{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+
+		// a bytes.Reader lets the request be sent again on a retry
+		reader = bytes.NewReader(jsonBody)
 	}
 
-	response, err := client.Do(req)
+	response, err := doRequest(ctx, client, method, endpoint, reader, requestOptions)
 
 	if err != nil {
-		return empty, responses.GetNetworkError(err.(*url.Error))
+		return empty, err
 	}
 
 	defer response.Body.Close()
 
-	body, err := io.ReadAll(response.Body)
+	responseBody, err := readBody(response.Body, requestOptions.maxResponseSize)
 
 	if err != nil {
-		return empty, &responses.NetworkError{
-			Code:    http.StatusUnprocessableEntity,
-			Message: err.Error(),
-		}
+		return empty, err
 	}
 
-	bodyMessage := string(body)
+	bodyMessage := string(responseBody)
 	err = responses.IsNetworkResponseOk(response, bodyMessage)
 
 	if err != nil {
 		return empty, err
 	}
 
-	err = json.Unmarshal(body, &dataResponse)
+	if response.StatusCode == http.StatusNoContent {
+		return dataResponse, nil
+	}
+
+	err = json.Unmarshal(responseBody, &dataResponse)
 
 	if err != nil {
 		return empty, &responses.NetworkError{
@@ -84,3 +214,76 @@ func DoGetRequest[T any](
 
 	return dataResponse, nil
 }
+
+func doRequest(
+	ctx context.Context,
+	client *http.Client,
+	method string,
+	endpoint string,
+	body io.Reader,
+	options requestOptions,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+
+	if err != nil {
+		return nil, &responses.NetworkError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+
+	for key, values := range options.headers {
+		req.Header[key] = values
+	}
+
+	response, err := client.Do(req)
+
+	if err != nil {
+		var urlError *url.Error
+
+		if errors.As(err, &urlError) {
+			return nil, responses.GetNetworkError(urlError)
+		}
+
+		return nil, &responses.NetworkError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+	}
+
+	return response, nil
+}
+
+func newRequestOptions(options []RequestOption) requestOptions {
+	requestOptions := requestOptions{
+		headers:         http.Header{},
+		maxResponseSize: DefaultMaxResponseSize,
+	}
+
+	for _, option := range options {
+		option(&requestOptions)
+	}
+
+	return requestOptions
+}
+
+// readBody reads one byte past the limit, so a body of exactly the limit is not taken as too large
+func readBody(body io.Reader, maxSize int64) ([]byte, error) {
+	responseBody, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+
+	if err != nil {
+		return nil, &responses.NetworkError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		}
+	}
+
+	if int64(len(responseBody)) > maxSize {
+		return nil, &responses.NetworkError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf("response body larger than %v bytes", maxSize),
+		}
+	}
+
+	return responseBody, nil
+}
@@ -2,13 +2,18 @@ package httpserver_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
 	"github.com/thiagoluis88git/tech1-orders/pkg/httpserver"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
 )
 
 func TestHttpClient(t *testing.T) {
@@ -17,7 +22,7 @@ func TestHttpClient(t *testing.T) {
 	t.Run("got success when creating http client", func(t *testing.T) {
 		t.Parallel()
 
-		client := httpserver.NewHTTPClient()
+		client := httpserver.NewHTTPClient(httpserver.NewHTTPTransport(nil))
 
 		assert.NotEmpty(t, client)
 	})
@@ -60,7 +65,7 @@ func TestHttpClient(t *testing.T) {
 	t.Run("got error when calling DoRequest client", func(t *testing.T) {
 		t.Parallel()
 
-		client := httpserver.NewHTTPClient()
+		client := httpserver.NewHTTPClient(httpserver.NewHTTPTransport(nil))
 
 		assert.NotEmpty(t, client)
 
@@ -69,4 +74,190 @@ func TestHttpClient(t *testing.T) {
 		assert.Error(t, err)
 		assert.Empty(t, response)
 	})
+
+	t.Run("got success when calling DoPostRequest with a json body", func(t *testing.T) {
+		t.Parallel()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var form dto.ProductForm
+			err := json.NewDecoder(r.Body).Decode(&form)
+
+			if err != nil || r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			w.WriteHeader(http.StatusCreated)
+			httpserver.SendResponseSuccess(w, dto.ProductResponse{Id: uint(1), Name: form.Name})
+		}))
+		defer ts.Close()
+
+		response, err := httpserver.DoPostRequest(
+			context.TODO(),
+			ts.Client(),
+			ts.URL,
+			dto.ProductForm{Name: "Name"},
+			dto.ProductResponse{},
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), response.Id)
+		assert.Equal(t, "Name", response.Name)
+	})
+
+	t.Run("got success when calling DoPutRequest with headers and bearer token", func(t *testing.T) {
+		t.Parallel()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPut ||
+				r.Header.Get("Authorization") != "Bearer token" ||
+				r.Header.Get("X-Request-ID") != "request" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			httpserver.SendResponseSuccess(w, dto.ProductResponse{Id: uint(1), Name: "Name"})
+		}))
+		defer ts.Close()
+
+		response, err := httpserver.DoPutRequest(
+			context.TODO(),
+			ts.Client(),
+			ts.URL,
+			dto.ProductForm{Name: "Name"},
+			dto.ProductResponse{},
+			httpserver.WithBearerToken("token"),
+			httpserver.WithHeader("X-Request-ID", "request"),
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Name", response.Name)
+	})
+
+	t.Run("got success when calling DoDeleteRequest with no content response", func(t *testing.T) {
+		t.Parallel()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodDelete {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer ts.Close()
+
+		response, err := httpserver.DoDeleteRequest(context.TODO(), ts.Client(), ts.URL, dto.ProductResponse{})
+
+		assert.NoError(t, err)
+		assert.Empty(t, response)
+	})
+
+	t.Run("got network error with the status code when calling DoPostRequest", func(t *testing.T) {
+		t.Parallel()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte("already exists"))
+		}))
+		defer ts.Close()
+
+		response, err := httpserver.DoPostRequest(context.TODO(), ts.Client(), ts.URL, nil, dto.ProductResponse{})
+
+		var netError *responses.NetworkError
+		assert.True(t, errors.As(err, &netError))
+		assert.Equal(t, http.StatusConflict, netError.Code)
+		assert.Equal(t, "already exists", netError.Message)
+		assert.Empty(t, response)
+	})
+
+	t.Run("got error when the response is larger than the max size", func(t *testing.T) {
+		t.Parallel()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			httpserver.SendResponseSuccess(w, dto.ProductResponse{Id: uint(1), Name: strings.Repeat("a", 100)})
+		}))
+		defer ts.Close()
+
+		_, err := httpserver.DoGetRequest(
+			context.TODO(),
+			ts.Client(),
+			ts.URL,
+			nil,
+			dto.ProductResponse{},
+			httpserver.WithMaxResponseSize(50),
+		)
+
+		var netError *responses.NetworkError
+		assert.True(t, errors.As(err, &netError))
+		assert.Equal(t, http.StatusUnprocessableEntity, netError.Code)
+	})
+
+	t.Run("got success when streaming a large payload", func(t *testing.T) {
+		t.Parallel()
+
+		payload := strings.Repeat("0123456789", 100000)
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received, err := io.ReadAll(r.Body)
+
+			if err != nil || r.Header.Get("Content-Type") != "application/octet-stream" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			w.Write(received)
+		}))
+		defer ts.Close()
+
+		body, err := httpserver.DoStreamRequest(
+			context.TODO(),
+			ts.Client(),
+			http.MethodPost,
+			ts.URL,
+			io.NopCloser(strings.NewReader(payload)),
+			httpserver.WithHeader("Content-Type", "application/octet-stream"),
+			httpserver.WithMaxResponseSize(10),
+		)
+
+		assert.NoError(t, err)
+		defer body.Close()
+
+		// the limit is for error bodies, a streamed body is read by the caller
+		streamed, err := io.ReadAll(body)
+
+		assert.NoError(t, err)
+		assert.Equal(t, payload, string(streamed))
+	})
+
+	t.Run("got network error when streaming from a failing endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("forbidden"))
+		}))
+		defer ts.Close()
+
+		body, err := httpserver.DoStreamRequest(context.TODO(), ts.Client(), http.MethodGet, ts.URL, nil)
+
+		var netError *responses.NetworkError
+		assert.True(t, errors.As(err, &netError))
+		assert.Equal(t, http.StatusForbidden, netError.Code)
+		assert.Equal(t, "forbidden", netError.Message)
+		assert.Nil(t, body)
+	})
+
+	t.Run("got network error when calling DoPostRequest on a closed server", func(t *testing.T) {
+		t.Parallel()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		endpoint := ts.URL
+		ts.Close()
+
+		_, err := httpserver.DoPostRequest(context.TODO(), http.DefaultClient, endpoint, dto.ProductForm{}, dto.ProductResponse{})
+
+		var netError *responses.NetworkError
+		assert.True(t, errors.As(err, &netError))
+	})
 }
@@ -0,0 +1,330 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// ResilienceSettings controls the outbound calls. Each attempt has its own timeout, always
+// within the deadline of the request context. A host with FailureThreshold failures in a row
+// is not called for OpenTimeout, then a single call decides if it is back
+type ResilienceSettings struct {
+	MaxAttempts      int
+	AttemptTimeout   time.Duration
+	MinBackoff       time.Duration
+	MaxBackoff       time.Duration
+	FailureThreshold int
+	OpenTimeout      time.Duration
+}
+
+var DefaultResilienceSettings = ResilienceSettings{
+	MaxAttempts:      3,
+	AttemptTimeout:   2 * time.Second,
+	MinBackoff:       100 * time.Millisecond,
+	MaxBackoff:       time.Second,
+	FailureThreshold: 5,
+	OpenTimeout:      30 * time.Second,
+}
+
+// CircuitOpenError is returned without calling the host while its circuit is open.
+// It is temporary, so it is answered as 503 Service Unavailable
+type CircuitOpenError struct {
+	Host string
+}
+
+func (err *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open for %v", err.Host)
+}
+
+func (err *CircuitOpenError) Temporary() bool {
+	return true
+}
+
+func (err *CircuitOpenError) Timeout() bool {
+	return false
+}
+
+// ResilientTransport retries the idempotent requests and keeps a circuit breaker per host
+type ResilientTransport struct {
+	next     http.RoundTripper
+	settings ResilienceSettings
+
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+func NewResilientTransport(next http.RoundTripper, settings ResilienceSettings) *ResilientTransport {
+	return &ResilientTransport{
+		next:     next,
+		settings: settings,
+		breakers: map[string]*circuitBreaker{},
+	}
+}
+
+func (transport *ResilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	breaker := transport.breaker(req.URL.Host)
+
+	attempts := 1
+
+	if isRetryable(req) {
+		attempts = max(transport.settings.MaxAttempts, 1)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if !breaker.allow() {
+			return nil, &CircuitOpenError{Host: req.URL.Host}
+		}
+
+		response, err := transport.roundTripAttempt(req, attempt)
+
+		// the caller giving up says nothing about the host
+		if ctx.Err() != nil {
+			breaker.release()
+			return response, err
+		}
+
+		failed := err != nil || response.StatusCode >= http.StatusInternalServerError
+		breaker.record(!failed)
+
+		if attempt+1 >= attempts || !shouldRetry(response, err) {
+			return response, err
+		}
+
+		if response != nil {
+			io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+		}
+
+		select {
+		case <-time.After(transport.backoff(attempt)):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// CircuitBreakers returns the circuit state of every host called so far
+func (transport *ResilientTransport) CircuitBreakers() map[string]string {
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	states := map[string]string{}
+
+	for host, breaker := range transport.breakers {
+		states[host] = breaker.currentState()
+	}
+
+	return states
+}
+
+func (transport *ResilientTransport) roundTripAttempt(req *http.Request, attempt int) (*http.Response, error) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	// a stream can take longer than any attempt, so only the request context bounds it
+	if hasNoAttemptTimeout(req.Context()) {
+		ctx, cancel = context.WithCancel(req.Context())
+	} else {
+		ctx, cancel = context.WithTimeout(req.Context(), transport.settings.AttemptTimeout)
+	}
+
+	attemptReq := req.Clone(ctx)
+
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		attemptReq.Body = body
+	}
+
+	response, err := transport.next.RoundTrip(attemptReq)
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// the attempt deadline also covers reading the body, so it is released when the body is closed
+	response.Body = &cancelOnCloseBody{ReadCloser: response.Body, cancel: cancel}
+
+	return response, nil
+}
+
+// backoff is a random wait up to MinBackoff * 2^attempt, capped at MaxBackoff (full jitter),
+// so the retries of many requests do not hit the host at the same time
+func (transport *ResilientTransport) backoff(attempt int) time.Duration {
+	limit := transport.settings.MinBackoff
+
+	for i := 0; i < attempt && limit < transport.settings.MaxBackoff; i++ {
+		limit *= 2
+	}
+
+	limit = min(limit, transport.settings.MaxBackoff)
+
+	if limit <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(limit) + 1))
+}
+
+func (transport *ResilientTransport) breaker(host string) *circuitBreaker {
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	breaker, ok := transport.breakers[host]
+
+	if !ok {
+		breaker = &circuitBreaker{
+			state:            CircuitClosed,
+			failureThreshold: transport.settings.FailureThreshold,
+			openTimeout:      transport.settings.OpenTimeout,
+		}
+		transport.breakers[host] = breaker
+	}
+
+	return breaker
+}
+
+// isRetryable is true for the idempotent methods whose body can be sent again
+func isRetryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func shouldRetry(response *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+type noAttemptTimeoutKey struct{}
+
+func withoutAttemptTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noAttemptTimeoutKey{}, true)
+}
+
+func hasNoAttemptTimeout(ctx context.Context) bool {
+	noTimeout, _ := ctx.Value(noAttemptTimeoutKey{}).(bool)
+
+	return noTimeout
+}
+
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body *cancelOnCloseBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+
+	return err
+}
+
+type circuitBreaker struct {
+	mu               sync.Mutex
+	state            string
+	failures         int
+	openedAt         time.Time
+	probing          bool
+	failureThreshold int
+	openTimeout      time.Duration
+}
+
+// allow lets the call go while closed. After the open timeout, only one call
+// at a time goes through (half-open) until one of them succeeds or fails
+func (breaker *circuitBreaker) allow() bool {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	switch breaker.state {
+	case CircuitOpen:
+		if time.Since(breaker.openedAt) < breaker.openTimeout {
+			return false
+		}
+
+		breaker.state = CircuitHalfOpen
+		breaker.probing = true
+
+		return true
+	case CircuitHalfOpen:
+		if breaker.probing {
+			return false
+		}
+
+		breaker.probing = true
+
+		return true
+	}
+
+	return true
+}
+
+func (breaker *circuitBreaker) record(success bool) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	breaker.probing = false
+
+	if success {
+		breaker.state = CircuitClosed
+		breaker.failures = 0
+
+		return
+	}
+
+	breaker.failures++
+
+	if breaker.state == CircuitHalfOpen || breaker.failures >= breaker.failureThreshold {
+		breaker.state = CircuitOpen
+		breaker.openedAt = time.Now()
+	}
+}
+
+// release lets another call probe the host when the probe was cancelled by its caller
+func (breaker *circuitBreaker) release() {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	breaker.probing = false
+}
+
+func (breaker *circuitBreaker) currentState() string {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	if breaker.state == CircuitOpen && time.Since(breaker.openedAt) >= breaker.openTimeout {
+		return CircuitHalfOpen
+	}
+
+	return breaker.state
+}
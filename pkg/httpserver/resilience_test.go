@@ -0,0 +1,362 @@
+package httpserver_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/pkg/httpserver"
+	"github.com/thiagoluis88git/tech1-orders/pkg/responses"
+)
+
+var resilienceSettings = httpserver.ResilienceSettings{
+	MaxAttempts:      3,
+	AttemptTimeout:   time.Second,
+	MinBackoff:       time.Millisecond,
+	MaxBackoff:       5 * time.Millisecond,
+	FailureThreshold: 10,
+	OpenTimeout:      time.Minute,
+}
+
+// failingServer answers with the status for the first failures calls and with a product after them
+func failingServer(failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	calls := &atomic.Int32{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, dto.ProductResponse{Id: uint(1), Name: "Name"})
+	}))
+
+	return ts, calls
+}
+
+func hostOf(t *testing.T, endpoint string) string {
+	parsed, err := url.Parse(endpoint)
+	assert.NoError(t, err)
+
+	return parsed.Host
+}
+
+func TestResilientTransport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got success when retrying a get after temporary failures", func(t *testing.T) {
+		t.Parallel()
+
+		ts, calls := failingServer(2, http.StatusServiceUnavailable)
+		defer ts.Close()
+
+		client := httpserver.NewHTTPClient(httpserver.NewResilientTransport(http.DefaultTransport, resilienceSettings))
+
+		response, err := httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Name", response.Name)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("got error when every attempt fails", func(t *testing.T) {
+		t.Parallel()
+
+		ts, calls := failingServer(10, http.StatusBadGateway)
+		defer ts.Close()
+
+		client := httpserver.NewHTTPClient(httpserver.NewResilientTransport(http.DefaultTransport, resilienceSettings))
+
+		_, err := httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+
+		var netError *responses.NetworkError
+		assert.True(t, errors.As(err, &netError))
+		assert.Equal(t, http.StatusBadGateway, netError.Code)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("got no retry when the request is not idempotent", func(t *testing.T) {
+		t.Parallel()
+
+		ts, calls := failingServer(1, http.StatusServiceUnavailable)
+		defer ts.Close()
+
+		client := httpserver.NewHTTPClient(httpserver.NewResilientTransport(http.DefaultTransport, resilienceSettings))
+
+		response, err := client.Post(ts.URL, "application/json", strings.NewReader("{}"))
+
+		assert.NoError(t, err)
+		defer response.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("got body sent again when retrying a put", func(t *testing.T) {
+		t.Parallel()
+
+		bodies := make(chan string, 3)
+		calls := &atomic.Int32{}
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies <- string(body)
+
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer ts.Close()
+
+		client := httpserver.NewHTTPClient(httpserver.NewResilientTransport(http.DefaultTransport, resilienceSettings))
+
+		req, err := http.NewRequest(http.MethodPut, ts.URL, strings.NewReader(`{"name":"Name"}`))
+		assert.NoError(t, err)
+
+		response, err := client.Do(req)
+
+		assert.NoError(t, err)
+		defer response.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, response.StatusCode)
+		assert.Equal(t, `{"name":"Name"}`, <-bodies)
+		assert.Equal(t, `{"name":"Name"}`, <-bodies)
+	})
+
+	t.Run("got no retry when the response is a client error", func(t *testing.T) {
+		t.Parallel()
+
+		ts, calls := failingServer(1, http.StatusNotFound)
+		defer ts.Close()
+
+		client := httpserver.NewHTTPClient(httpserver.NewResilientTransport(http.DefaultTransport, resilienceSettings))
+
+		_, err := httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+
+		var netError *responses.NetworkError
+		assert.True(t, errors.As(err, &netError))
+		assert.Equal(t, http.StatusNotFound, netError.Code)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("got success when a slow attempt times out and the next one answers", func(t *testing.T) {
+		t.Parallel()
+
+		calls := &atomic.Int32{}
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+
+				return
+			}
+
+			httpserver.SendResponseSuccess(w, dto.ProductResponse{Id: uint(1), Name: "Name"})
+		}))
+		defer ts.Close()
+
+		settings := resilienceSettings
+		settings.AttemptTimeout = 50 * time.Millisecond
+
+		client := httpserver.NewHTTPClient(httpserver.NewResilientTransport(http.DefaultTransport, settings))
+
+		start := time.Now()
+		response, err := httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Name", response.Name)
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("got success when a stream takes longer than the attempt timeout", func(t *testing.T) {
+		t.Parallel()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Content-Type") != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			w.Write([]byte("first"))
+			w.(http.Flusher).Flush()
+
+			time.Sleep(150 * time.Millisecond)
+
+			w.Write([]byte("second"))
+		}))
+		defer ts.Close()
+
+		settings := resilienceSettings
+		settings.AttemptTimeout = 50 * time.Millisecond
+
+		client := httpserver.NewHTTPClient(httpserver.NewResilientTransport(http.DefaultTransport, settings))
+
+		body, err := httpserver.DoStreamRequest(context.TODO(), client, http.MethodPost, ts.URL, strings.NewReader("payload"))
+
+		assert.NoError(t, err)
+		defer body.Close()
+
+		streamed, err := io.ReadAll(body)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "firstsecond", string(streamed))
+	})
+
+	t.Run("got error when a stream takes longer than the caller deadline", func(t *testing.T) {
+		t.Parallel()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("first"))
+			w.(http.Flusher).Flush()
+
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer ts.Close()
+
+		client := httpserver.NewHTTPClient(httpserver.NewResilientTransport(http.DefaultTransport, resilienceSettings))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		body, err := httpserver.DoStreamRequest(ctx, client, http.MethodGet, ts.URL, nil)
+
+		assert.NoError(t, err)
+		defer body.Close()
+
+		_, err = io.ReadAll(body)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("got timeout error when the caller deadline ends before the retries", func(t *testing.T) {
+		t.Parallel()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer ts.Close()
+
+		transport := httpserver.NewResilientTransport(http.DefaultTransport, resilienceSettings)
+		client := httpserver.NewHTTPClient(transport)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := httpserver.DoGetRequest(ctx, client, ts.URL, nil, dto.ProductResponse{})
+
+		var netError *responses.NetworkError
+		assert.True(t, errors.As(err, &netError))
+		assert.Equal(t, http.StatusGatewayTimeout, netError.Code)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+		// the caller giving up is not a failure of the host
+		assert.Equal(t, httpserver.CircuitClosed, transport.CircuitBreakers()[hostOf(t, ts.URL)])
+	})
+
+	t.Run("got circuit open error without calling the host after consecutive failures", func(t *testing.T) {
+		t.Parallel()
+
+		ts, calls := failingServer(100, http.StatusInternalServerError)
+		defer ts.Close()
+
+		settings := resilienceSettings
+		settings.FailureThreshold = 2
+
+		transport := httpserver.NewResilientTransport(http.DefaultTransport, settings)
+		client := httpserver.NewHTTPClient(transport)
+
+		for i := 0; i < 2; i++ {
+			_, err := httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+			assert.Error(t, err)
+		}
+
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Equal(t, httpserver.CircuitOpen, transport.CircuitBreakers()[hostOf(t, ts.URL)])
+
+		_, err := httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+
+		var netError *responses.NetworkError
+		assert.True(t, errors.As(err, &netError))
+		assert.Equal(t, http.StatusServiceUnavailable, netError.Code)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("got circuit closed again when the host recovers after the open timeout", func(t *testing.T) {
+		t.Parallel()
+
+		ts, calls := failingServer(2, http.StatusInternalServerError)
+		defer ts.Close()
+
+		settings := resilienceSettings
+		settings.MaxAttempts = 1
+		settings.FailureThreshold = 2
+		settings.OpenTimeout = 50 * time.Millisecond
+
+		transport := httpserver.NewResilientTransport(http.DefaultTransport, settings)
+		client := httpserver.NewHTTPClient(transport)
+
+		for i := 0; i < 2; i++ {
+			_, err := httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+			assert.Error(t, err)
+		}
+
+		assert.Equal(t, httpserver.CircuitOpen, transport.CircuitBreakers()[hostOf(t, ts.URL)])
+
+		time.Sleep(100 * time.Millisecond)
+
+		assert.Equal(t, httpserver.CircuitHalfOpen, transport.CircuitBreakers()[hostOf(t, ts.URL)])
+
+		response, err := httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Name", response.Name)
+		assert.Equal(t, int32(3), calls.Load())
+		assert.Equal(t, httpserver.CircuitClosed, transport.CircuitBreakers()[hostOf(t, ts.URL)])
+	})
+
+	t.Run("got circuit open again when the probe fails", func(t *testing.T) {
+		t.Parallel()
+
+		ts, calls := failingServer(100, http.StatusInternalServerError)
+		defer ts.Close()
+
+		settings := resilienceSettings
+		settings.MaxAttempts = 1
+		settings.FailureThreshold = 1
+		settings.OpenTimeout = 50 * time.Millisecond
+
+		transport := httpserver.NewResilientTransport(http.DefaultTransport, settings)
+		client := httpserver.NewHTTPClient(transport)
+
+		_, err := httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+		assert.Error(t, err)
+
+		time.Sleep(100 * time.Millisecond)
+
+		_, err = httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+		assert.Error(t, err)
+
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Equal(t, httpserver.CircuitOpen, transport.CircuitBreakers()[hostOf(t, ts.URL)])
+	})
+}
@@ -0,0 +1,186 @@
+package httpserver
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+// TLSSettings configures the outbound TLS. The system roots are trusted by default,
+// plus the certificates in CAFile when it is set. CertFile and KeyFile are the client
+// certificate for mutual TLS. Insecure skips the server verification and is meant
+// for local development only
+type TLSSettings struct {
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	Insecure bool
+}
+
+// NewTLSConfig reads the files once to fail fast on a bad setup. After that they are read
+// again on each new connection, so a renewed certificate is used without a restart
+func NewTLSConfig(settings TLSSettings) (*tls.Config, error) {
+	if (settings.CertFile == "") != (settings.KeyFile == "") {
+		return nil, errors.New("client certificate and key files must be set together")
+	}
+
+	files := &tlsFiles{settings: settings}
+
+	err := files.reload()
+
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if settings.CertFile != "" {
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return files.currentCertificate(), nil
+		}
+	}
+
+	switch {
+	case settings.Insecure:
+		config.InsecureSkipVerify = true
+	case settings.CAFile != "":
+		// the roots can change, so the chain is verified by hand against the current ones
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyPeer(state, files.currentRoots())
+		}
+	}
+
+	return config, nil
+}
+
+func verifyPeer(state tls.ConnectionState, roots *x509.CertPool) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("server sent no certificate")
+	}
+
+	options := x509.VerifyOptions{
+		DNSName:       state.ServerName,
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+	}
+
+	for _, cert := range state.PeerCertificates[1:] {
+		options.Intermediates.AddCert(cert)
+	}
+
+	_, err := state.PeerCertificates[0].Verify(options)
+
+	return err
+}
+
+type tlsFiles struct {
+	settings TLSSettings
+
+	mu          sync.Mutex
+	contents    []byte
+	roots       *x509.CertPool
+	certificate *tls.Certificate
+}
+
+func (files *tlsFiles) currentRoots() *x509.CertPool {
+	files.refresh()
+
+	files.mu.Lock()
+	defer files.mu.Unlock()
+
+	return files.roots
+}
+
+func (files *tlsFiles) currentCertificate() *tls.Certificate {
+	files.refresh()
+
+	files.mu.Lock()
+	defer files.mu.Unlock()
+
+	return files.certificate
+}
+
+// refresh keeps the last good files when the new ones can not be used,
+// like a certificate already renewed whose key is not written yet
+func (files *tlsFiles) refresh() {
+	err := files.reload()
+
+	if err != nil {
+		log.Print("reloading tls files", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+func (files *tlsFiles) reload() error {
+	caPEM, err := readOptionalFile(files.settings.CAFile)
+
+	if err != nil {
+		return err
+	}
+
+	certPEM, err := readOptionalFile(files.settings.CertFile)
+
+	if err != nil {
+		return err
+	}
+
+	keyPEM, err := readOptionalFile(files.settings.KeyFile)
+
+	if err != nil {
+		return err
+	}
+
+	contents := bytes.Join([][]byte{caPEM, certPEM, keyPEM}, []byte{0})
+
+	files.mu.Lock()
+	defer files.mu.Unlock()
+
+	if files.contents != nil && bytes.Equal(contents, files.contents) {
+		return nil
+	}
+
+	roots, err := x509.SystemCertPool()
+
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+
+	if caPEM != nil && !roots.AppendCertsFromPEM(caPEM) {
+		return fmt.Errorf("no certificate found in %v", files.settings.CAFile)
+	}
+
+	var certificate *tls.Certificate
+
+	if certPEM != nil {
+		keyPair, err := tls.X509KeyPair(certPEM, keyPEM)
+
+		if err != nil {
+			return fmt.Errorf("loading client certificate %v: %w", files.settings.CertFile, err)
+		}
+
+		certificate = &keyPair
+	}
+
+	files.contents = contents
+	files.roots = roots
+	files.certificate = certificate
+
+	return nil
+}
+
+func readOptionalFile(name string) ([]byte, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	return os.ReadFile(name)
+}
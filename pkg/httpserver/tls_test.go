@@ -0,0 +1,282 @@
+package httpserver_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1-orders/pkg/httpserver"
+)
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func (c testCertificate) tlsCertificate(t *testing.T) tls.Certificate {
+	keyPair, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	assert.NoError(t, err)
+
+	return keyPair
+}
+
+// newTestCertificate creates a self-signed CA when the parent is nil,
+// otherwise a certificate for 127.0.0.1 signed by the parent
+func newTestCertificate(t *testing.T, name string, parent *testCertificate) testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	signer := template
+	signerKey := key
+
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+		signer = parent.cert
+		signerKey = parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	assert.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	return testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func writeFile(t *testing.T, name string, content []byte) {
+	assert.NoError(t, os.WriteFile(name, content, 0600))
+}
+
+// newTLSServer answers with a product over TLS. When clientCA is set,
+// only clients with a certificate signed by it are accepted
+func newTLSServer(t *testing.T, serverCert testCertificate, clientCA *testCertificate) *httptest.Server {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpserver.SendResponseSuccess(w, dto.ProductResponse{Id: uint(1), Name: "Name"})
+	}))
+
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert.tlsCertificate(t)},
+	}
+
+	if clientCA != nil {
+		pool := x509.NewCertPool()
+		pool.AddCert(clientCA.cert)
+
+		ts.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+		ts.TLS.ClientCAs = pool
+	}
+
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func getProduct(t *testing.T, settings httpserver.TLSSettings, endpoint string) error {
+	tlsConfig, err := httpserver.NewTLSConfig(settings)
+	assert.NoError(t, err)
+
+	transport := httpserver.NewHTTPTransport(tlsConfig)
+	defer transport.CloseIdleConnections()
+
+	_, err = httpserver.DoGetRequest(context.TODO(), httpserver.NewHTTPClient(transport), endpoint, nil, dto.ProductResponse{})
+
+	return err
+}
+
+func TestTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	ca := newTestCertificate(t, "ca", nil)
+	serverCert := newTestCertificate(t, "server", &ca)
+
+	t.Run("got error when the server certificate is not trusted by the system roots", func(t *testing.T) {
+		t.Parallel()
+
+		ts := newTLSServer(t, serverCert, nil)
+
+		err := getProduct(t, httpserver.TLSSettings{}, ts.URL)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("got success when the server certificate is signed by the ca file", func(t *testing.T) {
+		t.Parallel()
+
+		ts := newTLSServer(t, serverCert, nil)
+
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		writeFile(t, caFile, ca.certPEM)
+
+		err := getProduct(t, httpserver.TLSSettings{CAFile: caFile}, ts.URL)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got success when skipping the verification in insecure mode", func(t *testing.T) {
+		t.Parallel()
+
+		ts := newTLSServer(t, serverCert, nil)
+
+		err := getProduct(t, httpserver.TLSSettings{Insecure: true}, ts.URL)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got success when sending the client certificate to a mutual tls server", func(t *testing.T) {
+		t.Parallel()
+
+		ts := newTLSServer(t, serverCert, &ca)
+		clientCert := newTestCertificate(t, "client", &ca)
+
+		dir := t.TempDir()
+		settings := httpserver.TLSSettings{
+			CAFile:   filepath.Join(dir, "ca.pem"),
+			CertFile: filepath.Join(dir, "client.pem"),
+			KeyFile:  filepath.Join(dir, "client-key.pem"),
+		}
+		writeFile(t, settings.CAFile, ca.certPEM)
+		writeFile(t, settings.CertFile, clientCert.certPEM)
+		writeFile(t, settings.KeyFile, clientCert.keyPEM)
+
+		err := getProduct(t, settings, ts.URL)
+		assert.NoError(t, err)
+
+		err = getProduct(t, httpserver.TLSSettings{CAFile: settings.CAFile}, ts.URL)
+		assert.Error(t, err)
+	})
+
+	t.Run("got new ca used when the ca file changes", func(t *testing.T) {
+		t.Parallel()
+
+		newCA := newTestCertificate(t, "new ca", nil)
+		ts := newTLSServer(t, newTestCertificate(t, "new server", &newCA), nil)
+
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		writeFile(t, caFile, ca.certPEM)
+
+		tlsConfig, err := httpserver.NewTLSConfig(httpserver.TLSSettings{CAFile: caFile})
+		assert.NoError(t, err)
+
+		transport := httpserver.NewHTTPTransport(tlsConfig)
+		defer transport.CloseIdleConnections()
+
+		client := httpserver.NewHTTPClient(transport)
+
+		_, err = httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+		assert.Error(t, err)
+
+		writeFile(t, caFile, newCA.certPEM)
+
+		_, err = httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("got new client certificate used when the files change", func(t *testing.T) {
+		t.Parallel()
+
+		newCA := newTestCertificate(t, "new ca", nil)
+		ts := newTLSServer(t, serverCert, &newCA)
+
+		oldClientCert := newTestCertificate(t, "old client", &ca)
+		newClientCert := newTestCertificate(t, "new client", &newCA)
+
+		dir := t.TempDir()
+		settings := httpserver.TLSSettings{
+			CAFile:   filepath.Join(dir, "ca.pem"),
+			CertFile: filepath.Join(dir, "client.pem"),
+			KeyFile:  filepath.Join(dir, "client-key.pem"),
+		}
+		writeFile(t, settings.CAFile, ca.certPEM)
+		writeFile(t, settings.CertFile, oldClientCert.certPEM)
+		writeFile(t, settings.KeyFile, oldClientCert.keyPEM)
+
+		tlsConfig, err := httpserver.NewTLSConfig(settings)
+		assert.NoError(t, err)
+
+		transport := httpserver.NewHTTPTransport(tlsConfig)
+		defer transport.CloseIdleConnections()
+
+		client := httpserver.NewHTTPClient(transport)
+
+		_, err = httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+		assert.Error(t, err)
+
+		// a certificate without its new key is not used, the old pair is kept
+		writeFile(t, settings.CertFile, newClientCert.certPEM)
+
+		_, err = httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+		assert.Error(t, err)
+
+		writeFile(t, settings.KeyFile, newClientCert.keyPEM)
+
+		_, err = httpserver.DoGetRequest(context.TODO(), client, ts.URL, nil, dto.ProductResponse{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("got error when the ca file has no certificate", func(t *testing.T) {
+		t.Parallel()
+
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		writeFile(t, caFile, []byte("not a certificate"))
+
+		_, err := httpserver.NewTLSConfig(httpserver.TLSSettings{CAFile: caFile})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("got error when the client certificate has no key", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := httpserver.NewTLSConfig(httpserver.TLSSettings{CertFile: "client.pem"})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("got error when the ca file does not exist", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := httpserver.NewTLSConfig(httpserver.TLSSettings{CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,101 @@
+package pix
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	pixGUI         = "br.gov.bcb.pix"
+	currencyBRL    = "986"
+	countryBR      = "BR"
+	noTxID         = "***"
+	maxNameLength  = 25
+	maxCityLength  = 15
+	maxTxIDLength  = 25
+	crcFieldHeader = "6304"
+)
+
+// Payload is a PIX "copia e cola" charge. Its String is the EMV string the
+// customer app reads from the QR Code
+type Payload struct {
+	Key          string
+	MerchantName string
+	MerchantCity string
+	Amount       float64
+	TxID         string
+}
+
+func (payload Payload) String() string {
+	txID := payload.TxID
+
+	if txID == "" {
+		txID = noTxID
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString(field("00", "01"))
+	builder.WriteString(field("01", "12"))
+	builder.WriteString(field("26", field("00", pixGUI)+field("01", payload.Key)))
+	builder.WriteString(field("52", "0000"))
+	builder.WriteString(field("53", currencyBRL))
+
+	if payload.Amount > 0 {
+		builder.WriteString(field("54", fmt.Sprintf("%.2f", payload.Amount)))
+	}
+
+	builder.WriteString(field("58", countryBR))
+	builder.WriteString(field("59", truncate(payload.MerchantName, maxNameLength)))
+	builder.WriteString(field("60", truncate(payload.MerchantCity, maxCityLength)))
+	builder.WriteString(field("62", field("05", truncate(txID, maxTxIDLength))))
+	builder.WriteString(crcFieldHeader)
+
+	return builder.String() + Checksum(builder.String())
+}
+
+// Checksum returns the CRC16 field value of a payload that already ends with the CRC field header (6304)
+func Checksum(payload string) string {
+	return fmt.Sprintf("%04X", crc16(payload))
+}
+
+// QRCodePNG encodes the payload in a PNG image with size x size pixels
+func QRCodePNG(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
+
+func field(id, value string) string {
+	return fmt.Sprintf("%v%02d%v", id, utf8.RuneCountInString(value), value)
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(value)
+
+	if len(runes) <= length {
+		return value
+	}
+
+	return string(runes[:length])
+}
+
+// crc16 is the CRC16-CCITT (polynomial 0x1021, initial value 0xFFFF) required by the EMV spec
+func crc16(value string) uint16 {
+	crc := uint16(0xFFFF)
+
+	for _, b := range []byte(value) {
+		crc ^= uint16(b) << 8
+
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
@@ -0,0 +1,83 @@
+package pix_test
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1-orders/pkg/pix"
+)
+
+func TestPix(t *testing.T) {
+	t.Parallel()
+
+	t.Run("got payload with amount and txid when building pix payload", func(t *testing.T) {
+		t.Parallel()
+
+		payload := pix.Payload{
+			Key:          "fastfood@pix.com",
+			MerchantName: "Fast Food",
+			MerchantCity: "Sao Paulo",
+			Amount:       32.5,
+			TxID:         "ORDER12",
+		}.String()
+
+		assert.True(t, strings.HasPrefix(payload, "000201010212"))
+		assert.Contains(t, payload, "26380014br.gov.bcb.pix0116fastfood@pix.com")
+		assert.Contains(t, payload, "540532.50")
+		assert.Contains(t, payload, "5909Fast Food6009Sao Paulo")
+		assert.Contains(t, payload, "62110507ORDER12")
+		assert.Equal(t, "6304", payload[len(payload)-8:len(payload)-4])
+	})
+
+	t.Run("got same crc as the central bank example when calculating checksum", func(t *testing.T) {
+		t.Parallel()
+
+		checksum := pix.Checksum("00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***6304")
+
+		assert.Equal(t, "1D3D", checksum)
+	})
+
+	t.Run("got payload ending with its own checksum when building pix payload", func(t *testing.T) {
+		t.Parallel()
+
+		payload := pix.Payload{
+			Key:          "123e4567-e12b-12d1-a456-426655440000",
+			MerchantName: "Fulano de Tal",
+			MerchantCity: "BRASILIA",
+		}.String()
+
+		assert.Equal(t, pix.Checksum(payload[:len(payload)-4]), payload[len(payload)-4:])
+		assert.Contains(t, payload, "62070503***6304")
+	})
+
+	t.Run("got truncated fields when building pix payload", func(t *testing.T) {
+		t.Parallel()
+
+		payload := pix.Payload{
+			Key:          "key",
+			MerchantName: "A very long merchant name that does not fit",
+			MerchantCity: "A very long city name",
+			TxID:         "abcdefghijklmnopqrstuvwxyz0123",
+		}.String()
+
+		assert.Contains(t, payload, "5925A very long merchant name6015")
+		assert.Contains(t, payload, "6015A very long cit62")
+		assert.Contains(t, payload, "0525abcdefghijklmnopqrstuvwxy")
+	})
+
+	t.Run("got png image when generating qr code", func(t *testing.T) {
+		t.Parallel()
+
+		image, err := pix.QRCodePNG(pix.Payload{Key: "key", MerchantName: "Name", MerchantCity: "City"}.String(), 256)
+
+		assert.NoError(t, err)
+
+		decoded, err := png.Decode(bytes.NewReader(image))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 256, decoded.Bounds().Dx())
+	})
+}
@@ -98,7 +98,7 @@ func getBusinessStatusCode(localError LocalError) int {
 		return http.StatusNotFound
 	}
 
-	if localError.Code == DATABASE_ERROR {
+	if localError.Code == DATABASE_ERROR || localError.Code == TICKET_ALLOCATION_ERROR {
 		return http.StatusServiceUnavailable
 	}
 
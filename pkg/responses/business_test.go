@@ -154,6 +154,19 @@ func TestBusinessResponse(t *testing.T) {
 		assert.Equal(t, http.StatusServiceUnavailable, businessError.(*responses.BusinessResponse).StatusCode)
 	})
 
+	t.Run("got StatusServiceUnavailable error with Ticket Local Error when calling GetResponseError", func(t *testing.T) {
+		t.Parallel()
+
+		err := &responses.LocalError{
+			Code:    responses.TICKET_ALLOCATION_ERROR,
+			Message: "could not allocate a ticket number",
+		}
+
+		businessError := responses.GetResponseError(err, "MOCK")
+
+		assert.Equal(t, http.StatusServiceUnavailable, businessError.(*responses.BusinessResponse).StatusCode)
+	})
+
 	t.Run("got StatusUnprocessableEntity error with Local Error when calling GetResponseError", func(t *testing.T) {
 		t.Parallel()
 
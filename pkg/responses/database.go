@@ -13,6 +13,7 @@ const (
 	DATABASE_CONFLICT_ERROR   = 3
 	NOT_FOUND_ERROR           = 4
 	LOGIC_ERROR               = 5
+	TICKET_ALLOCATION_ERROR   = 6
 )
 
 type LocalError struct {
@@ -33,11 +33,10 @@ func GetNetworkError(urlError *url.Error) *NetworkError {
 	code := http.StatusInternalServerError
 	message := urlError.Unwrap().Error()
 
+	// a deadline is also temporary, so the timeout is checked first
 	if urlError.Timeout() {
 		code = http.StatusGatewayTimeout
-	}
-
-	if urlError.Temporary() {
+	} else if urlError.Temporary() {
 		code = http.StatusServiceUnavailable
 	}
 
@@ -1,6 +1,7 @@
 package responses_test
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/url"
@@ -25,6 +26,18 @@ func TestNetworkResponse(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, localError.Code)
 	})
 
+	t.Run("got GatewayTimeout error with deadline urlError when calling GetNetworkError", func(t *testing.T) {
+		t.Parallel()
+
+		err := &url.Error{
+			Err: context.DeadlineExceeded,
+		}
+
+		localError := responses.GetNetworkError(err)
+
+		assert.Equal(t, http.StatusGatewayTimeout, localError.Code)
+	})
+
 	t.Run("got StatusConflict error with Cognito Error when calling GetCognitoError", func(t *testing.T) {
 		t.Parallel()
 